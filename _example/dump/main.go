@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser/astjson"
 )
 
 var (
@@ -16,6 +17,7 @@ var (
 	debug      = flag.Bool("debug", false, "debug flag to output more parsing process detail")
 	permissive = flag.Bool("permissive", true, "permissive flag to allow the permissive parsing rather than the just documented spec")
 	unordered  = flag.Bool("unordered", false, "unordered flag to output another one without interface{}")
+	kind       = flag.Bool("kind", false, "kind flag to output the lossless one with the kind of each node, which astjson can read back")
 )
 
 func run() int {
@@ -43,6 +45,16 @@ func run() int {
 		return 1
 	}
 
+	if *kind {
+		gotJSON, err := astjson.MarshalIndent(got, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal, err %v\n", err)
+			return 1
+		}
+		fmt.Print(string(gotJSON))
+		return 0
+	}
+
 	var v interface{}
 	v = got
 	if *unordered {
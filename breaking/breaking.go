@@ -0,0 +1,170 @@
+// Package breaking detects the changes between two versions of Protocol Buffer files
+// which break the compatibility of the wire format or of the generated source code.
+package breaking
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// Severity is the kind of the compatibility broken by a change.
+type Severity int
+
+// Severity values, from the most severe.
+const (
+	// SeverityWire is the severity of a change which breaks the wire compatibility, that is,
+	// the messages or the RPCs serialized by one version cannot be read correctly by the other.
+	SeverityWire Severity = iota + 1
+	// SeveritySource is the severity of a change which breaks the code using the generated source code,
+	// or the JSON format.
+	SeveritySource
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWire:
+		return "wire"
+	case SeveritySource:
+		return "source"
+	}
+	return "unknown"
+}
+
+// Rule is the stable identifier of the kind of a change.
+type Rule string
+
+// Rule values.
+const (
+	// RuleFileRemoved is the rule of a removed file.
+	RuleFileRemoved Rule = "file-removed"
+	// RulePackageChanged is the rule of a file whose package is changed.
+	RulePackageChanged Rule = "package-changed"
+	// RuleMessageRemoved is the rule of a removed message.
+	RuleMessageRemoved Rule = "message-removed"
+	// RuleEnumRemoved is the rule of a removed enum.
+	RuleEnumRemoved Rule = "enum-removed"
+	// RuleTypeMoved is the rule of a message or an enum moved to another file or another scope.
+	RuleTypeMoved Rule = "type-moved"
+	// RuleFieldRemoved is the rule of a removed field whose number is not reserved.
+	RuleFieldRemoved Rule = "field-removed"
+	// RuleFieldNumberChanged is the rule of a field whose number is changed.
+	RuleFieldNumberChanged Rule = "field-number-changed"
+	// RuleFieldRenamed is the rule of a field whose name is changed.
+	RuleFieldRenamed Rule = "field-renamed"
+	// RuleFieldTypeChanged is the rule of a field whose type is changed.
+	RuleFieldTypeChanged Rule = "field-type-changed"
+	// RuleFieldCardinalityChanged is the rule of a field changed between repeated and singular.
+	RuleFieldCardinalityChanged Rule = "field-cardinality-changed"
+	// RuleEnumValueRemoved is the rule of a removed enum value whose number is not reserved.
+	RuleEnumValueRemoved Rule = "enum-value-removed"
+	// RuleEnumValueNumberChanged is the rule of an enum value whose number is changed.
+	RuleEnumValueNumberChanged Rule = "enum-value-number-changed"
+	// RuleEnumValueRenamed is the rule of an enum value whose name is changed.
+	RuleEnumValueRenamed Rule = "enum-value-renamed"
+	// RuleServiceRemoved is the rule of a removed service.
+	RuleServiceRemoved Rule = "service-removed"
+	// RuleRPCRemoved is the rule of a removed RPC.
+	RuleRPCRemoved Rule = "rpc-removed"
+	// RuleRPCTypeChanged is the rule of an RPC whose request or response type is changed.
+	RuleRPCTypeChanged Rule = "rpc-type-changed"
+	// RuleRPCStreamingChanged is the rule of an RPC whose request or response streaming mode is changed.
+	RuleRPCStreamingChanged Rule = "rpc-streaming-changed"
+)
+
+// Change is a change which breaks the compatibility.
+type Change struct {
+	Rule     Rule
+	Severity Severity
+	Message  string
+	// Old is the position of the element in the old version, or the zero value if it is added.
+	Old meta.Position
+	// New is the position of the element in the new version, or the zero value if it is removed.
+	New meta.Position
+}
+
+// String returns the change along with the position in the new version, or in the old one if it is removed.
+func (c *Change) String() string {
+	pos := c.New
+	if pos.Line == 0 {
+		pos = c.Old
+	}
+	return fmt.Sprintf("%s: %s[%s]: %s", pos, c.Severity, c.Rule, c.Message)
+}
+
+// Changes is a list of changes.
+type Changes []*Change
+
+// BySeverity returns the changes of the severity.
+func (cs Changes) BySeverity(severity Severity) Changes {
+	var filtered Changes
+	for _, c := range cs {
+		if c.Severity == severity {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// Sort sorts the changes by their severities, keeping the order of the changes of the same severity.
+func (cs Changes) Sort() {
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].Severity < cs[j].Severity
+	})
+}
+
+// Compare compares the old version of the files with the new one and returns the breaking changes
+// in the order of the files and the elements of the old version.
+// The references which cannot be linked are compared by the names as written.
+func Compare(oldSet, newSet *resolver.FileSet) Changes {
+	c := &comparer{
+		old: newIndex(oldSet),
+		new: newIndex(newSet),
+	}
+	c.compare()
+	return c.changes
+}
+
+type comparer struct {
+	old     *index
+	new     *index
+	changes Changes
+
+	// moves are the fully qualified names of the declarations in the new version keyed by the ones in the old version.
+	moves map[string]string
+}
+
+func (c *comparer) report(
+	rule Rule,
+	severity Severity,
+	oldPos meta.Position,
+	newPos meta.Position,
+	format string,
+	a ...interface{},
+) {
+	c.changes = append(c.changes, &Change{
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, a...),
+		Old:      oldPos,
+		New:      newPos,
+	})
+}
+
+func newIndex(set *resolver.FileSet) *index {
+	// The unresolved references are compared by the names as written.
+	linked, _ := linker.Link(set)
+	idx := &index{
+		set:    set,
+		linked: linked,
+		decls:  make(map[string]*decl),
+		files:  make(map[string]*fileDecl),
+	}
+	for _, file := range set.Sorted() {
+		idx.addFile(file)
+	}
+	return idx
+}
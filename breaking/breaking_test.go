@@ -0,0 +1,261 @@
+package breaking_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/breaking"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+func resolve(t *testing.T, files map[string]string) *resolver.FileSet {
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	set, err := resolver.Resolve(
+		paths,
+		resolver.WithFiles(files),
+	)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	return set
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name        string
+		inputOld    map[string]string
+		inputNew    map[string]string
+		wantChanges []string
+	}{
+		{
+			name: "compatible changes",
+			inputOld: map[string]string{
+				"a.proto": `syntax = "proto3";
+message A {
+  int32 a = 1;
+  string b = 2;
+}
+enum E {
+  E_A = 0;
+  E_B = 1;
+}
+`,
+			},
+			inputNew: map[string]string{
+				"a.proto": `syntax = "proto3";
+message A {
+  int32 a = 1;
+  reserved 2;
+  int64 c = 3;
+}
+enum E {
+  E_A = 0;
+  reserved 1;
+}
+message B {}
+`,
+			},
+		},
+		{
+			name: "field changes",
+			inputOld: map[string]string{
+				"a.proto": `syntax = "proto3";
+package foo;
+message A {
+  int32 a = 1;
+  string b = 2;
+  int32 c = 3;
+  B d = 4;
+  repeated int32 e = 5;
+  int32 f = 6;
+}
+message B {}
+`,
+			},
+			inputNew: map[string]string{
+				"a.proto": `syntax = "proto3";
+package foo;
+message A {
+  int32 renamed = 1;
+  bytes b = 2;
+  int32 c = 30;
+  .foo.B d = 4;
+  int32 e = 5;
+}
+message B {}
+`,
+			},
+			wantChanges: []string{
+				`a.proto:4:3: source[field-renamed]: field 1 has been renamed from "a" to "renamed" in "foo.A"`,
+				`a.proto:5:3: wire[field-type-changed]: type of field "b" has been changed from "string" to "bytes" in "foo.A"`,
+				`a.proto:6:3: wire[field-number-changed]: number of field "c" has been changed from 3 to 30 in "foo.A"`,
+				`a.proto:8:3: wire[field-cardinality-changed]: field "e" has been changed from repeated to singular in "foo.A"`,
+				`a.proto:3:1: wire[field-removed]: field "f" with number 6 has been removed from "foo.A" without reserving the number`,
+			},
+		},
+		{
+			name: "enum value changes",
+			inputOld: map[string]string{
+				"a.proto": `syntax = "proto3";
+enum E {
+  E_A = 0;
+  E_B = 1;
+  E_C = 2;
+  E_D = 3;
+}
+`,
+			},
+			inputNew: map[string]string{
+				"a.proto": `syntax = "proto3";
+enum E {
+  E_A = 0;
+  E_RENAMED = 1;
+  E_C = 4;
+}
+`,
+			},
+			wantChanges: []string{
+				`a.proto:4:3: source[enum-value-renamed]: enum value 1 has been renamed from "E_B" to "E_RENAMED" in "E"`,
+				`a.proto:5:3: wire[enum-value-number-changed]: number of enum value "E_C" has been changed from 2 to 4 in "E"`,
+				`a.proto:2:1: wire[enum-value-removed]: enum value "E_D" with number 3 has been removed from "E" without reserving the number`,
+			},
+		},
+		{
+			name: "rpc changes",
+			inputOld: map[string]string{
+				"a.proto": `syntax = "proto3";
+service S {
+  rpc A(Req) returns (Res);
+  rpc B(Req) returns (stream Res);
+  rpc C(Req) returns (Res);
+}
+service T {}
+message Req {}
+message Res {}
+`,
+			},
+			inputNew: map[string]string{
+				"a.proto": `syntax = "proto3";
+service S {
+  rpc A(stream Req) returns (Res);
+  rpc B(Req) returns (Req);
+}
+message Req {}
+message Res {}
+`,
+			},
+			wantChanges: []string{
+				`a.proto:7:1: wire[service-removed]: service "T" has been removed`,
+				`a.proto:3:3: wire[rpc-streaming-changed]: request of rpc "A" has been changed from unary to streaming`,
+				`a.proto:4:3: wire[rpc-type-changed]: response type of rpc "B" has been changed from "Res" to "Req"`,
+				`a.proto:4:3: wire[rpc-streaming-changed]: response of rpc "B" has been changed from streaming to unary`,
+				`a.proto:2:1: wire[rpc-removed]: rpc "C" has been removed from "S"`,
+			},
+		},
+		{
+			name: "moved types and changed packages",
+			inputOld: map[string]string{
+				"a.proto": `syntax = "proto3";
+package foo;
+message A {
+  B b = 1;
+  message C {}
+}
+message B {}
+`,
+				"b.proto": `syntax = "proto3";
+package bar;
+message D {}
+`,
+			},
+			inputNew: map[string]string{
+				"a.proto": `syntax = "proto3";
+package foo;
+import "b.proto";
+message A {
+  baz.B b = 1;
+}
+message C {}
+`,
+				"b.proto": `syntax = "proto3";
+package baz;
+message B {}
+message D {}
+`,
+			},
+			wantChanges: []string{
+				`b.proto:2:1: wire[package-changed]: package of file "b.proto" has been changed from "bar" to "baz"`,
+				`a.proto:7:1: wire[type-moved]: message "foo.A.C" has been moved to "foo.C"`,
+				`b.proto:3:1: wire[type-moved]: message "foo.B" has been moved to "baz.B"`,
+			},
+		},
+		{
+			name: "a removed file and a removed message",
+			inputOld: map[string]string{
+				"a.proto": `syntax = "proto3";
+message A {}
+`,
+				"b.proto": `syntax = "proto3";
+message B {}
+`,
+			},
+			inputNew: map[string]string{
+				"a.proto": `syntax = "proto3";
+`,
+			},
+			wantChanges: []string{
+				`b.proto:1:1: source[file-removed]: file "b.proto" has been removed`,
+				`a.proto:2:1: source[message-removed]: message "A" has been removed`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			changes := breaking.Compare(resolve(t, test.inputOld), resolve(t, test.inputNew))
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, test.wantChanges) {
+				t.Errorf("got %v, but want %v", strings.Join(got, "\n"), strings.Join(test.wantChanges, "\n"))
+			}
+		})
+	}
+}
+
+func TestChanges_BySeverity(t *testing.T) {
+	changes := breaking.Changes{
+		{Rule: breaking.RuleFieldRenamed, Severity: breaking.SeveritySource},
+		{Rule: breaking.RuleFieldRemoved, Severity: breaking.SeverityWire},
+		{Rule: breaking.RuleEnumValueRenamed, Severity: breaking.SeveritySource},
+		{Rule: breaking.RuleRPCRemoved, Severity: breaking.SeverityWire},
+	}
+
+	wire := changes.BySeverity(breaking.SeverityWire)
+	if len(wire) != 2 || wire[0].Rule != breaking.RuleFieldRemoved || wire[1].Rule != breaking.RuleRPCRemoved {
+		t.Errorf("got %v, but want the wire changes", wire)
+	}
+
+	changes.Sort()
+	var got []breaking.Rule
+	for _, c := range changes {
+		got = append(got, c.Rule)
+	}
+	want := []breaking.Rule{
+		breaking.RuleFieldRemoved,
+		breaking.RuleRPCRemoved,
+		breaking.RuleFieldRenamed,
+		breaking.RuleEnumValueRenamed,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, but want %v", got, want)
+	}
+}
@@ -0,0 +1,389 @@
+package breaking
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+const (
+	// maxFieldNumber is the largest field number.
+	maxFieldNumber = 536870911
+	// maxEnumNumber is the largest enum value number.
+	maxEnumNumber = 2147483647
+)
+
+// match is a declaration in the old version and the one matched in the new version.
+type match struct {
+	old *decl
+	new *decl
+}
+
+func (c *comparer) compare() {
+	c.files()
+
+	// Matches all the declarations first so that the references to the moved types are compared correctly.
+	var matches []match
+	c.moves = make(map[string]string)
+	for _, fullName := range c.old.order {
+		oldDecl := c.old.decls[fullName]
+		newDecl := c.matchDecl(oldDecl)
+		if newDecl == nil {
+			continue
+		}
+		c.moves[oldDecl.fullName] = newDecl.fullName
+		matches = append(matches, match{old: oldDecl, new: newDecl})
+	}
+
+	for _, m := range matches {
+		switch m.old.kind {
+		case declKindMessage:
+			c.message(m.old, m.new)
+		case declKindEnum:
+			c.enum(m.old, m.new)
+		case declKindService:
+			c.service(m.old, m.new)
+		}
+	}
+}
+
+func (c *comparer) files() {
+	for _, path := range c.old.fileOrder {
+		oldFile := c.old.files[path]
+		newFile, ok := c.new.files[path]
+		if !ok {
+			c.report(RuleFileRemoved, SeveritySource, oldFile.pkgPos, meta.Position{},
+				"file %q has been removed", path)
+			continue
+		}
+		if oldFile.pkg != newFile.pkg {
+			c.report(RulePackageChanged, SeverityWire, oldFile.pkgPos, newFile.pkgPos,
+				"package of file %q has been changed from %q to %q", path, oldFile.pkg, newFile.pkg)
+		}
+	}
+}
+
+// matchDecl returns the declaration in the new version matched with the old one, reporting if it is
+// moved or removed. It returns nil if there is none.
+func (c *comparer) matchDecl(oldDecl *decl) *decl {
+	newDecl, ok := c.new.decls[oldDecl.fullName]
+	switch {
+	case ok && newDecl.kind == oldDecl.kind:
+		if newDecl.file.Path != oldDecl.file.Path {
+			c.report(RuleTypeMoved, SeveritySource, oldDecl.pos, newDecl.pos,
+				"%s %q has been moved from file %q to %q", oldDecl.kind, oldDecl.fullName, oldDecl.file.Path, newDecl.file.Path)
+		}
+		return newDecl
+	case !ok:
+		if newDecl = c.renamedByPackage(oldDecl); newDecl != nil {
+			// The change has been reported as the one of the package.
+			return newDecl
+		}
+		if newDecl = c.movedDecl(oldDecl); newDecl != nil {
+			c.report(RuleTypeMoved, SeverityWire, oldDecl.pos, newDecl.pos,
+				"%s %q has been moved to %q", oldDecl.kind, oldDecl.fullName, newDecl.fullName)
+			return newDecl
+		}
+	}
+	if _, ok := c.new.files[oldDecl.file.Path]; !ok {
+		// The change has been reported as the removal of the file.
+		return nil
+	}
+
+	switch oldDecl.kind {
+	case declKindMessage:
+		c.report(RuleMessageRemoved, SeveritySource, oldDecl.pos, meta.Position{},
+			"message %q has been removed", oldDecl.fullName)
+	case declKindEnum:
+		c.report(RuleEnumRemoved, SeveritySource, oldDecl.pos, meta.Position{},
+			"enum %q has been removed", oldDecl.fullName)
+	case declKindService:
+		c.report(RuleServiceRemoved, SeverityWire, oldDecl.pos, meta.Position{},
+			"service %q has been removed", oldDecl.fullName)
+	}
+	return nil
+}
+
+// renamedByPackage returns the declaration whose name differs from the old one only by the changed package.
+func (c *comparer) renamedByPackage(oldDecl *decl) *decl {
+	oldFile := c.old.files[oldDecl.file.Path]
+	newFile, ok := c.new.files[oldDecl.file.Path]
+	if !ok || oldFile.pkg == newFile.pkg {
+		return nil
+	}
+	name := oldDecl.fullName
+	if oldFile.pkg != "" {
+		name = strings.TrimPrefix(name, oldFile.pkg+".")
+	}
+	newDecl, ok := c.new.decls[join(newFile.pkg, name)]
+	if !ok || newDecl.kind != oldDecl.kind {
+		return nil
+	}
+	return newDecl
+}
+
+// movedDecl returns the only new declaration of the same kind and the same name as the old one.
+func (c *comparer) movedDecl(oldDecl *decl) *decl {
+	var found *decl
+	for _, fullName := range c.new.order {
+		newDecl := c.new.decls[fullName]
+		if newDecl.kind != oldDecl.kind || newDecl.name() != oldDecl.name() {
+			continue
+		}
+		if _, ok := c.old.decls[fullName]; ok {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = newDecl
+	}
+	return found
+}
+
+// typeName returns the name of the type in the old version translated into the one in the new version.
+func (c *comparer) typeName(name string) string {
+	if moved, ok := c.moves[name]; ok {
+		return moved
+	}
+	return name
+}
+
+// field is a field declared in a message.
+type field struct {
+	name     string
+	number   int64
+	typ      string
+	repeated bool
+	pos      meta.Position
+}
+
+func fields(idx *index, d *decl) []*field {
+	var fs []*field
+	add := func(name, number, typ string, repeated bool, pos meta.Position) {
+		n, err := parseNumber(number)
+		if err != nil {
+			return
+		}
+		fs = append(fs, &field{name: name, number: n, typ: typ, repeated: repeated, pos: pos})
+	}
+	for _, element := range d.body {
+		switch e := element.(type) {
+		case *parser.Field:
+			add(e.FieldName, e.FieldNumber, idx.typeName(e, e.Type), e.IsRepeated, e.Meta.Pos)
+		case *parser.MapField:
+			typ := "map<" + e.KeyType + ", " + idx.typeName(e, e.Type) + ">"
+			add(e.MapName, e.FieldNumber, typ, false, e.Meta.Pos)
+		case *parser.GroupField:
+			add(strings.ToLower(e.GroupName), e.FieldNumber, join(d.fullName, e.GroupName), e.IsRepeated, e.Meta.Pos)
+		case *parser.Oneof:
+			for _, f := range e.OneofFields {
+				add(f.FieldName, f.FieldNumber, idx.typeName(f, f.Type), false, f.Meta.Pos)
+			}
+		}
+	}
+	return fs
+}
+
+func (c *comparer) message(oldDecl, newDecl *decl) {
+	newFields := fields(c.new, newDecl)
+	byNumber := make(map[int64]*field)
+	byName := make(map[string]*field)
+	for _, f := range newFields {
+		byNumber[f.number] = f
+		byName[f.name] = f
+	}
+	reserved := reservedNumbers(newDecl.body, maxFieldNumber)
+
+	for _, f := range fields(c.old, oldDecl) {
+		if nf, ok := byNumber[f.number]; ok {
+			if nf.name != f.name {
+				c.report(RuleFieldRenamed, SeveritySource, f.pos, nf.pos,
+					"field %d has been renamed from %q to %q in %q", f.number, f.name, nf.name, newDecl.fullName)
+			}
+			if typ := c.typeName(f.typ); typ != nf.typ {
+				c.report(RuleFieldTypeChanged, SeverityWire, f.pos, nf.pos,
+					"type of field %q has been changed from %q to %q in %q", nf.name, f.typ, nf.typ, newDecl.fullName)
+			}
+			if f.repeated != nf.repeated {
+				c.report(RuleFieldCardinalityChanged, SeverityWire, f.pos, nf.pos,
+					"field %q has been changed from %s to %s in %q",
+					nf.name, cardinality(f.repeated), cardinality(nf.repeated), newDecl.fullName)
+			}
+			continue
+		}
+		if nf, ok := byName[f.name]; ok {
+			c.report(RuleFieldNumberChanged, SeverityWire, f.pos, nf.pos,
+				"number of field %q has been changed from %d to %d in %q", f.name, f.number, nf.number, newDecl.fullName)
+			continue
+		}
+		if !reserved(f.number) {
+			c.report(RuleFieldRemoved, SeverityWire, f.pos, newDecl.pos,
+				"field %q with number %d has been removed from %q without reserving the number",
+				f.name, f.number, newDecl.fullName)
+		}
+	}
+}
+
+func cardinality(repeated bool) string {
+	if repeated {
+		return "repeated"
+	}
+	return "singular"
+}
+
+// enumValue is a value declared in an enum.
+type enumValue struct {
+	name   string
+	number int64
+	pos    meta.Position
+}
+
+func enumValues(d *decl) []*enumValue {
+	var values []*enumValue
+	for _, element := range d.body {
+		if e, ok := element.(*parser.EnumField); ok {
+			n, err := parseNumber(e.Number)
+			if err != nil {
+				continue
+			}
+			values = append(values, &enumValue{name: e.Ident, number: n, pos: e.Meta.Pos})
+		}
+	}
+	return values
+}
+
+func (c *comparer) enum(oldDecl, newDecl *decl) {
+	byNumber := make(map[int64]*enumValue)
+	byName := make(map[string]*enumValue)
+	for _, v := range enumValues(newDecl) {
+		if _, ok := byNumber[v.number]; !ok {
+			byNumber[v.number] = v
+		}
+		byName[v.name] = v
+	}
+	reserved := reservedNumbers(newDecl.body, maxEnumNumber)
+
+	for _, v := range enumValues(oldDecl) {
+		if nv, ok := byName[v.name]; ok {
+			if nv.number != v.number {
+				c.report(RuleEnumValueNumberChanged, SeverityWire, v.pos, nv.pos,
+					"number of enum value %q has been changed from %d to %d in %q", v.name, v.number, nv.number, newDecl.fullName)
+			}
+			continue
+		}
+		if nv, ok := byNumber[v.number]; ok {
+			c.report(RuleEnumValueRenamed, SeveritySource, v.pos, nv.pos,
+				"enum value %d has been renamed from %q to %q in %q", v.number, v.name, nv.name, newDecl.fullName)
+			continue
+		}
+		if !reserved(v.number) {
+			c.report(RuleEnumValueRemoved, SeverityWire, v.pos, newDecl.pos,
+				"enum value %q with number %d has been removed from %q without reserving the number",
+				v.name, v.number, newDecl.fullName)
+		}
+	}
+}
+
+func rpcs(d *decl) map[string]*parser.RPC {
+	m := make(map[string]*parser.RPC)
+	for _, element := range d.body {
+		if rpc, ok := element.(*parser.RPC); ok {
+			m[rpc.RPCName] = rpc
+		}
+	}
+	return m
+}
+
+func (c *comparer) service(oldDecl, newDecl *decl) {
+	newRPCs := rpcs(newDecl)
+	for _, element := range oldDecl.body {
+		rpc, ok := element.(*parser.RPC)
+		if !ok {
+			continue
+		}
+		newRPC, ok := newRPCs[rpc.RPCName]
+		if !ok {
+			c.report(RuleRPCRemoved, SeverityWire, rpc.Meta.Pos, newDecl.pos,
+				"rpc %q has been removed from %q", rpc.RPCName, newDecl.fullName)
+			continue
+		}
+
+		oldRequest := c.typeName(c.old.typeName(rpc.RPCRequest, rpc.RPCRequest.MessageType))
+		newRequest := c.new.typeName(newRPC.RPCRequest, newRPC.RPCRequest.MessageType)
+		if oldRequest != newRequest {
+			c.report(RuleRPCTypeChanged, SeverityWire, rpc.Meta.Pos, newRPC.Meta.Pos,
+				"request type of rpc %q has been changed from %q to %q", rpc.RPCName, oldRequest, newRequest)
+		}
+		oldResponse := c.typeName(c.old.typeName(rpc.RPCResponse, rpc.RPCResponse.MessageType))
+		newResponse := c.new.typeName(newRPC.RPCResponse, newRPC.RPCResponse.MessageType)
+		if oldResponse != newResponse {
+			c.report(RuleRPCTypeChanged, SeverityWire, rpc.Meta.Pos, newRPC.Meta.Pos,
+				"response type of rpc %q has been changed from %q to %q", rpc.RPCName, oldResponse, newResponse)
+		}
+
+		if rpc.RPCRequest.IsStream != newRPC.RPCRequest.IsStream {
+			c.report(RuleRPCStreamingChanged, SeverityWire, rpc.Meta.Pos, newRPC.Meta.Pos,
+				"request of rpc %q has been changed from %s to %s",
+				rpc.RPCName, streaming(rpc.RPCRequest.IsStream), streaming(newRPC.RPCRequest.IsStream))
+		}
+		if rpc.RPCResponse.IsStream != newRPC.RPCResponse.IsStream {
+			c.report(RuleRPCStreamingChanged, SeverityWire, rpc.Meta.Pos, newRPC.Meta.Pos,
+				"response of rpc %q has been changed from %s to %s",
+				rpc.RPCName, streaming(rpc.RPCResponse.IsStream), streaming(newRPC.RPCResponse.IsStream))
+		}
+	}
+}
+
+func streaming(isStream bool) string {
+	if isStream {
+		return "streaming"
+	}
+	return "unary"
+}
+
+// reservedNumbers returns the function reporting whether the number is reserved in the body.
+func reservedNumbers(body []parser.Visitee, max int64) func(int64) bool {
+	type numberRange struct {
+		start int64
+		end   int64
+	}
+	var ranges []numberRange
+	for _, element := range body {
+		reserved, ok := element.(*parser.Reserved)
+		if !ok {
+			continue
+		}
+		for _, r := range reserved.Ranges {
+			start, err := parseNumber(r.Begin)
+			if err != nil {
+				continue
+			}
+			end := start
+			switch r.End {
+			case "":
+			case "max":
+				end = max
+			default:
+				if end, err = parseNumber(r.End); err != nil {
+					continue
+				}
+			}
+			ranges = append(ranges, numberRange{start: start, end: end})
+		}
+	}
+	return func(number int64) bool {
+		for _, r := range ranges {
+			if r.start <= number && number <= r.end {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func parseNumber(number string) (int64, error) {
+	return strconv.ParseInt(number, 0, 64)
+}
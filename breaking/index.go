@@ -0,0 +1,120 @@
+package breaking
+
+import (
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// declKind is the kind of a declaration.
+type declKind string
+
+const (
+	declKindMessage declKind = "message"
+	declKindEnum    declKind = "enum"
+	declKindService declKind = "service"
+)
+
+// decl is a message, an enum or a service declared in a version.
+type decl struct {
+	fullName string
+	kind     declKind
+	file     *resolver.File
+	// body is the body of the message or the group, the enum or the service.
+	body []parser.Visitee
+	pos  meta.Position
+}
+
+// name returns the name without the package and the enclosing messages.
+func (d *decl) name() string {
+	return d.fullName[strings.LastIndex(d.fullName, ".")+1:]
+}
+
+// fileDecl is a file in a version.
+type fileDecl struct {
+	file *resolver.File
+	pkg  string
+	// pkgPos is the position of the package statement, or the one of the start of the file if there is none.
+	pkgPos meta.Position
+}
+
+// index holds the declarations in a version.
+type index struct {
+	set    *resolver.FileSet
+	linked *linker.Result
+
+	// decls are the declarations keyed by their fully qualified names.
+	decls map[string]*decl
+	// order is the fully qualified names of the declarations in the order of the files and the elements.
+	order []string
+	// files are the files keyed by their import paths.
+	files map[string]*fileDecl
+	// fileOrder is the import paths of the files in the dependency order.
+	fileOrder []string
+}
+
+func (idx *index) addFile(file *resolver.File) {
+	pkg := linker.PackageName(file.Proto)
+	f := &fileDecl{
+		file:   file,
+		pkg:    pkg,
+		pkgPos: meta.Position{Filename: file.Filename, Offset: 0, Line: 1, Column: 1},
+	}
+	for _, element := range file.Proto.ProtoBody {
+		if p, ok := element.(*parser.Package); ok {
+			f.pkgPos = p.Meta.Pos
+			break
+		}
+	}
+	idx.files[file.Path] = f
+	idx.fileOrder = append(idx.fileOrder, file.Path)
+	idx.addBody(file, pkg, file.Proto.ProtoBody)
+}
+
+func (idx *index) addBody(file *resolver.File, scope string, body []parser.Visitee) {
+	for _, element := range body {
+		switch e := element.(type) {
+		case *parser.Message:
+			fullName := join(scope, e.MessageName)
+			idx.add(&decl{fullName: fullName, kind: declKindMessage, file: file, body: e.MessageBody, pos: e.Meta.Pos})
+			idx.addBody(file, fullName, e.MessageBody)
+		case *parser.GroupField:
+			fullName := join(scope, e.GroupName)
+			idx.add(&decl{fullName: fullName, kind: declKindMessage, file: file, body: e.MessageBody, pos: e.Meta.Pos})
+			idx.addBody(file, fullName, e.MessageBody)
+		case *parser.Enum:
+			idx.add(&decl{fullName: join(scope, e.EnumName), kind: declKindEnum, file: file, body: e.EnumBody, pos: e.Meta.Pos})
+		case *parser.Service:
+			idx.add(&decl{fullName: join(scope, e.ServiceName), kind: declKindService, file: file, body: e.ServiceBody, pos: e.Meta.Pos})
+		}
+	}
+}
+
+func (idx *index) add(d *decl) {
+	if _, ok := idx.decls[d.fullName]; ok {
+		return
+	}
+	idx.decls[d.fullName] = d
+	idx.order = append(idx.order, d.fullName)
+}
+
+// typeName returns the fully qualified name of the type referred by the node,
+// or the name as written if the reference cannot be resolved.
+func (idx *index) typeName(node interface{}, written string) string {
+	if idx.linked != nil {
+		if ref := idx.linked.ReferenceOf(node); ref != nil && ref.Symbol != nil {
+			return ref.Symbol.FullName
+		}
+	}
+	return strings.TrimPrefix(written, ".")
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
@@ -0,0 +1,159 @@
+// Command protobreaking reports the breaking changes between two versions of Protocol Buffer files.
+//
+// Usage:
+//
+//	protobreaking [flags] -old dir -new dir [path ...]
+//
+// The paths are the import paths of the files relative to the directories.
+// Without an explicit path, it compares all .proto files in the old directory recursively.
+// The imports which cannot be resolved are reported as warnings, and the files are compared without them.
+// It exits with 1 if any breaking change is found.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/breaking"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+var (
+	oldDir      = flag.String("old", "", "directory of the old version")
+	newDir      = flag.String("new", "", "directory of the new version")
+	importPaths = flag.String("I", "", "comma-separated import paths searched after the directory of each version")
+	wireOnly    = flag.Bool("wire-only", false, "report only the changes which break the wire compatibility")
+)
+
+func run() int {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: protobreaking [flags] -old dir -new dir [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *oldDir == "" || *newDir == "" {
+		flag.Usage()
+		return 2
+	}
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		var err error
+		paths, err = protoPaths(*oldDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	oldSet, err := resolveSet(*oldDir, paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	newPaths, err := protoPaths(*newDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	newSet, err := resolveSet(*newDir, existing(*newDir, append(paths, newPaths...)))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	changes := breaking.Compare(oldSet, newSet)
+	if *wireOnly {
+		changes = changes.BySeverity(breaking.SeverityWire)
+	}
+	if len(changes) == 0 {
+		return 0
+	}
+	printChanges(os.Stdout, changes)
+	return 1
+}
+
+// protoPaths returns the paths of all .proto files in the directory relative to it.
+func protoPaths(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".proto") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	return paths, err
+}
+
+// existing returns the paths which exist in the directory without duplicates.
+func existing(dir string, paths []string) []string {
+	seen := make(map[string]bool)
+	var found []string
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		found = append(found, path)
+	}
+	return found
+}
+
+func resolveSet(dir string, paths []string) (*resolver.FileSet, error) {
+	dirs := []string{dir}
+	if *importPaths != "" {
+		dirs = append(dirs, strings.Split(*importPaths, ",")...)
+	}
+	set, err := resolver.Resolve(paths, resolver.WithImportPaths(dirs...))
+	var errs resolver.Errors
+	if err != nil && !errors.As(err, &errs) {
+		return nil, fmt.Errorf("failed to resolve the files in %s, err %v", dir, err)
+	}
+	for _, e := range errs {
+		// The compared files themselves must be resolved, or they would be reported as removed.
+		if e.Pos.Line == 0 {
+			return nil, fmt.Errorf("failed to resolve the files in %s, err %v", dir, e)
+		}
+		fmt.Fprintf(os.Stderr, "warning: %v\n", e)
+	}
+	return set, nil
+}
+
+func printChanges(w io.Writer, changes breaking.Changes) {
+	for _, severity := range []breaking.Severity{breaking.SeverityWire, breaking.SeveritySource} {
+		group := changes.BySeverity(severity)
+		if len(group) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s-incompatible changes:\n", severity)
+		for _, c := range group {
+			fmt.Fprintf(w, "  [%s] %s\n", c.Rule, c.Message)
+			if c.Old.Line != 0 {
+				fmt.Fprintf(w, "    old: %s\n", c.Old)
+			}
+			if c.New.Line != 0 {
+				fmt.Fprintf(w, "    new: %s\n", c.New)
+			}
+		}
+	}
+}
+
+func main() {
+	os.Exit(run())
+}
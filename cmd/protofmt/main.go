@@ -0,0 +1,142 @@
+// Command protofmt formats Protocol Buffer files.
+//
+// Usage:
+//
+//	protofmt [flags] [path ...]
+//
+// Without an explicit path, it formats the standard input.
+// Given a directory, it formats all .proto files in it recursively.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/formatter"
+)
+
+var (
+	write         = flag.Bool("w", false, "write the result to the source file instead of the standard output")
+	list          = flag.Bool("l", false, "list the files whose formatting differs from protofmt's")
+	indentWidth   = flag.Int("indent", 2, "number of spaces used for one level of indentation")
+	alignment     = flag.Bool("align", true, "align the field numbers, the options and the inline comments")
+	maxLineLength = flag.Int("max-line-length", 0, "wrap the field options if the line is longer than this, or 0 for no limit")
+	sortImports   = flag.Bool("sort-imports", true, "sort the consecutive import statements")
+)
+
+func run() int {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: protofmt [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	f := formatter.NewFormatter(
+		formatter.WithIndentWidth(*indentWidth),
+		formatter.WithAlignment(*alignment),
+		formatter.WithMaxLineLength(*maxLineLength),
+		formatter.WithSortImports(*sortImports),
+	)
+
+	if flag.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "cannot use -w with the standard input")
+			return 2
+		}
+		if err := processFile(f, "<standard input>", os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	exitCode := 0
+	for _, path := range flag.Args() {
+		err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".proto") {
+				return nil
+			}
+			if err := processPath(f, path, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exitCode = 1
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+func processPath(f *formatter.Formatter, path string, out io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close %s, err %v\n", path, err)
+		}
+	}()
+	return processFile(f, path, file, out)
+}
+
+func processFile(f *formatter.Formatter, filename string, in io.Reader, out io.Writer) error {
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	proto, err := protoparser.Parse(
+		bytes.NewReader(src),
+		protoparser.WithPermissive(true),
+		protoparser.WithBodyIncludingComments(true),
+		protoparser.WithTrivia(true),
+		protoparser.WithOptionValue(true),
+		protoparser.WithFilename(filename),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s, err %v", filename, err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, proto); err != nil {
+		return fmt.Errorf("failed to format %s, err %v", filename, err)
+	}
+	res := buf.Bytes()
+
+	if *list {
+		if !bytes.Equal(src, res) {
+			fmt.Fprintln(out, filename)
+		}
+		if !*write {
+			return nil
+		}
+	}
+	if *write {
+		if bytes.Equal(src, res) {
+			return nil
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, res, info.Mode().Perm())
+	}
+	_, err = out.Write(res)
+	return err
+}
+
+func main() {
+	os.Exit(run())
+}
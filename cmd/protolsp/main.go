@@ -0,0 +1,45 @@
+// Command protolsp is a Language Server Protocol server for Protocol Buffer files.
+//
+// Usage:
+//
+//	protolsp [flags]
+//
+// It talks to the editor over the standard input and output.
+// The imported files are searched in the root folders of the workspace and the import paths.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/lsp"
+)
+
+var (
+	importPaths = flag.String("I", "", "comma-separated import paths searched after the root folders of the workspace")
+)
+
+func run() int {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: protolsp [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var opts []lsp.Option
+	if *importPaths != "" {
+		opts = append(opts, lsp.WithImportPaths(strings.Split(*importPaths, ",")...))
+	}
+	if err := lsp.NewServer(opts...).Serve(context.Background(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}
@@ -0,0 +1,175 @@
+package diagnostic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// FromError converts the error returned by the parser, the resolver or the linker into diagnostics.
+// Each error of a list, such as parser.Errors and linker.Errors, becomes a diagnostic.
+// An error without structured information becomes a diagnostic with CodeUnknown.
+// The columns of the positions must count the Unicode code points, which is the default of the parser.
+func FromError(err error) Diagnostics {
+	return FromErrorEncoding(err, meta.EncodingRune)
+}
+
+// FromErrorEncoding is like FromError, but the columns of the positions count the units of enc,
+// like the ones of the source parsed with protoparser.WithColumnEncoding.
+func FromErrorEncoding(err error, enc meta.Encoding) Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	switch e := err.(type) {
+	case Diagnostics:
+		return e
+	case parser.Errors:
+		var diags Diagnostics
+		for _, metaErr := range e {
+			diags = append(diags, FromErrorEncoding(metaErr, enc)...)
+		}
+		return diags
+	case linker.Errors:
+		var diags Diagnostics
+		for _, refErr := range e {
+			diags = append(diags, FromErrorEncoding(refErr, enc)...)
+		}
+		return diags
+	case interface{ Unwrap() []error }:
+		var diags Diagnostics
+		for _, wrapped := range e.Unwrap() {
+			diags = append(diags, FromErrorEncoding(wrapped, enc)...)
+		}
+		return diags
+	}
+
+	var diag *Diagnostic
+	if errors.As(err, &diag) {
+		return Diagnostics{diag}
+	}
+	var importErr *resolver.ImportError
+	if errors.As(err, &importErr) {
+		return fromImportError(importErr, enc)
+	}
+	var refErr *linker.ReferenceError
+	if errors.As(err, &refErr) {
+		return Diagnostics{fromReferenceError(refErr)}
+	}
+	var metaErr *meta.Error
+	if errors.As(err, &metaErr) {
+		return Diagnostics{fromMetaError(metaErr, enc)}
+	}
+	return Diagnostics{
+		{
+			Code:     CodeUnknown,
+			Severity: SeverityError,
+			Message:  err.Error(),
+		},
+	}
+}
+
+func fromMetaError(e *meta.Error, enc meta.Encoding) *Diagnostic {
+	diag := &Diagnostic{
+		Severity: SeverityError,
+		Range:    RangeOfEncoding(e.Pos, e.Text, enc),
+	}
+	switch {
+	case e.Expected == "":
+		diag.Code = CodeUnknown
+		diag.Message = e.Found
+	case e.Text == "":
+		diag.Code = CodeUnexpectedEOF
+		diag.Message = fmt.Sprintf("unexpected end of input, expected %s", e.Expected)
+	default:
+		diag.Code = CodeUnexpectedToken
+		diag.Message = fmt.Sprintf("unexpected %q, expected %s", e.Text, e.Expected)
+	}
+	return diag
+}
+
+func fromImportError(e *resolver.ImportError, enc meta.Encoding) Diagnostics {
+	newDiag := func(code Code, msg string) Diagnostics {
+		return Diagnostics{
+			{
+				Code:     code,
+				Severity: SeverityError,
+				Message:  msg,
+				Range:    RangeOf(e.Pos, ""),
+			},
+		}
+	}
+
+	switch {
+	case errors.Is(e.Err, resolver.ErrFileNotFound):
+		return newDiag(CodeImportNotFound, fmt.Sprintf("file %q is not found", e.Path))
+	case errors.Is(e.Err, resolver.ErrImportCycle):
+		return newDiag(CodeImportCycle, fmt.Sprintf("import cycle: %s", strings.Join(e.Cycle, " -> ")))
+	}
+
+	diags := FromErrorEncoding(e.Err, enc)
+	for _, diag := range diags {
+		if diag.Code == CodeUnknown {
+			return newDiag(CodeImportFailed, fmt.Sprintf("failed to import %q: %v", e.Path, e.Err))
+		}
+	}
+	if e.Pos.Line != 0 {
+		for _, diag := range diags {
+			diag.Related = append(diag.Related, RelatedLocation{
+				Range:   RangeOf(e.Pos, ""),
+				Message: fmt.Sprintf("%q is imported here", e.Path),
+			})
+		}
+	}
+	return diags
+}
+
+func fromReferenceError(e *linker.ReferenceError) *Diagnostic {
+	diag := &Diagnostic{
+		Severity: SeverityError,
+		Range:    RangeOf(e.Pos, ""),
+	}
+	if errors.Is(e.Err, linker.ErrAmbiguous) {
+		diag.Code = CodeAmbiguousReference
+	} else {
+		diag.Code = CodeUnresolvedReference
+	}
+
+	diag.Message = fmt.Sprintf("%v %q", e.Err, e.Name)
+	if e.Detail != "" {
+		diag.Message += ", " + e.Detail
+	}
+
+	for _, candidate := range e.Candidates {
+		pos, ok := declarationPos(candidate.Node)
+		if !ok {
+			continue
+		}
+		diag.Related = append(diag.Related, RelatedLocation{
+			Range:   RangeOf(pos, ""),
+			Message: fmt.Sprintf("%q is declared here", candidate.FullName),
+		})
+	}
+	return diag
+}
+
+func declarationPos(node parser.Visitee) (meta.Position, bool) {
+	switch n := node.(type) {
+	case *parser.Package:
+		return n.Meta.Pos, true
+	case *parser.Message:
+		return n.Meta.Pos, true
+	case *parser.GroupField:
+		return n.Meta.Pos, true
+	case *parser.Enum:
+		return n.Meta.Pos, true
+	case *parser.Service:
+		return n.Meta.Pos, true
+	}
+	return meta.Position{}, false
+}
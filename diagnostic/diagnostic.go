@@ -0,0 +1,149 @@
+// Package diagnostic provides structured diagnostics for the errors reported by the parser,
+// the resolver and the linker, and renders them with snippets of the source.
+package diagnostic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// Severity is the seriousness of a diagnostic.
+type Severity uint
+
+// Severity values. They are ordered from the most serious one.
+const (
+	SeverityError Severity = iota + 1
+	SeverityWarning
+	SeverityInfo
+	SeverityHint
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	case SeverityHint:
+		return "hint"
+	}
+	return "unknown"
+}
+
+// Code identifies the kind of a diagnostic.
+// It is stable across releases so that tools can filter or suppress diagnostics by it.
+type Code string
+
+// Code values.
+const (
+	// CodeUnexpectedToken is the code of a syntax error at an unexpected token.
+	CodeUnexpectedToken Code = "unexpected-token"
+	// CodeUnexpectedEOF is the code of a syntax error at the end of the input.
+	CodeUnexpectedEOF Code = "unexpected-eof"
+	// CodeImportNotFound is the code of an import whose file is not found.
+	CodeImportNotFound Code = "import-not-found"
+	// CodeImportCycle is the code of imports forming a cycle.
+	CodeImportCycle Code = "import-cycle"
+	// CodeImportFailed is the code of an import whose file cannot be read.
+	CodeImportFailed Code = "import-failed"
+	// CodeUnresolvedReference is the code of a type reference matching no declaration.
+	CodeUnresolvedReference Code = "unresolved-reference"
+	// CodeAmbiguousReference is the code of a type reference matching more than one declaration.
+	CodeAmbiguousReference Code = "ambiguous-reference"
+	// CodeUnknown is the code of an error which carries no structured information.
+	CodeUnknown Code = "unknown"
+)
+
+// Range is a span of the source.
+// End is the position just after the span.
+// It is the zero value if the length of the span is unknown.
+type Range struct {
+	Start meta.Position
+	End   meta.Position
+}
+
+// RangeOf returns the Range of the text placed at the position whose column counts the Unicode code points,
+// which is the default of the parser.
+// The Range has an unknown length if the text is empty or spans several lines.
+func RangeOf(pos meta.Position, text string) Range {
+	return RangeOfEncoding(pos, text, meta.EncodingRune)
+}
+
+// RangeOfEncoding returns the Range of the text placed at the position whose column counts the units of enc.
+// The Range has an unknown length if the text is empty or spans several lines.
+func RangeOfEncoding(pos meta.Position, text string, enc meta.Encoding) Range {
+	r := Range{Start: pos}
+	if text == "" || strings.ContainsAny(text, "\r\n") {
+		return r
+	}
+	r.End = pos
+	r.End.Offset += len(text)
+	for _, ch := range text {
+		r.End.Column += enc.Len(ch)
+	}
+	return r
+}
+
+// RelatedLocation is a location of the source related to a diagnostic, such as a conflicting declaration.
+type RelatedLocation struct {
+	Range   Range
+	Message string
+}
+
+// Diagnostic is a problem found in the source.
+type Diagnostic struct {
+	Code     Code
+	Severity Severity
+	Message  string
+	Range    Range
+	// Related are the locations related to the problem, if any.
+	Related []RelatedLocation
+}
+
+func (d *Diagnostic) Error() string {
+	msg := fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Message)
+	if d.Range.Start.Line == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", d.Range.Start, msg)
+}
+
+// Diagnostics is a list of diagnostics.
+type Diagnostics []*Diagnostic
+
+func (d Diagnostics) Error() string {
+	var msgs []string
+	for _, diag := range d {
+		msgs = append(msgs, diag.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// HasErrors reports whether any of the diagnostics has SeverityError.
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort sorts the diagnostics by the filename and the position.
+func (d Diagnostics) Sort() {
+	sort.SliceStable(d, func(i, j int) bool {
+		a, b := d[i].Range.Start, d[j].Range.Start
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
@@ -0,0 +1,238 @@
+package diagnostic_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/diagnostic"
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+func accessor(files map[string]string) resolver.FileAccessor {
+	return func(filename string) (io.ReadCloser, error) {
+		content, ok := files[filepath.ToSlash(filename)]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+// summarize formats the diagnostic into a line, followed by a line per related location.
+func summarize(diags diagnostic.Diagnostics) []string {
+	var lines []string
+	for _, d := range diags {
+		end := "?"
+		if d.Range.End.Line != 0 {
+			end = fmt.Sprintf("%d:%d", d.Range.End.Line, d.Range.End.Column)
+		}
+		lines = append(lines, fmt.Sprintf("%s-%s %s %s: %s", d.Range.Start, end, d.Severity, d.Code, d.Message))
+		for _, r := range d.Related {
+			lines = append(lines, fmt.Sprintf("  %s: %s", r.Range.Start, r.Message))
+		}
+	}
+	return lines
+}
+
+func TestFromError(t *testing.T) {
+	parse := func(input string, opts ...protoparser.Option) error {
+		_, err := protoparser.Parse(
+			strings.NewReader(input),
+			append(opts, protoparser.WithFilename("a.proto"))...,
+		)
+		return err
+	}
+	resolve := func(files map[string]string) error {
+		_, err := resolver.Resolve([]string{"a.proto"}, resolver.WithAccessor(accessor(files)))
+		return err
+	}
+	link := func(files map[string]string) error {
+		set, err := resolver.Resolve([]string{"a.proto"}, resolver.WithAccessor(accessor(files)))
+		if err != nil {
+			return err
+		}
+		_, err = linker.Link(set)
+		return err
+	}
+
+	tests := []struct {
+		name      string
+		inputErr  error
+		wantDiags []string
+	}{
+		{
+			name:     "converting nil",
+			inputErr: nil,
+		},
+		{
+			name:     "converting a syntax error",
+			inputErr: parse("message A {\n  int32 = 1;\n}\n"),
+			wantDiags: []string{
+				`a.proto:2:9-2:10 error unexpected-token: unexpected "=", expected fieldName`,
+			},
+		},
+		{
+			name:     "converting every syntax error found in the recovery mode",
+			inputErr: parse("message A {\n  int32 = 1;\n  string = 2;\n", protoparser.WithRecovery(true)),
+			wantDiags: []string{
+				`a.proto:2:9-2:10 error unexpected-token: unexpected "=", expected fieldName`,
+				`a.proto:3:10-3:11 error unexpected-token: unexpected "=", expected fieldName`,
+				`a.proto:4:1-? error unexpected-eof: unexpected end of input, expected }`,
+			},
+		},
+		{
+			name: "converting an import error",
+			inputErr: resolve(map[string]string{
+				"a.proto": "syntax = \"proto3\";\nimport \"b.proto\";\n",
+			}),
+			wantDiags: []string{
+				`a.proto:2:1-? error import-not-found: file "b.proto" is not found`,
+			},
+		},
+		{
+			name: "converting every import error",
+			inputErr: resolve(map[string]string{
+				"a.proto": "syntax = \"proto3\";\nimport \"b.proto\";\nimport \"c.proto\";\n",
+			}),
+			wantDiags: []string{
+				`a.proto:2:1-? error import-not-found: file "b.proto" is not found`,
+				`a.proto:3:1-? error import-not-found: file "c.proto" is not found`,
+			},
+		},
+		{
+			name: "converting an import cycle",
+			inputErr: resolve(map[string]string{
+				"a.proto": "import \"b.proto\";\n",
+				"b.proto": "import \"a.proto\";\n",
+			}),
+			wantDiags: []string{
+				`b.proto:1:1-? error import-cycle: import cycle: a.proto -> b.proto -> a.proto`,
+			},
+		},
+		{
+			name: "converting a syntax error in an imported file",
+			inputErr: resolve(map[string]string{
+				"a.proto": "syntax = \"proto3\";\nimport \"b.proto\";\n",
+				"b.proto": "message B {\n  int32 = 1;\n}\n",
+			}),
+			wantDiags: []string{
+				`b.proto:2:9-2:10 error unexpected-token: unexpected "=", expected fieldName`,
+				`  a.proto:2:1: "b.proto" is imported here`,
+			},
+		},
+		{
+			name: "converting reference errors",
+			inputErr: link(map[string]string{
+				"a.proto": `syntax = "proto3";
+import "b.proto";
+message A {
+  Unknown u = 1;
+  B b = 2;
+}
+`,
+				"b.proto": `syntax = "proto3";
+message B {}
+message B {}
+`,
+			}),
+			wantDiags: []string{
+				`a.proto:4:3-? error unresolved-reference: unresolved reference "Unknown"`,
+				`a.proto:5:3-? error ambiguous-reference: ambiguous reference "B", "B" is declared 2 times`,
+				`  b.proto:2:1: "B" is declared here`,
+				`  b.proto:3:1: "B" is declared here`,
+			},
+		},
+		{
+			name:     "converting an error without structured information",
+			inputErr: errors.New("something went wrong"),
+			wantDiags: []string{
+				`<input>:0:0-? error unknown: something went wrong`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got := summarize(diagnostic.FromError(test.inputErr))
+			if !reflect.DeepEqual(got, test.wantDiags) {
+				t.Errorf("got %q, but want %q", got, test.wantDiags)
+			}
+		})
+	}
+}
+
+func TestDiagnostics_Error(t *testing.T) {
+	diags := diagnostic.FromError(errors.Join(
+		errors.New("something went wrong"),
+		func() error {
+			_, err := protoparser.Parse(strings.NewReader("message A {\n  int32 = 1;\n}\n"))
+			return err
+		}(),
+	))
+
+	want := `error[unknown]: something went wrong
+<input>:2:9: error[unexpected-token]: unexpected "=", expected fieldName`
+	if got := diags.Error(); got != want {
+		t.Errorf("got %q, but want %q", got, want)
+	}
+	if !diags.HasErrors() {
+		t.Errorf("got false, but want true")
+	}
+}
+
+func TestRangeOfEncoding(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputText     string
+		inputEncoding meta.Encoding
+		wantEnd       meta.Position
+	}{
+		{
+			name:          "counting the code points",
+			inputText:     `"日本😀"`,
+			inputEncoding: meta.EncodingRune,
+			wantEnd:       meta.Position{Offset: 22, Line: 2, Column: 8},
+		},
+		{
+			name:          "counting the bytes",
+			inputText:     `"日本😀"`,
+			inputEncoding: meta.EncodingUTF8,
+			wantEnd:       meta.Position{Offset: 22, Line: 2, Column: 15},
+		},
+		{
+			name:          "counting the UTF-16 code units",
+			inputText:     `"日本😀"`,
+			inputEncoding: meta.EncodingUTF16,
+			wantEnd:       meta.Position{Offset: 22, Line: 2, Column: 9},
+		},
+		{
+			name:          "spanning several lines",
+			inputText:     "\"a\nb\"",
+			inputEncoding: meta.EncodingUTF8,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			start := meta.Position{Offset: 10, Line: 2, Column: 3}
+			got := diagnostic.RangeOfEncoding(start, test.inputText, test.inputEncoding)
+			if got.Start != start {
+				t.Errorf("got %v, but want %v", got.Start, start)
+			}
+			if got.End != test.wantEnd {
+				t.Errorf("got %v, but want %v", got.End, test.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// Renderer renders diagnostics in the compiler style.
+// Each location is followed by the source line and a caret pointing at the range.
+//
+//	a.proto:3:9: error[unexpected-token]: unexpected "=", expected fieldName
+//	 3 |   int32 = 2;
+//	   |         ^
+type Renderer struct {
+	sources  map[string][]byte
+	accessor resolver.FileAccessor
+	encoding meta.Encoding
+}
+
+// Option is an option for NewRenderer.
+type Option func(*Renderer)
+
+// WithSource is an option to give the content of the file named filename.
+// The filename is the one of the positions, which is empty if the source was parsed without a filename.
+func WithSource(filename string, src []byte) Option {
+	return func(r *Renderer) {
+		r.sources[filename] = src
+	}
+}
+
+// WithAccessor is an option to set how to read the files not given by WithSource.
+// The default reads them from the file system.
+func WithAccessor(accessor resolver.FileAccessor) Option {
+	return func(r *Renderer) {
+		r.accessor = accessor
+	}
+}
+
+// WithColumnEncoding is an option to set the Encoding the columns of the positions count.
+// It must be the one the source was parsed with. The default is meta.EncodingRune.
+func WithColumnEncoding(enc meta.Encoding) Option {
+	return func(r *Renderer) {
+		r.encoding = enc
+	}
+}
+
+// NewRenderer creates a new Renderer.
+func NewRenderer(opts ...Option) *Renderer {
+	r := &Renderer{
+		sources: make(map[string][]byte),
+		accessor: func(filename string) (io.ReadCloser, error) {
+			return os.Open(filename)
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Render writes the diagnostics to w.
+// The source snippet is omitted if the file cannot be read.
+func (r *Renderer) Render(w io.Writer, diags Diagnostics) error {
+	lines := make(map[string][]string)
+	sourceLine := func(pos meta.Position) (string, bool) {
+		ls, ok := lines[pos.Filename]
+		if !ok {
+			ls = r.readLines(pos.Filename)
+			lines[pos.Filename] = ls
+		}
+		if pos.Line < 1 || len(ls) < pos.Line {
+			return "", false
+		}
+		return ls[pos.Line-1], true
+	}
+
+	var b strings.Builder
+	for _, diag := range diags {
+		b.WriteString(diag.Error())
+		b.WriteString("\n")
+		writeSnippet(&b, diag.Range, r.encoding, sourceLine)
+
+		for _, related := range diag.Related {
+			fmt.Fprintf(&b, "%s: note: %s\n", related.Range.Start, related.Message)
+			writeSnippet(&b, related.Range, r.encoding, sourceLine)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Render writes the diagnostics to w with the given options.
+func Render(w io.Writer, diags Diagnostics, opts ...Option) error {
+	return NewRenderer(opts...).Render(w, diags)
+}
+
+func (r *Renderer) readLines(filename string) []string {
+	src, ok := r.sources[filename]
+	if !ok {
+		if filename == "" {
+			return nil
+		}
+		reader, err := r.accessor(filename)
+		if err != nil {
+			return nil
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		src, err = io.ReadAll(reader)
+		if err != nil {
+			return nil
+		}
+	}
+
+	lines := strings.Split(string(src), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// writeSnippet writes the source line at the start of the range and the caret line below it.
+// The caret line keeps the tabs of the source line so that the caret is placed under the range.
+func writeSnippet(
+	b *strings.Builder,
+	rng Range,
+	enc meta.Encoding,
+	sourceLine func(meta.Position) (string, bool),
+) {
+	line, ok := sourceLine(rng.Start)
+	if !ok {
+		return
+	}
+	runes := []rune(line)
+
+	number := strconv.Itoa(rng.Start.Line)
+	gutter := strings.Repeat(" ", len(number))
+	if line == "" {
+		fmt.Fprintf(b, " %s |\n", number)
+	} else {
+		fmt.Fprintf(b, " %s | %s\n", number, line)
+	}
+
+	start := runeIndex(runes, rng.Start.Column, enc)
+	var marker strings.Builder
+	for i := 0; i < start; i++ {
+		if i < len(runes) && runes[i] == '\t' {
+			marker.WriteRune('\t')
+		} else {
+			marker.WriteRune(' ')
+		}
+	}
+	marker.WriteRune('^')
+
+	end := start + 1
+	switch {
+	case rng.End.Line == rng.Start.Line:
+		end = runeIndex(runes, rng.End.Column, enc)
+	case rng.Start.Line < rng.End.Line:
+		end = len(runes)
+	}
+	for i := start + 1; i < end; i++ {
+		marker.WriteRune('~')
+	}
+	fmt.Fprintf(b, " %s | %s\n", gutter, marker.String())
+}
+
+// runeIndex returns the index of the rune placed at the column counting the units of enc.
+// The column in the middle of a rune is rounded up to the next one, and the one beyond the end of the line
+// counts a rune per column.
+func runeIndex(runes []rune, column int, enc meta.Encoding) int {
+	i := 0
+	for c := 1; c < column; i++ {
+		if i < len(runes) {
+			c += enc.Len(runes[i])
+		} else {
+			c++
+		}
+	}
+	return i
+}
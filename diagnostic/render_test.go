@@ -0,0 +1,163 @@
+package diagnostic_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/diagnostic"
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	tests := []struct {
+		name         string
+		inputDiags   func() diagnostic.Diagnostics
+		inputOpts    []diagnostic.Option
+		wantRendered string
+	}{
+		{
+			name: "rendering syntax errors",
+			inputDiags: func() diagnostic.Diagnostics {
+				_, err := protoparser.Parse(
+					strings.NewReader("message A {\n\tint32 = 1;\n  string s = 2\n"),
+					protoparser.WithRecovery(true),
+				)
+				return diagnostic.FromError(err)
+			},
+			inputOpts: []diagnostic.Option{
+				diagnostic.WithSource("", []byte("message A {\n\tint32 = 1;\n  string s = 2\n")),
+			},
+			wantRendered: `<input>:2:8: error[unexpected-token]: unexpected "=", expected fieldName
+ 2 | 	int32 = 1;
+   | 	      ^
+<input>:4:1: error[unexpected-eof]: unexpected end of input, expected ;
+ 4 |
+   | ^
+`,
+		},
+		{
+			name: "rendering a range and related locations",
+			inputDiags: func() diagnostic.Diagnostics {
+				return diagnostic.Diagnostics{
+					{
+						Code:     diagnostic.CodeAmbiguousReference,
+						Severity: diagnostic.SeverityWarning,
+						Message:  "ambiguous",
+						Range: diagnostic.RangeOf(
+							meta.Position{Filename: "a.proto", Line: 2, Column: 3},
+							"Foo",
+						),
+						Related: []diagnostic.RelatedLocation{
+							{
+								Range:   diagnostic.RangeOf(meta.Position{Filename: "b.proto", Line: 1, Column: 1}, ""),
+								Message: "declared here",
+							},
+							{
+								Range:   diagnostic.RangeOf(meta.Position{Filename: "missing.proto", Line: 1, Column: 1}, ""),
+								Message: "declared here",
+							},
+						},
+					},
+				}
+			},
+			inputOpts: []diagnostic.Option{
+				diagnostic.WithAccessor(accessor(map[string]string{
+					"a.proto": "message A {\n  Foo foo = 1;\n}\n",
+					"b.proto": "message Foo {}\n",
+				})),
+			},
+			wantRendered: `a.proto:2:3: warning[ambiguous-reference]: ambiguous
+ 2 |   Foo foo = 1;
+   |   ^~~
+b.proto:1:1: note: declared here
+ 1 | message Foo {}
+   | ^
+missing.proto:1:1: note: declared here
+`,
+		},
+		{
+			name: "rendering a range whose columns count the bytes",
+			inputDiags: func() diagnostic.Diagnostics {
+				_, err := protoparser.Parse(
+					strings.NewReader("message A {\n\tstring s = 1 [(o) = \"日本\"]; int32 b = \"日本\";\n}\n"),
+					protoparser.WithColumnEncoding(meta.EncodingUTF8),
+				)
+				return diagnostic.FromErrorEncoding(err, meta.EncodingUTF8)
+			},
+			inputOpts: []diagnostic.Option{
+				diagnostic.WithSource("", []byte("message A {\n\tstring s = 1 [(o) = \"日本\"]; int32 b = \"日本\";\n}\n")),
+				diagnostic.WithColumnEncoding(meta.EncodingUTF8),
+			},
+			wantRendered: `<input>:2:43: error[unexpected-token]: unexpected "\"", expected fieldNumber
+ 2 | 	string s = 1 [(o) = "日本"]; int32 b = "日本";
+   | 	                                     ^
+`,
+		},
+		{
+			name: "rendering a range whose columns count the UTF-16 code units",
+			inputDiags: func() diagnostic.Diagnostics {
+				return diagnostic.Diagnostics{
+					{
+						Code:     diagnostic.CodeUnknown,
+						Severity: diagnostic.SeverityError,
+						Message:  "invalid",
+						Range: diagnostic.RangeOfEncoding(
+							meta.Position{Filename: "a.proto", Line: 1, Column: 33},
+							`"😀"`,
+							meta.EncodingUTF16,
+						),
+					},
+				}
+			},
+			inputOpts: []diagnostic.Option{
+				diagnostic.WithSource("a.proto", []byte(`option (a) = "😀"; option (b) = "😀";`)),
+				diagnostic.WithColumnEncoding(meta.EncodingUTF16),
+			},
+			wantRendered: `a.proto:1:33: error[unknown]: invalid
+ 1 | option (a) = "😀"; option (b) = "😀";
+   |                                ^~~
+`,
+		},
+		{
+			name: "rendering diagnostics read from the files",
+			inputDiags: func() diagnostic.Diagnostics {
+				files := map[string]string{
+					"a.proto": "syntax = \"proto3\";\n\n\n\n\n\n\n\n\nmessage A {\n  B b = 1;\n}\n",
+				}
+				set, err := resolver.Resolve([]string{"a.proto"}, resolver.WithAccessor(accessor(files)))
+				if err != nil {
+					t.Fatalf("got err %v, but want nil", err)
+				}
+				_, err = linker.Link(set)
+				return diagnostic.FromError(err)
+			},
+			inputOpts: []diagnostic.Option{
+				diagnostic.WithAccessor(accessor(map[string]string{
+					"a.proto": "syntax = \"proto3\";\n\n\n\n\n\n\n\n\nmessage A {\n  B b = 1;\n}\n",
+				})),
+			},
+			wantRendered: `a.proto:11:3: error[unresolved-reference]: unresolved reference "B"
+ 11 |   B b = 1;
+    |   ^
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := diagnostic.Render(&b, test.inputDiags(), test.inputOpts...)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if got := b.String(); got != test.wantRendered {
+				t.Errorf("got %q, but want %q", got, test.wantRendered)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Package diff compares two versions of a Protocol Buffer file and lists the elements which are added,
+// removed or modified, including the changes of their options and comments.
+//
+// Unlike the breaking package, it reports every change regardless of the compatibility, so that it can be
+// used to generate changelogs or to review schema changes.
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// ChangeType is the kind of a change.
+type ChangeType int
+
+// ChangeType values.
+const (
+	// Added is the type of an element which exists only in the new version.
+	Added ChangeType = iota + 1
+	// Removed is the type of an element which exists only in the old version.
+	Removed
+	// Modified is the type of a property of an element which differs between the versions.
+	Modified
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t ChangeType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *ChangeType) UnmarshalText(text []byte) error {
+	for _, v := range []ChangeType{Added, Removed, Modified} {
+		if v.String() == string(text) {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown change type %q", text)
+}
+
+func (t ChangeType) sign() string {
+	switch t {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	}
+	return "~"
+}
+
+// Element is the kind of a compared element.
+type Element string
+
+// Element values.
+const (
+	ElementFile       Element = "file"
+	ElementSyntax     Element = "syntax"
+	ElementEdition    Element = "edition"
+	ElementPackage    Element = "package"
+	ElementImport     Element = "import"
+	ElementOption     Element = "option"
+	ElementMessage    Element = "message"
+	ElementField      Element = "field"
+	ElementOneof      Element = "oneof"
+	ElementReserved   Element = "reserved"
+	ElementExtensions Element = "extensions"
+	ElementExtend     Element = "extend"
+	ElementEnum       Element = "enum"
+	ElementEnumValue  Element = "enum value"
+	ElementService    Element = "service"
+	ElementRPC        Element = "rpc"
+)
+
+// Change is a difference between the two versions.
+//
+// The elements are matched by their identities: the messages, the enums and the services by their fully
+// qualified names, the fields by their numbers, the enum values, the oneofs and the RPCs by their names,
+// the options by their names, and the imports by their locations.
+type Change struct {
+	Type    ChangeType `json:"type"`
+	Element Element    `json:"element"`
+	// Path identifies the element, like "foo.Bar" for a message and "foo.Bar[1]" for its field.
+	// The options are identified by the path of their owner followed by their names, like "foo.Bar deprecated".
+	Path string `json:"path"`
+	// Property is the name of the modified property, like "name", "type", "value" and "comments".
+	// It is empty unless Type is Modified.
+	Property string `json:"property,omitempty"`
+	// Old is the value of the property in the old version if Type is Modified,
+	// or the summary of the element if Type is Removed.
+	Old string `json:"old,omitempty"`
+	// New is the value of the property in the new version if Type is Modified,
+	// or the summary of the element if Type is Added.
+	New string `json:"new,omitempty"`
+	// OldPos is the position of the element in the old version, or the zero value if it is added.
+	OldPos meta.Position `json:"oldPos"`
+	// NewPos is the position of the element in the new version, or the zero value if it is removed.
+	NewPos meta.Position `json:"newPos"`
+}
+
+// String returns the change along with the position in the new version, or in the old one if it is removed.
+func (c *Change) String() string {
+	pos := c.NewPos
+	if c.Type == Removed {
+		pos = c.OldPos
+	}
+	switch c.Type {
+	case Added, Removed:
+		summary := c.New
+		if c.Type == Removed {
+			summary = c.Old
+		}
+		if summary == "" {
+			return fmt.Sprintf("%s: %s %s %s", pos, c.Type.sign(), c.Element, c.Path)
+		}
+		return fmt.Sprintf("%s: %s %s %s: %s", pos, c.Type.sign(), c.Element, c.Path, summary)
+	}
+	return fmt.Sprintf("%s: %s %s %s %s: %q -> %q", pos, c.Type.sign(), c.Element, c.Path, c.Property, c.Old, c.New)
+}
+
+// Changes is a list of changes.
+type Changes []*Change
+
+// ByType returns the changes of the type.
+func (cs Changes) ByType(t ChangeType) Changes {
+	var filtered Changes
+	for _, c := range cs {
+		if c.Type == t {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// Diff compares the old version of the proto with the new one and returns the changes in the order of
+// the elements of the old version, followed by the ones added to each scope.
+//
+// An added or removed element is reported as a single change without the changes of its children.
+func Diff(oldProto, newProto *parser.Proto) Changes {
+	d := &differ{}
+	d.compare(newFileNode(oldProto), newFileNode(newProto))
+	return d.changes
+}
+
+type differ struct {
+	changes Changes
+}
+
+func (d *differ) compare(old, new *node) {
+	for _, p := range old.props {
+		if v := new.prop(p.name); v != p.value {
+			d.changes = append(d.changes, &Change{
+				Type:     Modified,
+				Element:  new.element,
+				Path:     new.path,
+				Property: p.name,
+				Old:      p.value,
+				New:      v,
+				OldPos:   old.pos,
+				NewPos:   new.pos,
+			})
+		}
+	}
+
+	matched := make(map[*node]bool)
+	for _, o := range old.children {
+		n := find(new.children, o)
+		if n == nil {
+			d.changes = append(d.changes, &Change{
+				Type:    Removed,
+				Element: o.element,
+				Path:    o.path,
+				Old:     summary(o),
+				OldPos:  o.pos,
+			})
+			continue
+		}
+		matched[n] = true
+		d.compare(o, n)
+	}
+	for _, n := range new.children {
+		if matched[n] {
+			continue
+		}
+		d.changes = append(d.changes, &Change{
+			Type:    Added,
+			Element: n.element,
+			Path:    n.path,
+			New:     summary(n),
+			NewPos:  n.pos,
+		})
+	}
+}
+
+func find(nodes []*node, target *node) *node {
+	for _, n := range nodes {
+		if n.element == target.element && n.key == target.key {
+			return n
+		}
+	}
+	return nil
+}
+
+// summary returns the text which describes the added or removed element besides its path.
+func summary(n *node) string {
+	switch n.element {
+	case ElementSyntax, ElementEdition:
+		return n.prop("value")
+	case ElementPackage:
+		return n.prop("name")
+	case ElementImport:
+		return strings.TrimSpace(n.prop("modifier") + " " + fmt.Sprintf("%q", n.key))
+	case ElementOption:
+		return n.key + " = " + n.prop("value")
+	case ElementField:
+		return strings.TrimSpace(n.prop("label")+" "+n.prop("type")) + " " + n.prop("name") + " = " + n.key
+	case ElementEnumValue:
+		return n.key + " = " + n.prop("number")
+	case ElementRPC:
+		return fmt.Sprintf("(%s) returns (%s)", n.prop("request"), n.prop("response"))
+	case ElementReserved, ElementExtensions:
+		return n.key
+	}
+	return ""
+}
@@ -0,0 +1,211 @@
+package diff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/diff"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func parse(t *testing.T, input string) *parser.Proto {
+	proto, err := protoparser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	return proto
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		inputOld    string
+		inputNew    string
+		wantChanges []string
+	}{
+		{
+			name: "no changes",
+			inputOld: `syntax = "proto3";
+package foo;
+message A {
+  int32 a = 1;
+}
+`,
+			inputNew: `syntax = "proto3";
+
+package foo;
+
+message A { int32 a = 1; }
+`,
+		},
+		{
+			name: "messages and fields",
+			inputOld: `syntax = "proto3";
+package foo;
+message A {
+  int32 a = 1;
+  string b = 2;
+  message Nested {}
+}
+message B {}
+`,
+			inputNew: `syntax = "proto3";
+package foo;
+message A {
+  int64 a = 1;
+  repeated string c = 2;
+  map<string, int32> d = 3;
+}
+message C {
+  int32 c = 1;
+}
+`,
+			wantChanges: []string{
+				`<input>:4:3: ~ field foo.A[1] type: "int32" -> "int64"`,
+				`<input>:5:3: ~ field foo.A[2] name: "b" -> "c"`,
+				`<input>:5:3: ~ field foo.A[2] label: "" -> "repeated"`,
+				`<input>:6:3: - message foo.A.Nested`,
+				`<input>:6:3: + field foo.A[3]: map<string, int32> d = 3`,
+				`<input>:8:1: - message foo.B`,
+				`<input>:8:1: + message foo.C`,
+			},
+		},
+		{
+			name: "options and comments",
+			inputOld: `syntax = "proto3";
+package foo;
+option go_package = "foo";
+// A is a message.
+message A {
+  option deprecated = true;
+  int32 a = 1 [deprecated = true]; // a
+}
+`,
+			inputNew: `syntax = "proto3";
+package foo;
+option go_package = "bar";
+option java_package = "bar";
+// A is the message.
+message A {
+  int32 a = 1 [deprecated = false, json_name = "b"];
+}
+`,
+			wantChanges: []string{
+				`<input>:3:1: ~ option go_package value: "\"foo\"" -> "\"bar\""`,
+				`<input>:6:1: ~ message foo.A comments: "// A is a message." -> "// A is the message."`,
+				`<input>:6:3: - option foo.A deprecated: deprecated = true`,
+				`<input>:7:3: ~ field foo.A[1] inline comment: "// a" -> ""`,
+				`<input>:7:3: ~ option foo.A[1] deprecated value: "true" -> "false"`,
+				`<input>:7:3: + option foo.A[1] json_name: json_name = "b"`,
+				`<input>:4:1: + option java_package: java_package = "bar"`,
+			},
+		},
+		{
+			name: "enums and services",
+			inputOld: `syntax = "proto3";
+import "a.proto";
+enum E {
+  E_A = 0;
+  E_B = 1;
+}
+service S {
+  rpc Get(A) returns (A);
+  rpc List(A) returns (A);
+}
+`,
+			inputNew: `syntax = "proto3";
+import public "a.proto";
+import "b.proto";
+enum E {
+  E_A = 0;
+  E_B = 2;
+  E_C = 3;
+}
+service S {
+  rpc Get(A) returns (stream A);
+}
+`,
+			wantChanges: []string{
+				`<input>:2:1: ~ import a.proto modifier: "" -> "public"`,
+				`<input>:6:3: ~ enum value E.E_B number: "1" -> "2"`,
+				`<input>:7:3: + enum value E.E_C: E_C = 3`,
+				`<input>:10:3: ~ rpc S.Get response: "A" -> "stream A"`,
+				`<input>:9:3: - rpc S.List: (A) returns (A)`,
+				`<input>:3:1: + import b.proto: "b.proto"`,
+			},
+		},
+		{
+			name: "reserved and syntax",
+			inputOld: `syntax = "proto2";
+message A {
+  reserved 1, 2;
+  extensions 100 to max;
+  optional int32 a = 3;
+}
+`,
+			inputNew: `edition = "2023";
+message A {
+  reserved 1 to 2;
+  extensions 100 to max;
+  int32 a = 3;
+}
+`,
+			wantChanges: []string{
+				`<input>:1:1: - syntax syntax: proto2`,
+				`<input>:3:3: - reserved A: 1, 2`,
+				`<input>:5:3: ~ field A[3] label: "optional" -> ""`,
+				`<input>:3:3: + reserved A: 1 to 2`,
+				`<input>:1:1: + edition edition: 2023`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			changes := diff.Diff(parse(t, test.inputOld), parse(t, test.inputNew))
+
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, test.wantChanges) {
+				t.Errorf("got %v, but want %v", strings.Join(got, "\n"), strings.Join(test.wantChanges, "\n"))
+			}
+		})
+	}
+}
+
+func TestChanges_WriteJSON(t *testing.T) {
+	changes := diff.Diff(
+		parse(t, `syntax = "proto3"; message A { int32 a = 1; }`),
+		parse(t, `syntax = "proto3"; message A { int32 b = 1; }`),
+	)
+
+	var buf bytes.Buffer
+	if err := changes.WriteJSON(&buf); err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	var got diff.Changes
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if !reflect.DeepEqual(got, changes) {
+		t.Errorf("got %v, but want %v", got, changes)
+	}
+	if !strings.Contains(buf.String(), `"type": "modified"`) {
+		t.Errorf("got %s, but want the type as text", buf.String())
+	}
+
+	buf.Reset()
+	if err := diff.Changes(nil).WriteJSON(&buf); err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("got %q, but want %q", got, "[]\n")
+	}
+}
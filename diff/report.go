@@ -0,0 +1,28 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteText writes the changes to w, one per line, in the format of Change.String.
+func (cs Changes) WriteText(w io.Writer) error {
+	for _, c := range cs {
+		if _, err := fmt.Fprintln(w, c.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the changes to w as a JSON array.
+// The changes are written as an empty array rather than null if there is none.
+func (cs Changes) WriteJSON(w io.Writer) error {
+	if cs == nil {
+		cs = Changes{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(cs)
+}
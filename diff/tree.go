@@ -0,0 +1,358 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// property is a compared attribute of an element.
+type property struct {
+	name  string
+	value string
+}
+
+// node is an element reduced to what is compared.
+type node struct {
+	element Element
+	// key identifies the node among the children of its parent.
+	key  string
+	path string
+	pos  meta.Position
+	// props are the compared attributes in the order they are reported.
+	props    []property
+	children []*node
+}
+
+// prop returns the value of the property named name.
+func (n *node) prop(name string) string {
+	for _, p := range n.props {
+		if p.name == name {
+			return p.value
+		}
+	}
+	return ""
+}
+
+func (n *node) addChild(child *node) {
+	// The elements declared more than once, like the extends of the same message, are merged into one.
+	for _, c := range n.children {
+		if c.key == child.key && c.element == child.element {
+			c.children = append(c.children, child.children...)
+			return
+		}
+	}
+	n.children = append(n.children, child)
+}
+
+func commentProps(comments []*parser.Comment, inline *parser.Comment) []property {
+	var raws []string
+	for _, c := range comments {
+		raws = append(raws, c.Raw)
+	}
+	props := []property{{name: "comments", value: strings.Join(raws, "\n")}}
+	if inline != nil {
+		props = append(props, property{name: "inline comment", value: inline.Raw})
+	} else {
+		props = append(props, property{name: "inline comment"})
+	}
+	return props
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// newFileNode builds the tree of the proto.
+func newFileNode(proto *parser.Proto) *node {
+	root := &node{element: ElementFile}
+	if proto.Syntax != nil {
+		root.addChild(&node{
+			element: ElementSyntax,
+			key:     "syntax",
+			path:    "syntax",
+			pos:     proto.Syntax.Meta.Pos,
+			props: append([]property{{name: "value", value: proto.Syntax.ProtobufVersion}},
+				commentProps(proto.Syntax.Comments, proto.Syntax.InlineComment)...),
+		})
+	}
+	if proto.Edition != nil {
+		root.addChild(&node{
+			element: ElementEdition,
+			key:     "edition",
+			path:    "edition",
+			pos:     proto.Edition.Meta.Pos,
+			props: append([]property{{name: "value", value: proto.Edition.Edition}},
+				commentProps(proto.Edition.Comments, proto.Edition.InlineComment)...),
+		})
+	}
+
+	scope := ""
+	for _, element := range proto.ProtoBody {
+		if p, ok := element.(*parser.Package); ok {
+			scope = p.Name
+		}
+	}
+	for _, element := range proto.ProtoBody {
+		switch e := element.(type) {
+		case *parser.Package:
+			root.addChild(&node{
+				element: ElementPackage,
+				key:     "package",
+				path:    "package",
+				pos:     e.Meta.Pos,
+				props:   append([]property{{name: "name", value: e.Name}}, commentProps(e.Comments, e.InlineComment)...),
+			})
+		case *parser.Import:
+			location := strings.Trim(e.Location, `"'`)
+			modifier := ""
+			switch e.Modifier {
+			case parser.ImportModifierPublic:
+				modifier = "public"
+			case parser.ImportModifierWeak:
+				modifier = "weak"
+			}
+			root.addChild(&node{
+				element: ElementImport,
+				key:     location,
+				path:    location,
+				pos:     e.Meta.Pos,
+				props:   append([]property{{name: "modifier", value: modifier}}, commentProps(e.Comments, e.InlineComment)...),
+			})
+		default:
+			if child := newNode(scope, "", element); child != nil {
+				root.addChild(child)
+			}
+		}
+	}
+	return root
+}
+
+// newNode builds the node of the element declared in the scope, which is the fully qualified name of
+// the enclosing message or the package. parent is the path of the parent node.
+// It returns nil if the element is not compared, like an empty statement.
+func newNode(scope string, parent string, element parser.Visitee) *node {
+	switch e := element.(type) {
+	case *parser.Option:
+		return optionNode(parent, e.OptionName, e.Constant, e.Meta.Pos, commentProps(e.Comments, e.InlineComment))
+	case *parser.Message:
+		fullName := join(scope, e.MessageName)
+		n := &node{
+			element: ElementMessage,
+			key:     e.MessageName,
+			path:    fullName,
+			pos:     e.Meta.Pos,
+			props:   commentProps(e.Comments, e.InlineComment),
+		}
+		addChildren(n, fullName, e.MessageBody)
+		return n
+	case *parser.Field:
+		label := ""
+		switch {
+		case e.IsRepeated:
+			label = "repeated"
+		case e.IsRequired:
+			label = "required"
+		case e.IsOptional:
+			label = "optional"
+		}
+		n := fieldNode(parent, e.FieldNumber, e.Meta.Pos, []property{
+			{name: "name", value: e.FieldName},
+			{name: "label", value: label},
+			{name: "type", value: e.Type},
+		})
+		n.props = append(n.props, commentProps(e.Comments, e.InlineComment)...)
+		addFieldOptions(n, e.FieldOptions)
+		return n
+	case *parser.MapField:
+		n := fieldNode(parent, e.FieldNumber, e.Meta.Pos, []property{
+			{name: "name", value: e.MapName},
+			{name: "label"},
+			{name: "type", value: "map<" + e.KeyType + ", " + e.Type + ">"},
+		})
+		n.props = append(n.props, commentProps(e.Comments, e.InlineComment)...)
+		addFieldOptions(n, e.FieldOptions)
+		return n
+	case *parser.GroupField:
+		label := ""
+		switch {
+		case e.IsRepeated:
+			label = "repeated"
+		case e.IsRequired:
+			label = "required"
+		case e.IsOptional:
+			label = "optional"
+		}
+		n := fieldNode(parent, e.FieldNumber, e.Meta.Pos, []property{
+			{name: "name", value: e.GroupName},
+			{name: "label", value: label},
+			{name: "type", value: "group"},
+		})
+		n.props = append(n.props, commentProps(e.Comments, e.InlineComment)...)
+		addChildren(n, join(scope, e.GroupName), e.MessageBody)
+		return n
+	case *parser.Oneof:
+		n := &node{
+			element: ElementOneof,
+			key:     e.OneofName,
+			path:    join(parent, e.OneofName),
+			pos:     e.Meta.Pos,
+			props:   commentProps(e.Comments, e.InlineComment),
+		}
+		for _, option := range e.Options {
+			n.addChild(newNode(scope, n.path, option))
+		}
+		for _, field := range e.OneofFields {
+			child := fieldNode(parent, field.FieldNumber, field.Meta.Pos, []property{
+				{name: "name", value: field.FieldName},
+				{name: "label"},
+				{name: "type", value: field.Type},
+			})
+			child.props = append(child.props, commentProps(field.Comments, field.InlineComment)...)
+			addFieldOptions(child, field.FieldOptions)
+			n.addChild(child)
+		}
+		return n
+	case *parser.Reserved:
+		var values []string
+		for _, r := range e.Ranges {
+			values = append(values, rangeText(r))
+		}
+		values = append(values, e.FieldNames...)
+		return &node{
+			element: ElementReserved,
+			key:     strings.Join(values, ", "),
+			path:    parent,
+			pos:     e.Meta.Pos,
+			props:   commentProps(e.Comments, e.InlineComment),
+		}
+	case *parser.Extensions:
+		var values []string
+		for _, r := range e.Ranges {
+			values = append(values, rangeText(r))
+		}
+		return &node{
+			element: ElementExtensions,
+			key:     strings.Join(values, ", "),
+			path:    parent,
+			pos:     e.Meta.Pos,
+			props:   commentProps(e.Comments, e.InlineComment),
+		}
+	case *parser.Extend:
+		n := &node{
+			element: ElementExtend,
+			key:     e.MessageType,
+			path:    join(parent, e.MessageType),
+			pos:     e.Meta.Pos,
+			props:   commentProps(e.Comments, e.InlineComment),
+		}
+		addChildren(n, scope, e.ExtendBody)
+		return n
+	case *parser.Enum:
+		fullName := join(scope, e.EnumName)
+		n := &node{
+			element: ElementEnum,
+			key:     e.EnumName,
+			path:    fullName,
+			pos:     e.Meta.Pos,
+			props:   commentProps(e.Comments, e.InlineComment),
+		}
+		addChildren(n, fullName, e.EnumBody)
+		return n
+	case *parser.EnumField:
+		n := &node{
+			element: ElementEnumValue,
+			key:     e.Ident,
+			path:    join(parent, e.Ident),
+			pos:     e.Meta.Pos,
+			props:   append([]property{{name: "number", value: e.Number}}, commentProps(e.Comments, e.InlineComment)...),
+		}
+		for _, option := range e.EnumValueOptions {
+			n.addChild(optionNode(n.path, option.OptionName, option.Constant, e.Meta.Pos, nil))
+		}
+		return n
+	case *parser.Service:
+		fullName := join(scope, e.ServiceName)
+		n := &node{
+			element: ElementService,
+			key:     e.ServiceName,
+			path:    fullName,
+			pos:     e.Meta.Pos,
+			props:   commentProps(e.Comments, e.InlineComment),
+		}
+		addChildren(n, fullName, e.ServiceBody)
+		return n
+	case *parser.RPC:
+		n := &node{
+			element: ElementRPC,
+			key:     e.RPCName,
+			path:    join(parent, e.RPCName),
+			pos:     e.Meta.Pos,
+			props: append([]property{
+				{name: "request", value: streamType(e.RPCRequest.IsStream, e.RPCRequest.MessageType)},
+				{name: "response", value: streamType(e.RPCResponse.IsStream, e.RPCResponse.MessageType)},
+			}, commentProps(e.Comments, e.InlineComment)...),
+		}
+		for _, option := range e.Options {
+			n.addChild(newNode(scope, n.path, option))
+		}
+		return n
+	}
+	return nil
+}
+
+func addChildren(n *node, scope string, body []parser.Visitee) {
+	for _, element := range body {
+		if child := newNode(scope, n.path, element); child != nil {
+			n.addChild(child)
+		}
+	}
+}
+
+func fieldNode(parent string, number string, pos meta.Position, props []property) *node {
+	return &node{
+		element: ElementField,
+		key:     number,
+		path:    parent + "[" + number + "]",
+		pos:     pos,
+		props:   props,
+	}
+}
+
+func optionNode(parent string, name string, constant string, pos meta.Position, comments []property) *node {
+	path := name
+	if parent != "" {
+		path = parent + " " + name
+	}
+	return &node{
+		element: ElementOption,
+		key:     name,
+		path:    path,
+		pos:     pos,
+		props:   append([]property{{name: "value", value: constant}}, comments...),
+	}
+}
+
+func addFieldOptions(n *node, options []*parser.FieldOption) {
+	for _, option := range options {
+		n.addChild(optionNode(n.path, option.OptionName, option.Constant, n.pos, nil))
+	}
+}
+
+func rangeText(r *parser.Range) string {
+	if r.End == "" {
+		return r.Begin
+	}
+	return r.Begin + " to " + r.End
+}
+
+func streamType(isStream bool, messageType string) string {
+	if isStream {
+		return "stream " + messageType
+	}
+	return messageType
+}
@@ -0,0 +1,168 @@
+// Package formatter formats Protocol Buffer files in a consistent style.
+package formatter
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/printer"
+)
+
+const defaultIndentWidth = 2
+
+// Formatter formats Protocol Buffer files.
+//
+// It normalizes the indentation, puts each leading comment on its own line above the element
+// and each inline comment at the end of the line, and keeps at most one blank line between elements.
+type Formatter struct {
+	indentWidth   int
+	alignment     bool
+	maxLineLength int
+	sortImports   bool
+}
+
+// Option is an option for NewFormatter.
+type Option func(*Formatter)
+
+// WithIndentWidth is an option to set the number of spaces used for one level of indentation.
+// The default is 2.
+func WithIndentWidth(width int) Option {
+	return func(f *Formatter) {
+		f.indentWidth = width
+	}
+}
+
+// WithAlignment is an option to align the field numbers, the options and the inline comments
+// of consecutive fields and enum values. The default is true.
+func WithAlignment(alignment bool) Option {
+	return func(f *Formatter) {
+		f.alignment = alignment
+	}
+}
+
+// WithMaxLineLength is an option to wrap the options of fields and enum values
+// onto separate lines when the line would be longer than the given length.
+// The default is 0, which means no limit.
+func WithMaxLineLength(length int) Option {
+	return func(f *Formatter) {
+		f.maxLineLength = length
+	}
+}
+
+// WithSortImports is an option to sort the consecutive import statements by their paths.
+// The default is true.
+func WithSortImports(sortImports bool) Option {
+	return func(f *Formatter) {
+		f.sortImports = sortImports
+	}
+}
+
+// NewFormatter creates a new Formatter.
+func NewFormatter(opts ...Option) *Formatter {
+	f := &Formatter{
+		indentWidth: defaultIndentWidth,
+		alignment:   true,
+		sortImports: true,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Format writes the formatted proto to w.
+// The proto should be parsed with the option to include comments in the body,
+// otherwise the comments not attached to any element are lost.
+// It should also be parsed with the option value option to lay out the message literals of the options,
+// and with the trivia option to keep the adjacent string literals apart.
+// The proto itself is not modified.
+func (f *Formatter) Format(w io.Writer, proto *parser.Proto) error {
+	if f.sortImports {
+		proto = sortImports(proto)
+	}
+	return printer.NewPrinter(
+		printer.WithIndent(strings.Repeat(" ", f.indentWidth)),
+		printer.WithAlignment(f.alignment),
+		printer.WithMaxLineLength(f.maxLineLength),
+		printer.WithBlankLines(true),
+		printer.WithIgnoreTrivia(true),
+	).Fprint(w, proto)
+}
+
+// Source parses the source of a .proto file and returns the formatted one.
+func (f *Formatter) Source(src []byte) ([]byte, error) {
+	p := parser.NewParser(
+		lexer.NewLexer(bytes.NewReader(src)),
+		parser.WithPermissive(true),
+		parser.WithBodyIncludingComments(true),
+		parser.WithTrivia(true),
+		parser.WithOptionValue(true),
+	)
+	proto, err := p.ParseProto()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := f.Format(&buf, proto); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Source formats the source of a .proto file with the given options.
+func Source(src []byte, opts ...Option) ([]byte, error) {
+	return NewFormatter(opts...).Source(src)
+}
+
+// sortImports returns a shallow copy of the proto whose consecutive imports are sorted by their paths.
+// Imports separated by a blank line or another element are sorted independently.
+func sortImports(proto *parser.Proto) *parser.Proto {
+	sorted := *proto
+	sorted.ProtoBody = append([]parser.Visitee(nil), proto.ProtoBody...)
+
+	body := sorted.ProtoBody
+	for i := 0; i < len(body); {
+		j := i
+		for j < len(body) && isImport(body[j]) && (j == i || adjacent(body[j-1].(*parser.Import), body[j].(*parser.Import))) {
+			j++
+		}
+		if i == j {
+			i++
+			continue
+		}
+
+		group := body[i:j]
+		sort.SliceStable(group, func(a, b int) bool {
+			return importPath(group[a].(*parser.Import)) < importPath(group[b].(*parser.Import))
+		})
+		i = j
+	}
+	return &sorted
+}
+
+func isImport(v parser.Visitee) bool {
+	_, ok := v.(*parser.Import)
+	return ok
+}
+
+// adjacent reports whether next starts right after the line where prev ends.
+func adjacent(prev, next *parser.Import) bool {
+	last := prev.Meta.LastPos.Line
+	if prev.InlineComment != nil {
+		last = prev.InlineComment.Meta.LastPos.Line
+	}
+	first := next.Meta.Pos.Line
+	if 0 < len(next.Comments) {
+		first = next.Comments[0].Meta.Pos.Line
+	}
+	return last == 0 || first == 0 || first <= last+1
+}
+
+func importPath(i *parser.Import) string {
+	return strings.Trim(i.Location, `"'`)
+}
@@ -0,0 +1,324 @@
+package formatter_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/formatter"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestFormatter_Source(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		inputOptions []formatter.Option
+		wantOutput   string
+		wantErr      bool
+	}{
+		{
+			name: "formatting with the default options",
+			input: `syntax = "proto3";
+package foo;
+
+
+import "z.proto";
+// about a
+import "a.proto";
+
+import "m.proto";
+  // A is a message.
+message A {
+
+        // id.
+    int32 id = 1; // id
+    repeated string long_name = 22 [deprecated = true];   // names
+
+
+    map<string, int32> m = 3;
+    enum E { E_UNSPECIFIED = 0; E_A = 1 [(x) = true]; }
+    oneof o { string s = 4; int64 longer_i = 5; }
+    // trailing
+
+}
+`,
+			wantOutput: `syntax = "proto3";
+package foo;
+
+// about a
+import "a.proto";
+import "z.proto";
+
+import "m.proto";
+// A is a message.
+message A {
+  // id.
+  int32 id                  = 1;                      // id
+  repeated string long_name = 22 [deprecated = true]; // names
+
+  map<string, int32> m = 3;
+  enum E {
+    E_UNSPECIFIED = 0;
+    E_A           = 1 [(x) = true];
+  }
+  oneof o {
+    string s       = 4;
+    int64 longer_i = 5;
+  }
+  // trailing
+}
+`,
+		},
+		{
+			name: "formatting with the indent width option",
+			input: `message A { message B { int32 a = 1; } }
+`,
+			inputOptions: []formatter.Option{
+				formatter.WithIndentWidth(4),
+			},
+			wantOutput: `message A {
+    message B {
+        int32 a = 1;
+    }
+}
+`,
+		},
+		{
+			name: "formatting without the alignment",
+			input: `message A {
+  int32 id = 1; // id
+  string name = 2; // name
+}
+`,
+			inputOptions: []formatter.Option{
+				formatter.WithAlignment(false),
+			},
+			wantOutput: `message A {
+  int32 id = 1; // id
+  string name = 2; // name
+}
+`,
+		},
+		{
+			name: "formatting with the max line length option",
+			input: `message A {
+  int32 id = 1;
+  string name = 2 [deprecated = true, (validate.rules).string.min_len = 1];
+}
+`,
+			inputOptions: []formatter.Option{
+				formatter.WithMaxLineLength(60),
+			},
+			wantOutput: `message A {
+  int32 id = 1;
+  string name = 2 [
+    deprecated = true,
+    (validate.rules).string.min_len = 1
+  ];
+}
+`,
+		},
+		{
+			name: "formatting without sorting imports",
+			input: `import "b.proto";
+import "a.proto";
+`,
+			inputOptions: []formatter.Option{
+				formatter.WithSortImports(false),
+			},
+			wantOutput: `import "b.proto";
+import "a.proto";
+`,
+		},
+		{
+			name: "formatting the message literals of the options",
+			input: `service S {
+  rpc Ready (Empty) returns (Empty) {
+    option (google.api.http) = { post: "/ready" body: "*" };
+  }
+}
+message A {
+  int32 f = 1 [(my.opt) = {a:[1,2] b:"x"}];
+  int32 g = 2 [(my.list) = [{a: 1}, {b: 2}], deprecated = true];
+  option (o) = { inner { x: -inf } list: [] empty {} };
+}
+`,
+			wantOutput: `service S {
+  rpc Ready (Empty) returns (Empty) {
+    option (google.api.http) = {
+      post: "/ready"
+      body: "*"
+    };
+  }
+}
+message A {
+  int32 f = 1 [(my.opt) = {
+    a: [1, 2]
+    b: "x"
+  }];
+  int32 g = 2 [(my.list) = [
+    {
+      a: 1
+    },
+    {
+      b: 2
+    }
+  ], deprecated = true];
+  option (o) = {
+    inner: {
+      x: -inf
+    }
+    list: []
+    empty: {}
+  };
+}
+`,
+		},
+		{
+			name: "formatting the adjacent string literals",
+			input: `message A {
+  string s = 1 [(d) = "a" "b"];
+  string t = 2 [(d) = "a"
+      "b"];
+  option (o) = { s: 'a' "b" };
+}
+`,
+			wantOutput: `message A {
+  string s = 1 [(d) = "a" "b"];
+  string t = 2 [(d) = "a"
+    "b"];
+  option (o) = {
+    s: 'a' "b"
+  };
+}
+`,
+		},
+		{
+			name:    "formatting an invalid source",
+			input:   `message A {`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got, err := formatter.Source([]byte(test.input), test.inputOptions...)
+			switch {
+			case test.wantErr:
+				if err == nil {
+					t.Errorf("got err nil, but want err")
+				}
+				return
+			case err != nil:
+				t.Errorf("got err %v, but want nil", err)
+				return
+			}
+
+			if string(got) != test.wantOutput {
+				t.Errorf("got %s, but want %s", got, test.wantOutput)
+			}
+		})
+	}
+}
+
+func TestFormatter_Format(t *testing.T) {
+	input := `import "b.proto";
+import "a.proto";
+`
+	proto, err := protoparser.Parse(strings.NewReader(input), protoparser.WithTrivia(true))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	var got bytes.Buffer
+	if err := formatter.NewFormatter().Format(&got, proto); err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	want := `import "a.proto";
+import "b.proto";
+`
+	if got.String() != want {
+		t.Errorf("got %s, but want %s", got.String(), want)
+	}
+	if len(proto.ProtoBody) != 2 || proto.ProtoBody[0].(*parser.Import).Location != `"b.proto"` {
+		t.Errorf("got the modified proto, but want the original one")
+	}
+}
+
+func TestFormatter_Source_idempotent(t *testing.T) {
+	files, err := filepath.Glob("../_testdata/*.proto")
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			formatted, err := formatter.Source(content, formatter.WithMaxLineLength(80))
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			got, err := formatter.Source(formatted, formatter.WithMaxLineLength(80))
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if !bytes.Equal(got, formatted) {
+				t.Errorf("got %s, but want %s", got, formatted)
+			}
+		})
+	}
+}
+
+func TestFormatter_Source_golden(t *testing.T) {
+	for _, name := range []string{
+		"cloudEndpoints.proto",
+		"grpc-gateway_a_bit_of_everything.proto",
+	} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("..", "_testdata", name))
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			got, err := formatter.Source(content)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatalf("got err %v, but want nil", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %s, but want %s", got, want)
+			}
+
+			again, err := formatter.Source(got)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if !bytes.Equal(again, got) {
+				t.Errorf("got %s, but want %s", again, got)
+			}
+		})
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/yoheimuta/go-protoparser/v4
 
-go 1.13
+go 1.21
+
+require google.golang.org/protobuf v1.36.1
@@ -0,0 +1,220 @@
+// Package descriptor interprets parsed Protocol Buffer files into descriptors,
+// that is, google.protobuf.FileDescriptorProto and google.protobuf.FileDescriptorSet.
+package descriptor
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// Option is an option for InterpretFile and InterpretFileSet.
+type Option func(*config)
+
+type config struct {
+	sourceCodeInfo bool
+}
+
+// WithSourceCodeInfo is an option to include SourceCodeInfo, which holds the locations and the comments
+// of the elements, like protoc --include_source_info.
+func WithSourceCodeInfo(sourceCodeInfo bool) Option {
+	return func(c *config) {
+		c.sourceCodeInfo = sourceCodeInfo
+	}
+}
+
+// InterpretFileSet links the files and interprets all of them.
+// The files are ordered so that every file comes after the files it imports.
+func InterpretFileSet(set *resolver.FileSet, opts ...Option) (*descriptorpb.FileDescriptorSet, error) {
+	linked, err := linker.Link(set)
+	if err != nil {
+		return nil, err
+	}
+
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	for _, file := range set.Sorted() {
+		fd, err := InterpretFile(file, linked, opts...)
+		if err != nil {
+			return nil, err
+		}
+		fileSet.File = append(fileSet.File, fd)
+	}
+	return fileSet, nil
+}
+
+// InterpretFile interprets the file into a FileDescriptorProto.
+// linked must be the result of linking the set of files including it.
+//
+// Options whose names are extensions, like (foo.bar), are stored as uninterpreted_option
+// because they need the descriptors of the extensions to be interpreted.
+func InterpretFile(
+	file *resolver.File,
+	linked *linker.Result,
+	opts ...Option,
+) (*descriptorpb.FileDescriptorProto, error) {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	i := &interpreter{
+		config: c,
+		file:   file,
+		linked: linked,
+		pkg:    linker.PackageName(file.Proto),
+		source: &descriptorpb.SourceCodeInfo{},
+	}
+	fd, err := i.interpretFile(file.Proto)
+	if err != nil {
+		return nil, err
+	}
+	if c.sourceCodeInfo {
+		fd.SourceCodeInfo = i.source
+	}
+	return fd, nil
+}
+
+type interpreter struct {
+	*config
+
+	file   *resolver.File
+	linked *linker.Result
+	pkg    string
+	source *descriptorpb.SourceCodeInfo
+	// editions is true if the file uses editions.
+	editions bool
+	// proto3 is true if the file uses proto3.
+	proto3 bool
+}
+
+func (i *interpreter) interpretFile(src *parser.Proto) (*descriptorpb.FileDescriptorProto, error) {
+	fd := &descriptorpb.FileDescriptorProto{
+		Name: proto.String(i.file.Path),
+	}
+	i.addFileLocation(src)
+
+	switch {
+	case src.Edition != nil:
+		edition, ok := descriptorpb.Edition_value["EDITION_"+src.Edition.Edition]
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid edition %q", src.Edition.Meta.Pos, src.Edition.Edition)
+		}
+		i.editions = true
+		fd.Syntax = proto.String("editions")
+		fd.Edition = descriptorpb.Edition(edition).Enum()
+		i.addLocation(path(fileEditionTag), src.Edition.Meta, src.Edition.Comments, src.Edition.InlineComment)
+	case src.Syntax != nil:
+		if src.Syntax.ProtobufVersion == "proto3" {
+			i.proto3 = true
+			fd.Syntax = proto.String("proto3")
+		}
+		i.addLocation(path(fileSyntaxTag), src.Syntax.Meta, src.Syntax.Comments, src.Syntax.InlineComment)
+	}
+
+	for _, body := range src.ProtoBody {
+		var err error
+		switch e := body.(type) {
+		case *parser.Package:
+			fd.Package = proto.String(e.Name)
+			i.addLocation(path(filePackageTag), e.Meta, e.Comments, e.InlineComment)
+		case *parser.Import:
+			index := int32(len(fd.Dependency))
+			switch e.Modifier {
+			case parser.ImportModifierPublic:
+				fd.PublicDependency = append(fd.PublicDependency, index)
+			case parser.ImportModifierWeak:
+				fd.WeakDependency = append(fd.WeakDependency, index)
+			}
+			fd.Dependency = append(fd.Dependency, resolver.ImportPath(e))
+			i.addLocation(path(fileDependencyTag, index), e.Meta, e.Comments, e.InlineComment)
+		case *parser.Option:
+			if fd.Options == nil {
+				fd.Options = &descriptorpb.FileOptions{}
+			}
+			err = i.setOption(fd.Options, e.OptionName, e.Constant, e.Meta.Pos)
+			i.addLocation(path(fileOptionsTag), e.Meta, e.Comments, e.InlineComment)
+		case *parser.Message:
+			var m *descriptorpb.DescriptorProto
+			m, err = i.interpretMessage(e, i.pkg, path(fileMessageTypeTag, int32(len(fd.MessageType))))
+			fd.MessageType = append(fd.MessageType, m)
+		case *parser.Enum:
+			var en *descriptorpb.EnumDescriptorProto
+			en, err = i.interpretEnum(e, path(fileEnumTypeTag, int32(len(fd.EnumType))))
+			fd.EnumType = append(fd.EnumType, en)
+		case *parser.Service:
+			var s *descriptorpb.ServiceDescriptorProto
+			s, err = i.interpretService(e, path(fileServiceTag, int32(len(fd.Service))))
+			fd.Service = append(fd.Service, s)
+		case *parser.Extend:
+			scope := &scope{
+				name:          i.pkg,
+				extensions:    &fd.Extension,
+				extensionsTag: fileExtensionTag,
+				nestedTypes:   &fd.MessageType,
+				nestedTypeTag: fileMessageTypeTag,
+			}
+			err = i.interpretExtend(e, scope)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fd, nil
+}
+
+// Field numbers of the descriptors used as the paths of SourceCodeInfo.
+const (
+	fileMessageTypeTag = 4
+	filePackageTag     = 2
+	fileDependencyTag  = 3
+	fileEnumTypeTag    = 5
+	fileServiceTag     = 6
+	fileExtensionTag   = 7
+	fileOptionsTag     = 8
+	fileSyntaxTag      = 12
+	fileEditionTag     = 14
+
+	messageFieldTag          = 2
+	messageNestedTypeTag     = 3
+	messageEnumTypeTag       = 4
+	messageExtensionRangeTag = 5
+	messageExtensionTag      = 6
+	messageOptionsTag        = 7
+	messageOneofDeclTag      = 8
+	messageReservedRangeTag  = 9
+	messageReservedNameTag   = 10
+
+	enumValueTag         = 2
+	enumOptionsTag       = 3
+	enumReservedRangeTag = 4
+	enumReservedNameTag  = 5
+
+	serviceMethodTag  = 2
+	serviceOptionsTag = 3
+)
+
+func path(elements ...int32) []int32 {
+	return elements
+}
+
+func appendPath(p []int32, elements ...int32) []int32 {
+	return append(append([]int32(nil), p...), elements...)
+}
+
+// typeName returns the type name of the resolved reference of the node, with the leading dot.
+func (i *interpreter) typeName(node interface{}, pos meta.Position) (string, *linker.Symbol, error) {
+	ref := i.linked.ReferenceOf(node)
+	if ref == nil {
+		return "", nil, nil
+	}
+	if ref.Symbol == nil {
+		return "", nil, fmt.Errorf("%s: %v %q", pos, linker.ErrUnresolved, ref.Name)
+	}
+	return "." + ref.Symbol.FullName, ref.Symbol, nil
+}
@@ -0,0 +1,364 @@
+package descriptor_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/interpret/descriptor"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+func resolve(t *testing.T, files map[string]string) *resolver.FileSet {
+	set, err := resolver.Resolve(
+		[]string{"a.proto"},
+		resolver.WithFiles(files),
+	)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	return set
+}
+
+func TestInterpretFileSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		inputFiles map[string]string
+		// wantFile is the last file in the set in the text format.
+		wantFile string
+	}{
+		{
+			name: "interpreting proto3 elements",
+			inputFiles: map[string]string{
+				"a.proto": `
+syntax = "proto3";
+package foo;
+import public "b.proto";
+option java_package = "com.foo";
+message A {
+  string name = 1 [json_name = "NAME", deprecated = true];
+  optional int32 opt = 2;
+  map<string, B> map_field = 3;
+  oneof choice {
+    option (o) = true;
+    int64 i = 4;
+    Kind k = 5;
+  }
+  enum Kind {
+    KIND_UNSPECIFIED = 0;
+    reserved 10 to max;
+    reserved "OLD";
+  }
+  repeated int32 ints = 6 [packed = true];
+  reserved 100 to 200, 300;
+  reserved "old";
+}
+service S {
+  rpc Get (stream A) returns (stream B) {
+    option deprecated = true;
+  }
+}
+`,
+				"b.proto": `
+syntax = "proto3";
+package foo;
+message B {}
+`,
+			},
+			wantFile: `
+name: "a.proto"
+package: "foo"
+dependency: "b.proto"
+public_dependency: 0
+message_type: {
+  name: "A"
+  field: { name: "name" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "NAME" options: { deprecated: true } }
+  field: { name: "opt" number: 2 label: LABEL_OPTIONAL type: TYPE_INT32 oneof_index: 1 json_name: "opt" proto3_optional: true }
+  field: { name: "map_field" number: 3 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".foo.A.MapFieldEntry" json_name: "mapField" }
+  field: { name: "i" number: 4 label: LABEL_OPTIONAL type: TYPE_INT64 oneof_index: 0 json_name: "i" }
+  field: { name: "k" number: 5 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".foo.A.Kind" oneof_index: 0 json_name: "k" }
+  field: { name: "ints" number: 6 label: LABEL_REPEATED type: TYPE_INT32 json_name: "ints" options: { packed: true } }
+  nested_type: {
+    name: "MapFieldEntry"
+    field: { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "key" }
+    field: { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".foo.B" json_name: "value" }
+    options: { map_entry: true }
+  }
+  enum_type: {
+    name: "Kind"
+    value: { name: "KIND_UNSPECIFIED" number: 0 }
+    reserved_range: { start: 10 end: 2147483647 }
+    reserved_name: "OLD"
+  }
+  oneof_decl: {
+    name: "choice"
+    options: { uninterpreted_option: { name: { name_part: "o" is_extension: true } identifier_value: "true" } }
+  }
+  oneof_decl: { name: "_opt" }
+  reserved_range: { start: 100 end: 201 }
+  reserved_range: { start: 300 end: 301 }
+  reserved_name: "old"
+}
+service: {
+  name: "S"
+  method: {
+    name: "Get"
+    input_type: ".foo.A"
+    output_type: ".foo.B"
+    options: { deprecated: true }
+    client_streaming: true
+    server_streaming: true
+  }
+}
+options: { java_package: "com.foo" }
+syntax: "proto3"
+`,
+		},
+		{
+			name: "interpreting proto2 elements",
+			inputFiles: map[string]string{
+				"a.proto": `
+syntax = "proto2";
+message A {
+  required int32 r = 1;
+  optional string s = 2 [default = "a\n\x41"];
+  optional bytes b = 3 [default = "\001'"];
+  optional double d = 4 [default = -inf];
+  optional E e = 5 [default = E_B];
+  repeated group G = 6 {
+    optional int32 x = 1;
+  }
+  optional float n = 7 [default = -nan];
+  extensions 100 to 199, 1000 to max;
+  extend A {
+    optional int32 nested_ext = 100;
+  }
+}
+enum E {
+  E_A = 0;
+  E_B = -1;
+}
+extend A {
+  optional E ext = 101;
+}
+`,
+			},
+			wantFile: `
+name: "a.proto"
+message_type: {
+  name: "A"
+  field: { name: "r" number: 1 label: LABEL_REQUIRED type: TYPE_INT32 json_name: "r" }
+  field: { name: "s" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING default_value: "a\nA" json_name: "s" }
+  field: { name: "b" number: 3 label: LABEL_OPTIONAL type: TYPE_BYTES default_value: "\\001\\'" json_name: "b" }
+  field: { name: "d" number: 4 label: LABEL_OPTIONAL type: TYPE_DOUBLE default_value: "-inf" json_name: "d" }
+  field: { name: "e" number: 5 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".E" default_value: "E_B" json_name: "e" }
+  field: { name: "g" number: 6 label: LABEL_REPEATED type: TYPE_GROUP type_name: ".A.G" json_name: "g" }
+  field: { name: "n" number: 7 label: LABEL_OPTIONAL type: TYPE_FLOAT default_value: "nan" json_name: "n" }
+  nested_type: {
+    name: "G"
+    field: { name: "x" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "x" }
+  }
+  extension_range: { start: 100 end: 200 }
+  extension_range: { start: 1000 end: 536870912 }
+  extension: { name: "nested_ext" number: 100 label: LABEL_OPTIONAL type: TYPE_INT32 extendee: ".A" json_name: "nestedExt" }
+}
+enum_type: {
+  name: "E"
+  value: { name: "E_A" number: 0 }
+  value: { name: "E_B" number: -1 }
+}
+extension: { name: "ext" number: 101 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".E" extendee: ".A" json_name: "ext" }
+`,
+		},
+		{
+			name: "interpreting editions elements",
+			inputFiles: map[string]string{
+				"a.proto": `
+edition = "2023";
+option features.field_presence = IMPLICIT;
+message A {
+  int32 a = 1 [features.field_presence = EXPLICIT];
+  repeated int32 b = 2 [features = { repeated_field_encoding: EXPANDED }];
+  extensions 100 to 199 [
+    declaration = {
+      number: 100,
+      full_name: ".a",
+      type: "int32"
+    }
+  ];
+  reserved b_old;
+}
+`,
+			},
+			wantFile: `
+name: "a.proto"
+message_type: {
+  name: "A"
+  field: { name: "a" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "a" options: { features: { field_presence: EXPLICIT } } }
+  field: { name: "b" number: 2 label: LABEL_REPEATED type: TYPE_INT32 json_name: "b" options: { features: { repeated_field_encoding: EXPANDED } } }
+  extension_range: {
+    start: 100
+    end: 200
+    options: { declaration: { number: 100 full_name: ".a" type: "int32" } }
+  }
+  reserved_name: "b_old"
+}
+options: { features: { field_presence: IMPLICIT } }
+syntax: "editions"
+edition: EDITION_2023
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got, err := descriptor.InterpretFileSet(resolve(t, test.inputFiles))
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if _, err := protodesc.NewFiles(got); err != nil {
+				t.Errorf("got an invalid set, err %v", err)
+			}
+
+			want := &descriptorpb.FileDescriptorProto{}
+			if err := prototext.Unmarshal([]byte(test.wantFile), want); err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			gotFile := got.File[len(got.File)-1]
+			if !proto.Equal(gotFile, want) {
+				t.Errorf("got %v, but want %v", prototext.Format(gotFile), prototext.Format(want))
+			}
+		})
+	}
+}
+
+func TestInterpretFileSet_sourceCodeInfo(t *testing.T) {
+	set := resolve(t, map[string]string{
+		"a.proto": `syntax = "proto3";
+
+// detached
+
+// A is a message.
+message A {
+  /*
+   * a is a field.
+   */
+  int32 a = 1; // trailing
+}
+`,
+	})
+	got, err := descriptor.InterpretFileSet(set, descriptor.WithSourceCodeInfo(true))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	want := &descriptorpb.SourceCodeInfo{}
+	err = prototext.Unmarshal([]byte(`
+location: { path: [] span: [0, 0, 10, 1] }
+location: { path: [12] span: [0, 0, 18] }
+location: {
+  path: [4, 0]
+  span: [5, 0, 10, 1]
+  leading_comments: " A is a message.\n"
+  leading_detached_comments: " detached\n"
+}
+location: {
+  path: [4, 0, 2, 0]
+  span: [9, 2, 14]
+  leading_comments: "\n a is a field.\n"
+  trailing_comments: " trailing\n"
+}
+`), want)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if !proto.Equal(got.File[0].SourceCodeInfo, want) {
+		t.Errorf("got %v, but want %v", prototext.Format(got.File[0].SourceCodeInfo), prototext.Format(want))
+	}
+
+	got, err = descriptor.InterpretFileSet(set)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if got.File[0].SourceCodeInfo != nil {
+		t.Errorf("got %v, but want nil", got.File[0].SourceCodeInfo)
+	}
+}
+
+func TestInterpretFileSet_extensionRangeOptions(t *testing.T) {
+	got, err := descriptor.InterpretFileSet(resolve(t, map[string]string{
+		"a.proto": `syntax = "proto2";
+message A {
+  extensions 100, 200 to 299 [declaration = { number: 100, full_name: ".a", type: "int32" }];
+}
+`,
+	}))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	ranges := got.File[0].MessageType[0].ExtensionRange
+	if len(ranges) != 2 {
+		t.Fatalf("got %v, but want 2 ranges", ranges)
+	}
+	if !proto.Equal(ranges[0].Options, ranges[1].Options) {
+		t.Errorf("got %v, but want %v", ranges[1].Options, ranges[0].Options)
+	}
+	ranges[0].Options.Declaration[0].Number = proto.Int32(101)
+	if ranges[1].Options.Declaration[0].GetNumber() != 100 {
+		t.Errorf("got %v, but want the options of each range not to share the declarations", ranges[1].Options)
+	}
+}
+
+func TestInterpretFileSet_error(t *testing.T) {
+	tests := []struct {
+		name       string
+		inputFiles map[string]string
+		wantErr    string
+	}{
+		{
+			name: "reporting an unresolved type",
+			inputFiles: map[string]string{
+				"a.proto": `message A { Unknown u = 1; }`,
+			},
+			wantErr: `a.proto:1:13: unresolved reference "Unknown"`,
+		},
+		{
+			name: "reporting an unknown option",
+			inputFiles: map[string]string{
+				"a.proto": `message A {
+  option unknown = true;
+}`,
+			},
+			wantErr: `a.proto:2:3: unknown option "unknown"`,
+		},
+		{
+			name: "reporting an invalid option value",
+			inputFiles: map[string]string{
+				"a.proto": `option optimize_for = FAST;`,
+			},
+			wantErr: `a.proto:1:1: invalid value FAST of option "optimize_for", err unknown value of google.protobuf.FileOptions.OptimizeMode`,
+		},
+		{
+			name: "reporting an invalid default value",
+			inputFiles: map[string]string{
+				"a.proto": `message A { optional bool b = 1 [default = 1]; }`,
+			},
+			wantErr: `a.proto:1:13: invalid default value 1, err want true or false`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := descriptor.InterpretFileSet(resolve(t, test.inputFiles))
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("got err %v, but want %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package descriptor
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// maxEnumNumber is the inclusive end of the enum ranges ending with max.
+const maxEnumNumber = 1<<31 - 1
+
+func (i *interpreter) interpretEnum(src *parser.Enum, p []int32) (*descriptorpb.EnumDescriptorProto, error) {
+	i.addLocation(p, src.Meta, src.Comments, src.InlineComment)
+	enum := &descriptorpb.EnumDescriptorProto{
+		Name: proto.String(src.EnumName),
+	}
+
+	for _, b := range src.EnumBody {
+		switch e := b.(type) {
+		case *parser.EnumField:
+			i.addLocation(appendPath(p, enumValueTag, int32(len(enum.Value))), e.Meta, e.Comments, e.InlineComment)
+			n, err := parseNumber(e.Number, e.Meta.Pos)
+			if err != nil {
+				return nil, err
+			}
+			value := &descriptorpb.EnumValueDescriptorProto{
+				Name:   proto.String(e.Ident),
+				Number: proto.Int32(n),
+			}
+			for _, opt := range e.EnumValueOptions {
+				if value.Options == nil {
+					value.Options = &descriptorpb.EnumValueOptions{}
+				}
+				if err := i.setOption(value.Options, opt.OptionName, opt.Constant, e.Meta.Pos); err != nil {
+					return nil, err
+				}
+			}
+			enum.Value = append(enum.Value, value)
+		case *parser.Option:
+			i.addLocation(appendPath(p, enumOptionsTag), e.Meta, e.Comments, e.InlineComment)
+			if enum.Options == nil {
+				enum.Options = &descriptorpb.EnumOptions{}
+			}
+			if err := i.setOption(enum.Options, e.OptionName, e.Constant, e.Meta.Pos); err != nil {
+				return nil, err
+			}
+		case *parser.Reserved:
+			if 0 < len(e.Ranges) {
+				i.addLocation(appendPath(p, enumReservedRangeTag), e.Meta, e.Comments, e.InlineComment)
+			} else {
+				i.addLocation(appendPath(p, enumReservedNameTag), e.Meta, e.Comments, e.InlineComment)
+			}
+			for _, r := range e.Ranges {
+				start, end, err := parseRange(r, maxEnumNumber, e.Meta.Pos)
+				if err != nil {
+					return nil, err
+				}
+				enum.ReservedRange = append(enum.ReservedRange, &descriptorpb.EnumDescriptorProto_EnumReservedRange{
+					Start: proto.Int32(start),
+					End:   proto.Int32(end),
+				})
+			}
+			for _, name := range e.FieldNames {
+				enum.ReservedName = append(enum.ReservedName, unquoteName(name))
+			}
+		}
+	}
+	return enum, nil
+}
@@ -0,0 +1,434 @@
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+const (
+	// maxFieldNumber is the exclusive end of the ranges ending with max.
+	maxFieldNumber = 1<<29 - 1
+	// maxMessageSetFieldNumber is the exclusive end of the ranges ending with max in a message set.
+	maxMessageSetFieldNumber = 1<<31 - 1
+)
+
+// scope is where extensions are declared.
+type scope struct {
+	// name is the fully qualified name.
+	name string
+	// path is the path of SourceCodeInfo.
+	path []int32
+
+	extensions    *[]*descriptorpb.FieldDescriptorProto
+	extensionsTag int32
+	// nestedTypes is where the messages of the groups in extensions are declared.
+	nestedTypes   *[]*descriptorpb.DescriptorProto
+	nestedTypeTag int32
+}
+
+func (i *interpreter) interpretMessage(
+	src *parser.Message,
+	parent string,
+	p []int32,
+) (*descriptorpb.DescriptorProto, error) {
+	i.addLocation(p, src.Meta, src.Comments, src.InlineComment)
+	return i.interpretMessageBody(src.MessageName, join(parent, src.MessageName), src.MessageBody, p)
+}
+
+func (i *interpreter) interpretMessageBody(
+	name string,
+	fullName string,
+	body []parser.Visitee,
+	p []int32,
+) (*descriptorpb.DescriptorProto, error) {
+	msg := &descriptorpb.DescriptorProto{
+		Name: proto.String(name),
+	}
+	sc := &scope{
+		name:          fullName,
+		path:          p,
+		extensions:    &msg.Extension,
+		extensionsTag: messageExtensionTag,
+		nestedTypes:   &msg.NestedType,
+		nestedTypeTag: messageNestedTypeTag,
+	}
+	fieldPath := func() []int32 {
+		return appendPath(p, messageFieldTag, int32(len(msg.Field)))
+	}
+	nestedTypePath := func() []int32 {
+		return appendPath(p, messageNestedTypeTag, int32(len(msg.NestedType)))
+	}
+
+	var proto3Optionals []*descriptorpb.FieldDescriptorProto
+	var maxRanges []*int32
+	for _, b := range body {
+		switch e := b.(type) {
+		case *parser.Field:
+			i.addLocation(fieldPath(), e.Meta, e.Comments, e.InlineComment)
+			f, err := i.interpretField(e)
+			if err != nil {
+				return nil, err
+			}
+			msg.Field = append(msg.Field, f)
+			if f.GetProto3Optional() {
+				proto3Optionals = append(proto3Optionals, f)
+			}
+		case *parser.MapField:
+			i.addLocation(fieldPath(), e.Meta, e.Comments, e.InlineComment)
+			f, entry, err := i.interpretMapField(e, fullName)
+			if err != nil {
+				return nil, err
+			}
+			msg.Field = append(msg.Field, f)
+			msg.NestedType = append(msg.NestedType, entry)
+		case *parser.GroupField:
+			i.addLocation(fieldPath(), e.Meta, e.Comments, e.InlineComment)
+			f, group, err := i.interpretGroupField(e, fullName, nestedTypePath())
+			if err != nil {
+				return nil, err
+			}
+			msg.Field = append(msg.Field, f)
+			msg.NestedType = append(msg.NestedType, group)
+		case *parser.Oneof:
+			index := int32(len(msg.OneofDecl))
+			i.addLocation(appendPath(p, messageOneofDeclTag, index), e.Meta, e.Comments, e.InlineComment)
+			oneof := &descriptorpb.OneofDescriptorProto{
+				Name: proto.String(e.OneofName),
+			}
+			for _, opt := range e.Options {
+				if oneof.Options == nil {
+					oneof.Options = &descriptorpb.OneofOptions{}
+				}
+				if err := i.setOption(oneof.Options, opt.OptionName, opt.Constant, opt.Meta.Pos); err != nil {
+					return nil, err
+				}
+			}
+			msg.OneofDecl = append(msg.OneofDecl, oneof)
+
+			for _, field := range e.OneofFields {
+				i.addLocation(fieldPath(), field.Meta, field.Comments, field.InlineComment)
+				f, err := i.newField(field.FieldName, field.FieldNumber, field.Type, field, field.FieldOptions, field.Meta.Pos)
+				if err != nil {
+					return nil, err
+				}
+				f.OneofIndex = proto.Int32(index)
+				msg.Field = append(msg.Field, f)
+			}
+		case *parser.Message:
+			m, err := i.interpretMessage(e, fullName, nestedTypePath())
+			if err != nil {
+				return nil, err
+			}
+			msg.NestedType = append(msg.NestedType, m)
+		case *parser.Enum:
+			en, err := i.interpretEnum(e, appendPath(p, messageEnumTypeTag, int32(len(msg.EnumType))))
+			if err != nil {
+				return nil, err
+			}
+			msg.EnumType = append(msg.EnumType, en)
+		case *parser.Extend:
+			if err := i.interpretExtend(e, sc); err != nil {
+				return nil, err
+			}
+		case *parser.Option:
+			i.addLocation(appendPath(p, messageOptionsTag), e.Meta, e.Comments, e.InlineComment)
+			if msg.Options == nil {
+				msg.Options = &descriptorpb.MessageOptions{}
+			}
+			if err := i.setOption(msg.Options, e.OptionName, e.Constant, e.Meta.Pos); err != nil {
+				return nil, err
+			}
+		case *parser.Reserved:
+			if 0 < len(e.Ranges) {
+				i.addLocation(appendPath(p, messageReservedRangeTag), e.Meta, e.Comments, e.InlineComment)
+			} else {
+				i.addLocation(appendPath(p, messageReservedNameTag), e.Meta, e.Comments, e.InlineComment)
+			}
+			for _, r := range e.Ranges {
+				start, end, err := parseRange(r, maxFieldNumber, e.Meta.Pos)
+				if err != nil {
+					return nil, err
+				}
+				reserved := &descriptorpb.DescriptorProto_ReservedRange{
+					Start: proto.Int32(start),
+					End:   proto.Int32(end + 1),
+				}
+				if r.End == "max" {
+					maxRanges = append(maxRanges, reserved.End)
+				}
+				msg.ReservedRange = append(msg.ReservedRange, reserved)
+			}
+			for _, name := range e.FieldNames {
+				msg.ReservedName = append(msg.ReservedName, unquoteName(name))
+			}
+		case *parser.Extensions:
+			i.addLocation(appendPath(p, messageExtensionRangeTag), e.Meta, e.Comments, e.InlineComment)
+			options, err := interpretDeclarations(e.Declarations, e.Meta.Pos)
+			if err != nil {
+				return nil, err
+			}
+			for j, r := range e.Ranges {
+				start, end, err := parseRange(r, maxFieldNumber, e.Meta.Pos)
+				if err != nil {
+					return nil, err
+				}
+				rangeOptions := options
+				if 0 < j && options != nil {
+					// Each range owns its options so that changing one of them does not affect the others.
+					rangeOptions = proto.Clone(options).(*descriptorpb.ExtensionRangeOptions)
+				}
+				extensionRange := &descriptorpb.DescriptorProto_ExtensionRange{
+					Start:   proto.Int32(start),
+					End:     proto.Int32(end + 1),
+					Options: rangeOptions,
+				}
+				if r.End == "max" {
+					maxRanges = append(maxRanges, extensionRange.End)
+				}
+				msg.ExtensionRange = append(msg.ExtensionRange, extensionRange)
+			}
+		}
+	}
+
+	if msg.GetOptions().GetMessageSetWireFormat() {
+		for _, end := range maxRanges {
+			*end = maxMessageSetFieldNumber
+		}
+	}
+	addSyntheticOneofs(msg, proto3Optionals)
+	return msg, nil
+}
+
+// addSyntheticOneofs adds the oneofs of proto3 optional fields after all of the real oneofs, like protoc.
+func addSyntheticOneofs(msg *descriptorpb.DescriptorProto, fields []*descriptorpb.FieldDescriptorProto) {
+	names := make(map[string]bool)
+	for _, f := range msg.Field {
+		names[f.GetName()] = true
+	}
+	for _, o := range msg.OneofDecl {
+		names[o.GetName()] = true
+	}
+
+	for _, f := range fields {
+		name := "_" + f.GetName()
+		for names[name] {
+			name = "X" + name
+		}
+		names[name] = true
+
+		f.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+		msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{
+			Name: proto.String(name),
+		})
+	}
+}
+
+func (i *interpreter) interpretField(src *parser.Field) (*descriptorpb.FieldDescriptorProto, error) {
+	f, err := i.newField(src.FieldName, src.FieldNumber, src.Type, src, src.FieldOptions, src.Meta.Pos)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case src.IsRepeated:
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	case src.IsRequired:
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum()
+	case src.IsOptional && i.proto3:
+		f.Proto3Optional = proto.Bool(true)
+	}
+	return f, nil
+}
+
+func (i *interpreter) interpretMapField(
+	src *parser.MapField,
+	parent string,
+) (*descriptorpb.FieldDescriptorProto, *descriptorpb.DescriptorProto, error) {
+	entryName := mapEntryName(src.MapName)
+
+	key, err := i.newField("key", "1", src.KeyType, nil, nil, src.Meta.Pos)
+	if err != nil {
+		return nil, nil, err
+	}
+	value, err := i.newField("value", "2", src.Type, src, nil, src.Meta.Pos)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry := &descriptorpb.DescriptorProto{
+		Name:  proto.String(entryName),
+		Field: []*descriptorpb.FieldDescriptorProto{key, value},
+		Options: &descriptorpb.MessageOptions{
+			MapEntry: proto.Bool(true),
+		},
+	}
+
+	f, err := i.newField(src.MapName, src.FieldNumber, "", nil, src.FieldOptions, src.Meta.Pos)
+	if err != nil {
+		return nil, nil, err
+	}
+	f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	f.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+	f.TypeName = proto.String("." + join(parent, entryName))
+	return f, entry, nil
+}
+
+func (i *interpreter) interpretGroupField(
+	src *parser.GroupField,
+	parent string,
+	p []int32,
+) (*descriptorpb.FieldDescriptorProto, *descriptorpb.DescriptorProto, error) {
+	fullName := join(parent, src.GroupName)
+	i.addLocation(p, src.Meta, nil, nil)
+	group, err := i.interpretMessageBody(src.GroupName, fullName, src.MessageBody, p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := i.newField(strings.ToLower(src.GroupName), src.FieldNumber, "", nil, nil, src.Meta.Pos)
+	if err != nil {
+		return nil, nil, err
+	}
+	f.Type = descriptorpb.FieldDescriptorProto_TYPE_GROUP.Enum()
+	f.TypeName = proto.String("." + fullName)
+	switch {
+	case src.IsRepeated:
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	case src.IsRequired:
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum()
+	}
+	return f, group, nil
+}
+
+// newField creates a field. typ is the type as written, or "" if the caller sets the type.
+// node is the element having the type reference.
+func (i *interpreter) newField(
+	name string,
+	number string,
+	typ string,
+	node interface{},
+	options []*parser.FieldOption,
+	pos meta.Position,
+) (*descriptorpb.FieldDescriptorProto, error) {
+	n, err := parseNumber(number, pos)
+	if err != nil {
+		return nil, err
+	}
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(n),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		JsonName: proto.String(jsonName(name)),
+	}
+
+	if t, ok := scalarTypes[typ]; ok {
+		f.Type = t.Enum()
+	} else if typ != "" {
+		typeName, symbol, err := i.typeName(node, pos)
+		if err != nil {
+			return nil, err
+		}
+		f.TypeName = proto.String(typeName)
+		if symbol.Kind == linker.SymbolKindEnum {
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_ENUM.Enum()
+		} else {
+			f.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		}
+	}
+
+	for _, opt := range options {
+		switch opt.OptionName {
+		case "default":
+			value, err := defaultValue(f.GetType(), opt.Constant)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid default value %s, err %v", pos, opt.Constant, err)
+			}
+			f.DefaultValue = proto.String(value)
+		case "json_name":
+			value, err := unquote(opt.Constant)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid json_name %s, err %v", pos, opt.Constant, err)
+			}
+			f.JsonName = proto.String(value)
+		default:
+			if f.Options == nil {
+				f.Options = &descriptorpb.FieldOptions{}
+			}
+			if err := i.setOption(f.Options, opt.OptionName, opt.Constant, pos); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f, nil
+}
+
+func (i *interpreter) interpretExtend(src *parser.Extend, sc *scope) error {
+	i.addLocation(appendPath(sc.path, sc.extensionsTag), src.Meta, src.Comments, src.InlineComment)
+	extendee, _, err := i.typeName(src, src.Meta.Pos)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range src.ExtendBody {
+		var f *descriptorpb.FieldDescriptorProto
+		switch e := b.(type) {
+		case *parser.Field:
+			i.addLocation(appendPath(sc.path, sc.extensionsTag, int32(len(*sc.extensions))), e.Meta, e.Comments, e.InlineComment)
+			f, err = i.interpretField(e)
+		case *parser.GroupField:
+			i.addLocation(appendPath(sc.path, sc.extensionsTag, int32(len(*sc.extensions))), e.Meta, e.Comments, e.InlineComment)
+			var group *descriptorpb.DescriptorProto
+			f, group, err = i.interpretGroupField(e, sc.name, appendPath(sc.path, sc.nestedTypeTag, int32(len(*sc.nestedTypes))))
+			if err == nil {
+				*sc.nestedTypes = append(*sc.nestedTypes, group)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		f.Extendee = proto.String(extendee)
+		*sc.extensions = append(*sc.extensions, f)
+	}
+	return nil
+}
+
+func interpretDeclarations(
+	declarations []*parser.Declaration,
+	pos meta.Position,
+) (*descriptorpb.ExtensionRangeOptions, error) {
+	if len(declarations) == 0 {
+		return nil, nil
+	}
+	options := &descriptorpb.ExtensionRangeOptions{}
+	for _, d := range declarations {
+		declaration := &descriptorpb.ExtensionRangeOptions_Declaration{}
+		if d.Number != "" {
+			n, err := parseNumber(d.Number, pos)
+			if err != nil {
+				return nil, err
+			}
+			declaration.Number = proto.Int32(n)
+		}
+		if d.FullName != "" {
+			declaration.FullName = proto.String(unquoteName(d.FullName))
+		}
+		if d.Type != "" {
+			declaration.Type = proto.String(unquoteName(d.Type))
+		}
+		if d.Reserved {
+			declaration.Reserved = proto.Bool(true)
+		}
+		if d.Repeated {
+			declaration.Repeated = proto.Bool(true)
+		}
+		options.Declaration = append(options.Declaration, declaration)
+	}
+	return options, nil
+}
@@ -0,0 +1,274 @@
+package descriptor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+var scalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"double":   descriptorpb.FieldDescriptorProto_TYPE_DOUBLE,
+	"float":    descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+	"int32":    descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"int64":    descriptorpb.FieldDescriptorProto_TYPE_INT64,
+	"uint32":   descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+	"uint64":   descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	"sint32":   descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+	"sint64":   descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+	"fixed32":  descriptorpb.FieldDescriptorProto_TYPE_FIXED32,
+	"fixed64":  descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+	"sfixed32": descriptorpb.FieldDescriptorProto_TYPE_SFIXED32,
+	"sfixed64": descriptorpb.FieldDescriptorProto_TYPE_SFIXED64,
+	"bool":     descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"string":   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bytes":    descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// jsonName converts the field name to lowerCamelCase like protoc.
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, c := range name {
+		switch {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			b.WriteRune(c - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(c)
+			upper = false
+		}
+	}
+	return b.String()
+}
+
+// mapEntryName returns the name of the message for the entries of the map field like protoc.
+func mapEntryName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, c := range name {
+		switch {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			b.WriteRune(c - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(c)
+			upper = false
+		}
+	}
+	return b.String() + "Entry"
+}
+
+func parseNumber(number string, pos meta.Position) (int32, error) {
+	n, err := strconv.ParseInt(number, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid number %q, err %v", pos, number, err)
+	}
+	return int32(n), nil
+}
+
+// parseRange returns the inclusive start and end of the range.
+func parseRange(r *parser.Range, max int32, pos meta.Position) (int32, int32, error) {
+	start, err := parseNumber(r.Begin, pos)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch r.End {
+	case "":
+		return start, start, nil
+	case "max":
+		return start, max, nil
+	}
+	end, err := parseNumber(r.End, pos)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// unquoteName returns the reserved name or the name in a declaration without quotes.
+// Reserved names in editions are identifiers without quotes.
+func unquoteName(name string) string {
+	if v, err := unquote(name); err == nil {
+		return v
+	}
+	return name
+}
+
+// unquote interprets the string literal quoted with either double or single quotes.
+func unquote(lit string) (string, error) {
+	if len(lit) < 2 || (lit[0] != '"' && lit[0] != '\'') || lit[len(lit)-1] != lit[0] {
+		return "", fmt.Errorf("want a quoted string")
+	}
+	s := lit[1 : len(lit)-1]
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if len(s) <= i {
+			return "", fmt.Errorf("invalid escape at the end")
+		}
+		switch c = s[i]; c {
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case '\\', '\'', '"', '?':
+			b.WriteByte(c)
+		case 'x', 'X':
+			j := i + 1
+			for j < len(s) && j < i+3 && isHex(s[j]) {
+				j++
+			}
+			v, err := strconv.ParseUint(s[i+1:j], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid hex escape")
+			}
+			b.WriteByte(byte(v))
+			i = j - 1
+		case 'u', 'U':
+			size := 4
+			if c == 'U' {
+				size = 8
+			}
+			if len(s) < i+1+size {
+				return "", fmt.Errorf("invalid unicode escape")
+			}
+			v, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape")
+			}
+			b.WriteRune(rune(v))
+			i += size
+		default:
+			if c < '0' || '7' < c {
+				return "", fmt.Errorf("invalid escape \\%c", c)
+			}
+			j := i
+			for j < len(s) && j < i+3 && '0' <= s[j] && s[j] <= '7' {
+				j++
+			}
+			v, err := strconv.ParseUint(s[i:j], 8, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid octal escape")
+			}
+			b.WriteByte(byte(v))
+			i = j - 1
+		}
+	}
+	return b.String(), nil
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// defaultValue returns the default value in the representation of FieldDescriptorProto.default_value.
+func defaultValue(typ descriptorpb.FieldDescriptorProto_Type, constant string) (string, error) {
+	switch typ {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		return unquote(constant)
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		v, err := unquote(constant)
+		if err != nil {
+			return "", err
+		}
+		return cEscape(v), nil
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		if constant != "true" && constant != "false" {
+			return "", fmt.Errorf("want true or false")
+		}
+		return constant, nil
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		if constant == "" || !isIdentStart(constant[0]) {
+			return "", fmt.Errorf("want an enum value name")
+		}
+		return constant, nil
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+		if constant == "-nan" || constant == "+nan" {
+			// strconv.ParseFloat does not accept a signed nan, whose sign means nothing.
+			return "nan", nil
+		}
+		v, err := strconv.ParseFloat(constant, 64)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case math.IsInf(v, 1):
+			return "inf", nil
+		case math.IsInf(v, -1):
+			return "-inf", nil
+		case math.IsNaN(v):
+			return "nan", nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return "", fmt.Errorf("messages can not have a default value")
+	}
+	if strings.HasPrefix(constant, "-") {
+		v, err := strconv.ParseInt(constant, 0, 64)
+		return strconv.FormatInt(v, 10), err
+	}
+	v, err := strconv.ParseUint(strings.TrimPrefix(constant, "+"), 0, 64)
+	return strconv.FormatUint(v, 10), err
+}
+
+// cEscape escapes the bytes like protoc's CEscape.
+func cEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if c < 0x20 || 0x7f <= c {
+				fmt.Fprintf(&b, `\%03o`, c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,262 @@
+package descriptor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// namePart is a part of an option name separated by dots, like "(foo.bar)" or "baz" in "(foo.bar).baz".
+type namePart struct {
+	name        string
+	isExtension bool
+}
+
+func splitOptionName(name string) ([]namePart, error) {
+	var parts []namePart
+	for rest := name; ; {
+		var part namePart
+		if strings.HasPrefix(rest, "(") {
+			end := strings.IndexByte(rest, ')')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ) in %q", name)
+			}
+			part = namePart{name: rest[1:end], isExtension: true}
+			rest = rest[end+1:]
+		} else {
+			end := strings.IndexByte(rest, '.')
+			if end < 0 {
+				end = len(rest)
+			}
+			part = namePart{name: rest[:end]}
+			rest = rest[end:]
+		}
+		if part.name == "" {
+			return nil, fmt.Errorf("empty part in %q", name)
+		}
+		parts = append(parts, part)
+
+		if rest == "" {
+			return parts, nil
+		}
+		if rest[0] != '.' {
+			return nil, fmt.Errorf("unexpected %q in %q", rest, name)
+		}
+		rest = rest[1:]
+	}
+}
+
+// setOption sets the option to the options message, like *descriptorpb.FileOptions.
+// An option whose name has an extension is added as an uninterpreted_option.
+func (i *interpreter) setOption(options proto.Message, name string, constant string, pos meta.Position) error {
+	parts, err := splitOptionName(name)
+	if err != nil {
+		return fmt.Errorf("%s: invalid option name, err %v", pos, err)
+	}
+	for _, part := range parts {
+		if part.isExtension {
+			if err := addUninterpretedOption(options.ProtoReflect(), parts, constant); err != nil {
+				return fmt.Errorf("%s: invalid value %s of option %q, err %v", pos, constant, name, err)
+			}
+			return nil
+		}
+	}
+
+	msg := options.ProtoReflect()
+	for j, part := range parts {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(part.name))
+		if fd == nil {
+			return fmt.Errorf("%s: unknown option %q", pos, name)
+		}
+		if j < len(parts)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("%s: option %q has a field of %s", pos, name, fd.FullName())
+			}
+			msg = msg.Mutable(fd).Message()
+			continue
+		}
+		if err := setValue(msg, fd, constant); err != nil {
+			return fmt.Errorf("%s: invalid value %s of option %q, err %v", pos, constant, name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(msg protoreflect.Message, fd protoreflect.FieldDescriptor, constant string) error {
+	switch {
+	case fd.IsMap():
+		return fmt.Errorf("unsupported map field %s", fd.FullName())
+	case fd.IsList():
+		list := msg.Mutable(fd).List()
+		for _, c := range splitList(constant) {
+			var v protoreflect.Value
+			var err error
+			if fd.Message() != nil {
+				v = list.NewElement()
+				err = unmarshalAggregate(c, v.Message())
+			} else {
+				v, err = parseScalar(fd, c)
+			}
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+		return nil
+	case fd.Message() != nil:
+		return unmarshalAggregate(constant, msg.Mutable(fd).Message())
+	}
+
+	v, err := parseScalar(fd, constant)
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, v)
+	return nil
+}
+
+// splitList splits the constant in the form of [a,b] into the elements.
+// A constant not enclosed in square brackets is a single element.
+func splitList(constant string) []string {
+	if !strings.HasPrefix(constant, "[") || !strings.HasSuffix(constant, "]") {
+		return []string{constant}
+	}
+	inner := constant[1 : len(constant)-1]
+
+	var elements []string
+	var depth int
+	var quote byte
+	start := 0
+	for j := 0; j < len(inner); j++ {
+		c := inner[j]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				j++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			elements = append(elements, inner[start:j])
+			start = j + 1
+		}
+	}
+	if start < len(inner) {
+		elements = append(elements, inner[start:])
+	}
+	return elements
+}
+
+func unmarshalAggregate(constant string, msg protoreflect.Message) error {
+	if !strings.HasPrefix(constant, "{") || !strings.HasSuffix(constant, "}") {
+		return fmt.Errorf("want a message value in curly brackets")
+	}
+	inner := constant[1 : len(constant)-1]
+	value := msg.New().Interface()
+	if err := prototext.Unmarshal([]byte(inner), value); err != nil {
+		return err
+	}
+	proto.Merge(msg.Interface(), value)
+	return nil
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, constant string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(constant)
+		if err != nil || (constant != "true" && constant != "false") {
+			return protoreflect.Value{}, fmt.Errorf("want true or false")
+		}
+		return protoreflect.ValueOfBool(v), nil
+	case protoreflect.EnumKind:
+		ev := fd.Enum().Values().ByName(protoreflect.Name(constant))
+		if ev == nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown value of %s", fd.Enum().FullName())
+		}
+		return protoreflect.ValueOfEnum(ev.Number()), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(constant, 0, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(constant, 0, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(constant, 0, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(constant, 0, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(constant, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(constant, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		v, err := unquote(constant)
+		return protoreflect.ValueOfString(v), err
+	case protoreflect.BytesKind:
+		v, err := unquote(constant)
+		return protoreflect.ValueOfBytes([]byte(v)), err
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %v", fd.Kind())
+}
+
+// addUninterpretedOption adds the option as an uninterpreted_option, like protoc does before interpreting options.
+func addUninterpretedOption(options protoreflect.Message, parts []namePart, constant string) error {
+	option := &descriptorpb.UninterpretedOption{}
+	for _, part := range parts {
+		option.Name = append(option.Name, &descriptorpb.UninterpretedOption_NamePart{
+			NamePart:    proto.String(part.name),
+			IsExtension: proto.Bool(part.isExtension),
+		})
+	}
+
+	switch {
+	case constant == "":
+		return fmt.Errorf("empty value")
+	case strings.HasPrefix(constant, "{"):
+		option.AggregateValue = proto.String(strings.TrimSpace(constant[1 : len(constant)-1]))
+	case strings.HasPrefix(constant, "["):
+		return fmt.Errorf("unsupported list value")
+	case constant[0] == '"' || constant[0] == '\'':
+		v, err := unquote(constant)
+		if err != nil {
+			return err
+		}
+		option.StringValue = []byte(v)
+	case isIdentStart(constant[0]):
+		option.IdentifierValue = proto.String(constant)
+	default:
+		if v, err := strconv.ParseUint(strings.TrimPrefix(constant, "+"), 0, 64); err == nil {
+			option.PositiveIntValue = proto.Uint64(v)
+		} else if v, err := strconv.ParseInt(constant, 0, 64); err == nil {
+			option.NegativeIntValue = proto.Int64(v)
+		} else if v, err := strconv.ParseFloat(constant, 64); err == nil {
+			option.DoubleValue = proto.Float64(v)
+		} else {
+			return err
+		}
+	}
+
+	fd := options.Descriptor().Fields().ByName("uninterpreted_option")
+	options.Mutable(fd).List().Append(protoreflect.ValueOfMessage(option.ProtoReflect()))
+	return nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
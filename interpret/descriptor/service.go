@@ -0,0 +1,73 @@
+package descriptor
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func (i *interpreter) interpretService(src *parser.Service, p []int32) (*descriptorpb.ServiceDescriptorProto, error) {
+	i.addLocation(p, src.Meta, src.Comments, src.InlineComment)
+	service := &descriptorpb.ServiceDescriptorProto{
+		Name: proto.String(src.ServiceName),
+	}
+
+	for _, b := range src.ServiceBody {
+		switch e := b.(type) {
+		case *parser.RPC:
+			method, err := i.interpretRPC(e, appendPath(p, serviceMethodTag, int32(len(service.Method))))
+			if err != nil {
+				return nil, err
+			}
+			service.Method = append(service.Method, method)
+		case *parser.Option:
+			i.addLocation(appendPath(p, serviceOptionsTag), e.Meta, e.Comments, e.InlineComment)
+			if service.Options == nil {
+				service.Options = &descriptorpb.ServiceOptions{}
+			}
+			if err := i.setOption(service.Options, e.OptionName, e.Constant, e.Meta.Pos); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return service, nil
+}
+
+func (i *interpreter) interpretRPC(src *parser.RPC, p []int32) (*descriptorpb.MethodDescriptorProto, error) {
+	i.addLocation(p, src.Meta, src.Comments, src.InlineComment)
+	method := &descriptorpb.MethodDescriptorProto{
+		Name: proto.String(src.RPCName),
+	}
+
+	if src.RPCRequest != nil {
+		inputType, _, err := i.typeName(src.RPCRequest, src.Meta.Pos)
+		if err != nil {
+			return nil, err
+		}
+		method.InputType = proto.String(inputType)
+		if src.RPCRequest.IsStream {
+			method.ClientStreaming = proto.Bool(true)
+		}
+	}
+	if src.RPCResponse != nil {
+		outputType, _, err := i.typeName(src.RPCResponse, src.Meta.Pos)
+		if err != nil {
+			return nil, err
+		}
+		method.OutputType = proto.String(outputType)
+		if src.RPCResponse.IsStream {
+			method.ServerStreaming = proto.Bool(true)
+		}
+	}
+
+	for _, opt := range src.Options {
+		if method.Options == nil {
+			method.Options = &descriptorpb.MethodOptions{}
+		}
+		if err := i.setOption(method.Options, opt.OptionName, opt.Constant, opt.Meta.Pos); err != nil {
+			return nil, err
+		}
+	}
+	return method, nil
+}
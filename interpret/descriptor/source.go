@@ -0,0 +1,130 @@
+package descriptor
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// addFileLocation adds the location of the whole file.
+func (i *interpreter) addFileLocation(src *parser.Proto) {
+	last := meta.Position{Line: 1}
+	update := func(m meta.Meta, inlineComment *parser.Comment) {
+		if last.Offset < m.LastPos.Offset {
+			last = m.LastPos
+		}
+		if inlineComment != nil && last.Offset < inlineComment.Meta.LastPos.Offset {
+			last = inlineComment.Meta.LastPos
+		}
+	}
+	if src.Syntax != nil {
+		update(src.Syntax.Meta, src.Syntax.InlineComment)
+	}
+	if src.Edition != nil {
+		update(src.Edition.Meta, src.Edition.InlineComment)
+	}
+	for _, body := range src.ProtoBody {
+		switch e := body.(type) {
+		case *parser.Import:
+			update(e.Meta, e.InlineComment)
+		case *parser.Package:
+			update(e.Meta, e.InlineComment)
+		case *parser.Option:
+			update(e.Meta, e.InlineComment)
+		case *parser.Message:
+			update(e.Meta, e.InlineComment)
+		case *parser.Enum:
+			update(e.Meta, e.InlineComment)
+		case *parser.Service:
+			update(e.Meta, e.InlineComment)
+		case *parser.Extend:
+			update(e.Meta, e.InlineComment)
+		}
+	}
+	i.addLocation(nil, meta.Meta{Pos: meta.Position{Line: 1, Column: 1}, LastPos: last}, nil, nil)
+}
+
+// addLocation adds the location of the element at the path of SourceCodeInfo, with its comments.
+func (i *interpreter) addLocation(
+	p []int32,
+	m meta.Meta,
+	comments []*parser.Comment,
+	inlineComment *parser.Comment,
+) {
+	if !i.sourceCodeInfo {
+		return
+	}
+	location := &descriptorpb.SourceCodeInfo_Location{
+		Path: appendPath(p),
+		Span: span(m),
+	}
+	if location.Path == nil {
+		location.Path = []int32{}
+	}
+
+	blocks := commentBlocks(comments)
+	if 0 < len(blocks) {
+		last := blocks[len(blocks)-1]
+		if last[len(last)-1].Meta.LastPos.Line+1 >= m.Pos.Line {
+			location.LeadingComments = proto.String(commentText(last))
+			blocks = blocks[:len(blocks)-1]
+		}
+		for _, block := range blocks {
+			location.LeadingDetachedComments = append(location.LeadingDetachedComments, commentText(block))
+		}
+	}
+	if inlineComment != nil {
+		location.TrailingComments = proto.String(commentText([]*parser.Comment{inlineComment}))
+	}
+	i.source.Location = append(i.source.Location, location)
+}
+
+// span returns the zero-based span of SourceCodeInfo, whose end column is exclusive.
+// It omits the end line if it is the same as the start line.
+func span(m meta.Meta) []int32 {
+	startLine := int32(m.Pos.Line - 1)
+	startColumn := int32(m.Pos.Column - 1)
+	endLine := int32(m.LastPos.Line - 1)
+	endColumn := int32(m.LastPos.Column)
+	if startLine == endLine {
+		return []int32{startLine, startColumn, endColumn}
+	}
+	return []int32{startLine, startColumn, endLine, endColumn}
+}
+
+// commentBlocks groups the comments into the blocks separated by blank lines.
+func commentBlocks(comments []*parser.Comment) [][]*parser.Comment {
+	var blocks [][]*parser.Comment
+	for j, comment := range comments {
+		if j == 0 || comments[j-1].Meta.LastPos.Line+1 < comment.Meta.Pos.Line {
+			blocks = append(blocks, nil)
+		}
+		blocks[len(blocks)-1] = append(blocks[len(blocks)-1], comment)
+	}
+	return blocks
+}
+
+// commentText returns the text of the comments without the comment markers like protoc.
+func commentText(comments []*parser.Comment) string {
+	var b strings.Builder
+	for _, comment := range comments {
+		lines := comment.Lines()
+		if !comment.IsCStyle() {
+			b.WriteString(lines[0])
+			b.WriteString("\n")
+			continue
+		}
+		for j, line := range lines {
+			if 0 < j {
+				line = strings.TrimPrefix(strings.TrimLeft(line, " \t"), "*")
+				b.WriteString("\n")
+			}
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
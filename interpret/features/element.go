@@ -0,0 +1,252 @@
+package features
+
+import (
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// scalarTypes are the types of fields that refer to no declaration.
+var scalarTypes = map[string]bool{
+	"double":   true,
+	"float":    true,
+	"int32":    true,
+	"int64":    true,
+	"uint32":   true,
+	"uint64":   true,
+	"sint32":   true,
+	"sint64":   true,
+	"fixed32":  true,
+	"fixed64":  true,
+	"sfixed32": true,
+	"sfixed64": true,
+	"bool":     true,
+	"string":   true,
+	"bytes":    true,
+}
+
+// packableTypes are the scalar types which can be encoded in the packed format.
+var packableTypes = map[string]bool{
+	"double":   true,
+	"float":    true,
+	"int32":    true,
+	"int64":    true,
+	"uint32":   true,
+	"uint64":   true,
+	"sint32":   true,
+	"sint64":   true,
+	"fixed32":  true,
+	"fixed64":  true,
+	"sfixed32": true,
+	"sfixed64": true,
+	"bool":     true,
+}
+
+// body resolves the features of the declarations placed in the body of a file or a message.
+func (r *resolver) body(parent *descriptorpb.FeatureSet, body []parser.Visitee) error {
+	for _, element := range body {
+		var err error
+		switch e := element.(type) {
+		case *parser.Message:
+			err = r.message(parent, e)
+		case *parser.Enum:
+			err = r.enum(parent, e)
+		case *parser.Service:
+			err = r.service(parent, e)
+		case *parser.Extend:
+			err = r.extend(parent, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *resolver) message(parent *descriptorpb.FeatureSet, message *parser.Message) error {
+	features, err := r.messageBody(parent, message.MessageBody)
+	if err != nil {
+		return err
+	}
+	r.result.features[message] = features
+	return nil
+}
+
+// messageBody resolves the features of the elements in the body of a message or a group,
+// and returns the ones of the message.
+func (r *resolver) messageBody(parent *descriptorpb.FeatureSet, body []parser.Visitee) (*descriptorpb.FeatureSet, error) {
+	var options []*parser.Option
+	for _, element := range body {
+		if option, ok := element.(*parser.Option); ok {
+			options = append(options, option)
+		}
+	}
+	features, err := r.merge(parent, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, element := range body {
+		switch e := element.(type) {
+		case *parser.Field:
+			err = r.field(features, e)
+		case *parser.MapField:
+			var f *descriptorpb.FeatureSet
+			f, err = r.mergeFieldOptions(features, e.FieldOptions, e.Meta.Pos)
+			r.result.features[e] = f
+		case *parser.GroupField:
+			err = r.group(features, e)
+		case *parser.Oneof:
+			err = r.oneof(features, e)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return features, r.body(features, body)
+}
+
+func (r *resolver) field(parent *descriptorpb.FeatureSet, field *parser.Field) error {
+	features, err := r.mergeFieldOptions(parent, field.FieldOptions, field.Meta.Pos)
+	if err != nil {
+		return err
+	}
+	if !r.editions {
+		r.legacyField(features, field)
+	}
+	r.result.features[field] = features
+	return nil
+}
+
+// legacyField sets the features equivalent to the labels and the options of the field in proto2 or proto3.
+func (r *resolver) legacyField(features *descriptorpb.FeatureSet, field *parser.Field) {
+	switch {
+	case field.IsRequired:
+		features.FieldPresence = descriptorpb.FeatureSet_LEGACY_REQUIRED.Enum()
+	case field.IsOptional:
+		features.FieldPresence = descriptorpb.FeatureSet_EXPLICIT.Enum()
+	}
+	for _, option := range field.FieldOptions {
+		if option.OptionName != "packed" {
+			continue
+		}
+		switch option.Constant {
+		case "true":
+			features.RepeatedFieldEncoding = descriptorpb.FeatureSet_PACKED.Enum()
+		case "false":
+			features.RepeatedFieldEncoding = descriptorpb.FeatureSet_EXPANDED.Enum()
+		}
+	}
+}
+
+// group resolves the features of the group, which is the field encoded in the delimited format,
+// and of its body, which is the nested message.
+func (r *resolver) group(parent *descriptorpb.FeatureSet, group *parser.GroupField) error {
+	if _, err := r.messageBody(parent, group.MessageBody); err != nil {
+		return err
+	}
+
+	features := cloneFeatures(parent)
+	features.MessageEncoding = descriptorpb.FeatureSet_DELIMITED.Enum()
+	switch {
+	case group.IsRequired:
+		features.FieldPresence = descriptorpb.FeatureSet_LEGACY_REQUIRED.Enum()
+	case group.IsOptional:
+		features.FieldPresence = descriptorpb.FeatureSet_EXPLICIT.Enum()
+	}
+	r.result.features[group] = features
+	return nil
+}
+
+func (r *resolver) oneof(parent *descriptorpb.FeatureSet, oneof *parser.Oneof) error {
+	features, err := r.merge(parent, oneof.Options)
+	if err != nil {
+		return err
+	}
+	r.result.features[oneof] = features
+
+	for _, field := range oneof.OneofFields {
+		f, err := r.mergeFieldOptions(features, field.FieldOptions, field.Meta.Pos)
+		if err != nil {
+			return err
+		}
+		r.result.features[field] = f
+	}
+	return nil
+}
+
+func (r *resolver) enum(parent *descriptorpb.FeatureSet, enum *parser.Enum) error {
+	var options []*parser.Option
+	for _, element := range enum.EnumBody {
+		if option, ok := element.(*parser.Option); ok {
+			options = append(options, option)
+		}
+	}
+	features, err := r.merge(parent, options)
+	if err != nil {
+		return err
+	}
+	r.result.features[enum] = features
+
+	for _, element := range enum.EnumBody {
+		field, ok := element.(*parser.EnumField)
+		if !ok {
+			continue
+		}
+		var opts []option
+		for _, o := range field.EnumValueOptions {
+			opts = append(opts, option{name: o.OptionName, constant: o.Constant, value: o.Value, pos: field.Meta.Pos})
+		}
+		f, err := r.mergeOptions(features, opts)
+		if err != nil {
+			return err
+		}
+		r.result.features[field] = f
+	}
+	return nil
+}
+
+func (r *resolver) service(parent *descriptorpb.FeatureSet, service *parser.Service) error {
+	var options []*parser.Option
+	for _, element := range service.ServiceBody {
+		if option, ok := element.(*parser.Option); ok {
+			options = append(options, option)
+		}
+	}
+	features, err := r.merge(parent, options)
+	if err != nil {
+		return err
+	}
+	r.result.features[service] = features
+
+	for _, element := range service.ServiceBody {
+		rpc, ok := element.(*parser.RPC)
+		if !ok {
+			continue
+		}
+		f, err := r.merge(features, rpc.Options)
+		if err != nil {
+			return err
+		}
+		r.result.features[rpc] = f
+	}
+	return nil
+}
+
+// extend resolves the features of the extension fields, which inherit the ones of the scope
+// where they are declared rather than the extended message.
+func (r *resolver) extend(parent *descriptorpb.FeatureSet, extend *parser.Extend) error {
+	for _, element := range extend.ExtendBody {
+		var err error
+		switch e := element.(type) {
+		case *parser.Field:
+			err = r.field(parent, e)
+		case *parser.GroupField:
+			err = r.group(parent, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+// Package features resolves the features of Protocol Buffer editions, that is, google.protobuf.FeatureSet,
+// for every element of a parsed file.
+//
+// The features of an element are the defaults of the edition overridden by the features options of the file,
+// and then of each enclosing element down to the element itself.
+// The files using proto2 or proto3 are given the features equivalent to their behaviors,
+// like protoc does.
+package features
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// Option is an option for Resolve.
+type Option func(*config)
+
+type config struct {
+	linked *linker.Result
+}
+
+// WithLinked is an option to tell the kinds of the field types with the result of linking the file.
+// Without it, a field whose type is not a scalar type is regarded as a message.
+func WithLinked(linked *linker.Result) Option {
+	return func(c *config) {
+		c.linked = linked
+	}
+}
+
+// Result is the resolved features of a file.
+type Result struct {
+	// Edition is the edition of the file. It is either EDITION_PROTO2 or EDITION_PROTO3
+	// if the file uses the syntax statement instead.
+	Edition descriptorpb.Edition
+
+	config   *config
+	file     *descriptorpb.FeatureSet
+	features map[interface{}]*descriptorpb.FeatureSet
+}
+
+// File returns the features of the file.
+// The returned FeatureSet must not be modified.
+func (r *Result) File() *descriptorpb.FeatureSet {
+	return r.file
+}
+
+// Of returns the features of the element, which is either of a message, a field, a map field,
+// a group field, a oneof, a oneof field, an enum, an enum field, a service or an rpc.
+// It returns nil if the element is not in the file.
+// The returned FeatureSet must not be modified.
+func (r *Result) Of(element parser.Visitee) *descriptorpb.FeatureSet {
+	return r.features[element]
+}
+
+// HasPresence reports whether the field tracks its presence, that is, whether it has explicit presence.
+// The field is either of a field, a map field, a group field or a oneof field.
+// It returns false if the field is not in the file.
+func (r *Result) HasPresence(field parser.Visitee) bool {
+	features := r.Of(field)
+	if features == nil {
+		return false
+	}
+	switch f := field.(type) {
+	case *parser.Field:
+		if f.IsRepeated {
+			return false
+		}
+		return features.GetFieldPresence() != descriptorpb.FeatureSet_IMPLICIT || r.isMessage(f, f.Type)
+	case *parser.GroupField:
+		return !f.IsRepeated
+	case *parser.OneofField:
+		return true
+	}
+	return false
+}
+
+// IsPacked reports whether the repeated field is encoded in the packed format.
+// It returns false if the field is not in the file.
+func (r *Result) IsPacked(field *parser.Field) bool {
+	features := r.Of(field)
+	if features == nil || !field.IsRepeated {
+		return false
+	}
+	if !packableTypes[field.Type] && (scalarTypes[field.Type] || r.isMessage(field, field.Type)) {
+		return false
+	}
+	return features.GetRepeatedFieldEncoding() == descriptorpb.FeatureSet_PACKED
+}
+
+// isMessage reports whether the type of the field refers to a message.
+func (r *Result) isMessage(field interface{}, typ string) bool {
+	if scalarTypes[typ] {
+		return false
+	}
+	if r.config.linked != nil {
+		if ref := r.config.linked.ReferenceOf(field); ref != nil && ref.Symbol != nil {
+			return ref.Symbol.Kind == linker.SymbolKindMessage
+		}
+	}
+	return true
+}
+
+// Resolve resolves the features of every element of the proto.
+func Resolve(proto *parser.Proto, opts ...Option) (*Result, error) {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	edition, err := editionOf(proto)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := Defaults(edition)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &resolver{
+		result: &Result{
+			Edition:  edition,
+			config:   c,
+			features: make(map[interface{}]*descriptorpb.FeatureSet),
+		},
+		editions: edition != descriptorpb.Edition_EDITION_PROTO2 && edition != descriptorpb.Edition_EDITION_PROTO3,
+	}
+
+	var options []*parser.Option
+	for _, element := range proto.ProtoBody {
+		if option, ok := element.(*parser.Option); ok {
+			options = append(options, option)
+		}
+	}
+	file, err := r.merge(defaults, options)
+	if err != nil {
+		return nil, err
+	}
+	r.result.file = file
+
+	if err := r.body(file, proto.ProtoBody); err != nil {
+		return nil, err
+	}
+	return r.result, nil
+}
+
+// editionOf returns the edition of the proto.
+func editionOf(proto *parser.Proto) (descriptorpb.Edition, error) {
+	switch {
+	case proto.Edition != nil:
+		edition, ok := descriptorpb.Edition_value["EDITION_"+proto.Edition.Edition]
+		if !ok {
+			return 0, fmt.Errorf("%s: invalid edition %q", proto.Edition.Meta.Pos, proto.Edition.Edition)
+		}
+		return descriptorpb.Edition(edition), nil
+	case proto.Syntax != nil && proto.Syntax.ProtobufVersion == "proto3":
+		return descriptorpb.Edition_EDITION_PROTO3, nil
+	}
+	return descriptorpb.Edition_EDITION_PROTO2, nil
+}
+
+// Defaults returns the default features of the edition.
+func Defaults(edition descriptorpb.Edition) (*descriptorpb.FeatureSet, error) {
+	switch edition {
+	case descriptorpb.Edition_EDITION_PROTO2:
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_EXPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_CLOSED.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_EXPANDED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_NONE.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_LEGACY_BEST_EFFORT.Enum(),
+		}, nil
+	case descriptorpb.Edition_EDITION_PROTO3:
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_IMPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_OPEN.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_PACKED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_VERIFY.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_ALLOW.Enum(),
+		}, nil
+	case descriptorpb.Edition_EDITION_2023, descriptorpb.Edition_EDITION_2024:
+		return &descriptorpb.FeatureSet{
+			FieldPresence:         descriptorpb.FeatureSet_EXPLICIT.Enum(),
+			EnumType:              descriptorpb.FeatureSet_OPEN.Enum(),
+			RepeatedFieldEncoding: descriptorpb.FeatureSet_PACKED.Enum(),
+			Utf8Validation:        descriptorpb.FeatureSet_VERIFY.Enum(),
+			MessageEncoding:       descriptorpb.FeatureSet_LENGTH_PREFIXED.Enum(),
+			JsonFormat:            descriptorpb.FeatureSet_ALLOW.Enum(),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported edition %s", edition)
+}
+
+type resolver struct {
+	result *Result
+	// editions is true if the file uses editions.
+	editions bool
+}
+
+// option is a name and a value of any kinds of options.
+type option struct {
+	name     string
+	constant string
+	// value is the structured constant, which is nil unless the file is parsed with the option value option.
+	value *parser.OptionValue
+	pos   meta.Position
+}
+
+func (r *resolver) merge(parent *descriptorpb.FeatureSet, options []*parser.Option) (*descriptorpb.FeatureSet, error) {
+	var opts []option
+	for _, o := range options {
+		opts = append(opts, option{name: o.OptionName, constant: o.Constant, value: o.Value, pos: o.Meta.Pos})
+	}
+	return r.mergeOptions(parent, opts)
+}
+
+func (r *resolver) mergeFieldOptions(
+	parent *descriptorpb.FeatureSet,
+	options []*parser.FieldOption,
+	pos meta.Position,
+) (*descriptorpb.FeatureSet, error) {
+	var opts []option
+	for _, o := range options {
+		opts = append(opts, option{name: o.OptionName, constant: o.Constant, value: o.Value, pos: pos})
+	}
+	return r.mergeOptions(parent, opts)
+}
+
+// mergeOptions returns a copy of parent overridden by the features options.
+func (r *resolver) mergeOptions(parent *descriptorpb.FeatureSet, options []option) (*descriptorpb.FeatureSet, error) {
+	features := cloneFeatures(parent)
+	for _, o := range options {
+		if err := r.setFeature(features, o); err != nil {
+			return nil, err
+		}
+	}
+	return features, nil
+}
+
+// setFeature sets the option to the features if its name is features.<name>,
+// or sets each field of the message literal if its name is features.
+// The features defined by extensions, like features.(pb.cpp).legacy_closed_enum, are ignored.
+func (r *resolver) setFeature(features *descriptorpb.FeatureSet, o option) error {
+	if o.name == "features" {
+		return r.setFeatures(features, o)
+	}
+	name, ok := strings.CutPrefix(o.name, "features.")
+	if !ok {
+		return nil
+	}
+	if !r.editions {
+		return fmt.Errorf("%s: features are only available in editions, found option %q", o.pos, o.name)
+	}
+	if len(name) != 0 && name[0] == '(' {
+		return nil
+	}
+
+	msg := features.ProtoReflect()
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil || fd.Enum() == nil {
+		return fmt.Errorf("%s: unknown feature %q", o.pos, o.name)
+	}
+	value := fd.Enum().Values().ByName(protoreflect.Name(o.constant))
+	if value == nil || value.Number() == 0 {
+		return fmt.Errorf("%s: invalid value %s of feature %q", o.pos, o.constant, o.name)
+	}
+	msg.Set(fd, protoreflect.ValueOfEnum(value.Number()))
+	return nil
+}
+
+// setFeatures sets each field of the message literal, like { field_presence: IMPLICIT }, to the features.
+func (r *resolver) setFeatures(features *descriptorpb.FeatureSet, o option) error {
+	if !r.editions {
+		return fmt.Errorf("%s: features are only available in editions, found option %q", o.pos, o.name)
+	}
+	value := o.value
+	if value == nil {
+		var err error
+		value, err = parseOptionValue(o.constant)
+		if err != nil {
+			return fmt.Errorf("%s: invalid value %s of option %q: %v", o.pos, o.constant, o.name, err)
+		}
+	}
+	if value.Kind != parser.OptionValueKindMessage {
+		return fmt.Errorf("%s: invalid value %s of option %q, want a message literal", o.pos, o.constant, o.name)
+	}
+	for _, field := range value.Fields {
+		err := r.setFeature(features, option{
+			name:     o.name + "." + field.Name,
+			constant: field.Value.Text,
+			pos:      o.pos,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseOptionValue parses the constant of an option into the structured value.
+func parseOptionValue(constant string) (*parser.OptionValue, error) {
+	p := parser.NewParser(
+		lexer.NewLexer(strings.NewReader("option features = "+constant+";")),
+		parser.WithPermissive(true),
+		parser.WithOptionValue(true),
+	)
+	option, err := p.ParseOption()
+	if err != nil {
+		return nil, err
+	}
+	return option.Value, nil
+}
+
+func cloneFeatures(features *descriptorpb.FeatureSet) *descriptorpb.FeatureSet {
+	return proto.Clone(features).(*descriptorpb.FeatureSet)
+}
@@ -0,0 +1,351 @@
+package features_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/interpret/features"
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+func TestResolve(t *testing.T) {
+	input := `edition = "2023";
+option features.field_presence = IMPLICIT;
+message A {
+  option features.utf8_validation = NONE;
+  string a = 1 [features.field_presence = EXPLICIT];
+  repeated int32 b = 2 [features.repeated_field_encoding = EXPANDED];
+  oneof o {
+    option features.json_format = LEGACY_BEST_EFFORT;
+    string c = 3;
+  }
+  message B {
+    string d = 1;
+  }
+}
+enum E {
+  option features.enum_type = CLOSED;
+  E_A = 0;
+}
+service S {
+  rpc M(A) returns (A);
+}
+`
+	p, err := protoparser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	got, err := features.Resolve(p)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	message := p.ProtoBody[1].(*parser.Message)
+	oneof := message.MessageBody[3].(*parser.Oneof)
+	nested := message.MessageBody[4].(*parser.Message)
+	enum := p.ProtoBody[2].(*parser.Enum)
+	service := p.ProtoBody[3].(*parser.Service)
+
+	with := func(f func(*descriptorpb.FeatureSet)) *descriptorpb.FeatureSet {
+		fs, err := features.Defaults(descriptorpb.Edition_EDITION_2023)
+		if err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+		fs.FieldPresence = descriptorpb.FeatureSet_IMPLICIT.Enum()
+		f(fs)
+		return fs
+	}
+	noUTF8 := func(fs *descriptorpb.FeatureSet) {
+		fs.Utf8Validation = descriptorpb.FeatureSet_NONE.Enum()
+	}
+
+	tests := []struct {
+		name         string
+		inputElement parser.Visitee
+		wantFeatures *descriptorpb.FeatureSet
+	}{
+		{
+			name:         "a message overrides the file",
+			inputElement: message,
+			wantFeatures: with(noUTF8),
+		},
+		{
+			name:         "a field overrides the message",
+			inputElement: message.MessageBody[1],
+			wantFeatures: with(func(fs *descriptorpb.FeatureSet) {
+				noUTF8(fs)
+				fs.FieldPresence = descriptorpb.FeatureSet_EXPLICIT.Enum()
+			}),
+		},
+		{
+			name:         "a oneof field inherits the oneof",
+			inputElement: oneof.OneofFields[0],
+			wantFeatures: with(func(fs *descriptorpb.FeatureSet) {
+				noUTF8(fs)
+				fs.JsonFormat = descriptorpb.FeatureSet_LEGACY_BEST_EFFORT.Enum()
+			}),
+		},
+		{
+			name:         "a nested message field inherits the enclosing messages",
+			inputElement: nested.MessageBody[0],
+			wantFeatures: with(noUTF8),
+		},
+		{
+			name:         "an enum value inherits the enum",
+			inputElement: enum.EnumBody[1],
+			wantFeatures: with(func(fs *descriptorpb.FeatureSet) {
+				fs.EnumType = descriptorpb.FeatureSet_CLOSED.Enum()
+			}),
+		},
+		{
+			name:         "an rpc inherits the file",
+			inputElement: service.ServiceBody[0],
+			wantFeatures: with(func(*descriptorpb.FeatureSet) {}),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if got := got.Of(test.inputElement); !proto.Equal(got, test.wantFeatures) {
+				t.Errorf("got %v, but want %v", got, test.wantFeatures)
+			}
+		})
+	}
+
+	if gotFile := got.File().GetFieldPresence(); gotFile != descriptorpb.FeatureSet_IMPLICIT {
+		t.Errorf("got %v, but want IMPLICIT", gotFile)
+	}
+	if got.HasPresence(message.MessageBody[1]) != true {
+		t.Errorf("got no presence, but want explicit presence")
+	}
+	if got.IsPacked(message.MessageBody[2].(*parser.Field)) {
+		t.Errorf("got packed, but want expanded")
+	}
+}
+
+func TestResolve_messageLiteral(t *testing.T) {
+	input := `edition = "2023";
+option features = {
+  field_presence: IMPLICIT
+  enum_type: CLOSED
+};
+`
+	want, err := features.Defaults(descriptorpb.Edition_EDITION_2023)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	want.FieldPresence = descriptorpb.FeatureSet_IMPLICIT.Enum()
+	want.EnumType = descriptorpb.FeatureSet_CLOSED.Enum()
+
+	for _, optionValue := range []bool{false, true} {
+		p, err := protoparser.Parse(strings.NewReader(input), protoparser.WithOptionValue(optionValue))
+		if err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+		got, err := features.Resolve(p)
+		if err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+		if !proto.Equal(got.File(), want) {
+			t.Errorf("got %v, but want %v with the option value %v", got.File(), want, optionValue)
+		}
+	}
+}
+
+func TestResolve_invalidFeatures(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "features in proto3",
+			input:   "syntax = \"proto3\";\noption features.field_presence = IMPLICIT;\n",
+			wantErr: `<input>:2:1: features are only available in editions, found option "features.field_presence"`,
+		},
+		{
+			name:    "an unknown feature",
+			input:   "edition = \"2023\";\nmessage A {\n  int32 a = 1 [features.unknown = X];\n}\n",
+			wantErr: `<input>:3:3: unknown feature "features.unknown"`,
+		},
+		{
+			name:    "an invalid value",
+			input:   "edition = \"2023\";\noption features.enum_type = PACKED;\n",
+			wantErr: `<input>:2:1: invalid value PACKED of feature "features.enum_type"`,
+		},
+		{
+			name:    "a message literal in proto2",
+			input:   "syntax = \"proto2\";\noption features = { field_presence: IMPLICIT };\n",
+			wantErr: `<input>:2:1: features are only available in editions, found option "features"`,
+		},
+		{
+			name:    "an unknown feature in a message literal",
+			input:   "edition = \"2023\";\noption features = { unknown: X };\n",
+			wantErr: `<input>:2:1: unknown feature "features.unknown"`,
+		},
+		{
+			name:    "a scalar value of features",
+			input:   "edition = \"2023\";\noption features = IMPLICIT;\n",
+			wantErr: `<input>:2:1: invalid value IMPLICIT of option "features", want a message literal`,
+		},
+		{
+			name:    "an invalid edition",
+			input:   "edition = \"1999\";\n",
+			wantErr: `<input>:1:1: invalid edition "1999"`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			proto, err := protoparser.Parse(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			_, err = features.Resolve(proto)
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("got %v, but want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestResult_HasPresence(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		withLinked   bool
+		wantPresence []bool
+		wantPacked   []bool
+	}{
+		{
+			name: "proto2 fields",
+			input: `syntax = "proto2";
+message A {
+  optional int32 a = 1;
+  required int32 b = 2;
+  repeated int32 c = 3;
+  repeated int32 d = 4 [packed = true];
+  repeated string e = 5 [packed = true];
+}
+`,
+			wantPresence: []bool{true, true, false, false, false},
+			wantPacked:   []bool{false, false, false, true, false},
+		},
+		{
+			name: "proto3 fields",
+			input: `syntax = "proto3";
+message A {
+  int32 a = 1;
+  optional int32 b = 2;
+  A c = 3;
+  repeated int32 d = 4;
+  repeated int32 e = 5 [packed = false];
+  E f = 6;
+  repeated E g = 7;
+}
+enum E {
+  E_A = 0;
+}
+`,
+			withLinked:   true,
+			wantPresence: []bool{false, true, true, false, false, false, false},
+			wantPacked:   []bool{false, false, false, true, false, false, true},
+		},
+		{
+			name: "proto3 fields of non-scalar types without linking",
+			input: `syntax = "proto3";
+message A {
+  E a = 1;
+  repeated E b = 2;
+}
+`,
+			wantPresence: []bool{true, false},
+			wantPacked:   []bool{false, false},
+		},
+		{
+			name: "editions fields",
+			input: `edition = "2023";
+message A {
+  int32 a = 1;
+  int32 b = 2 [features.field_presence = IMPLICIT];
+  repeated int32 c = 3;
+  repeated int32 d = 4 [features.repeated_field_encoding = EXPANDED];
+}
+`,
+			wantPresence: []bool{true, false, false, false},
+			wantPacked:   []bool{false, false, true, false},
+		},
+		{
+			name: "editions fields with the message literals of features",
+			input: `edition = "2023";
+option features = { field_presence: IMPLICIT, repeated_field_encoding: EXPANDED };
+message A {
+  int32 a = 1;
+  int32 b = 2 [features = { field_presence: EXPLICIT }];
+  repeated int32 c = 3;
+  repeated int32 d = 4 [features = { repeated_field_encoding: PACKED }];
+}
+`,
+			wantPresence: []bool{false, true, false, false},
+			wantPacked:   []bool{false, false, false, true},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			set, err := resolver.Resolve(
+				[]string{"a.proto"},
+				resolver.WithAccessor(func(filename string) (io.ReadCloser, error) {
+					if filename != "a.proto" {
+						return nil, os.ErrNotExist
+					}
+					return io.NopCloser(strings.NewReader(test.input)), nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			file := set.Files["a.proto"]
+
+			var opts []features.Option
+			if test.withLinked {
+				linked, err := linker.Link(set)
+				if err != nil {
+					t.Fatalf("got err %v, but want nil", err)
+				}
+				opts = append(opts, features.WithLinked(linked))
+			}
+			got, err := features.Resolve(file.Proto, opts...)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			var body []parser.Visitee
+			for _, element := range file.Proto.ProtoBody {
+				if message, ok := element.(*parser.Message); ok {
+					body = message.MessageBody
+				}
+			}
+			for i, element := range body {
+				field := element.(*parser.Field)
+				if got := got.HasPresence(field); got != test.wantPresence[i] {
+					t.Errorf("%s: got presence %v, but want %v", field.FieldName, got, test.wantPresence[i])
+				}
+				if got := got.IsPacked(field); got != test.wantPacked[i] {
+					t.Errorf("%s: got packed %v, but want %v", field.FieldName, got, test.wantPacked[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package unordered
+
+import "github.com/yoheimuta/go-protoparser/v4/parser"
+
+// Clone returns a deep copy of the proto.
+func (p *Proto) Clone() *Proto { return parser.Clone(p).(*Proto) }
+
+// Equal reports whether the proto is equal to the other. See parser.Equal.
+func (p *Proto) Equal(other *Proto, opts ...parser.EqualOption) bool {
+	return parser.Equal(p, other, opts...)
+}
+
+// Clone returns a deep copy of the proto body.
+func (b *ProtoBody) Clone() *ProtoBody { return parser.Clone(b).(*ProtoBody) }
+
+// Equal reports whether the proto body is equal to the other. See parser.Equal.
+func (b *ProtoBody) Equal(other *ProtoBody, opts ...parser.EqualOption) bool {
+	return parser.Equal(b, other, opts...)
+}
+
+// Clone returns a deep copy of the message.
+func (m *Message) Clone() *Message { return parser.Clone(m).(*Message) }
+
+// Equal reports whether the message is equal to the other. See parser.Equal.
+func (m *Message) Equal(other *Message, opts ...parser.EqualOption) bool {
+	return parser.Equal(m, other, opts...)
+}
+
+// Clone returns a deep copy of the message body.
+func (b *MessageBody) Clone() *MessageBody { return parser.Clone(b).(*MessageBody) }
+
+// Equal reports whether the message body is equal to the other. See parser.Equal.
+func (b *MessageBody) Equal(other *MessageBody, opts ...parser.EqualOption) bool {
+	return parser.Equal(b, other, opts...)
+}
+
+// Clone returns a deep copy of the enum.
+func (e *Enum) Clone() *Enum { return parser.Clone(e).(*Enum) }
+
+// Equal reports whether the enum is equal to the other. See parser.Equal.
+func (e *Enum) Equal(other *Enum, opts ...parser.EqualOption) bool {
+	return parser.Equal(e, other, opts...)
+}
+
+// Clone returns a deep copy of the enum body.
+func (b *EnumBody) Clone() *EnumBody { return parser.Clone(b).(*EnumBody) }
+
+// Equal reports whether the enum body is equal to the other. See parser.Equal.
+func (b *EnumBody) Equal(other *EnumBody, opts ...parser.EqualOption) bool {
+	return parser.Equal(b, other, opts...)
+}
+
+// Clone returns a deep copy of the extend.
+func (e *Extend) Clone() *Extend { return parser.Clone(e).(*Extend) }
+
+// Equal reports whether the extend is equal to the other. See parser.Equal.
+func (e *Extend) Equal(other *Extend, opts ...parser.EqualOption) bool {
+	return parser.Equal(e, other, opts...)
+}
+
+// Clone returns a deep copy of the extend body.
+func (b *ExtendBody) Clone() *ExtendBody { return parser.Clone(b).(*ExtendBody) }
+
+// Equal reports whether the extend body is equal to the other. See parser.Equal.
+func (b *ExtendBody) Equal(other *ExtendBody, opts ...parser.EqualOption) bool {
+	return parser.Equal(b, other, opts...)
+}
+
+// Clone returns a deep copy of the service.
+func (s *Service) Clone() *Service { return parser.Clone(s).(*Service) }
+
+// Equal reports whether the service is equal to the other. See parser.Equal.
+func (s *Service) Equal(other *Service, opts ...parser.EqualOption) bool {
+	return parser.Equal(s, other, opts...)
+}
+
+// Clone returns a deep copy of the service body.
+func (b *ServiceBody) Clone() *ServiceBody { return parser.Clone(b).(*ServiceBody) }
+
+// Equal reports whether the service body is equal to the other. See parser.Equal.
+func (b *ServiceBody) Equal(other *ServiceBody, opts ...parser.EqualOption) bool {
+	return parser.Equal(b, other, opts...)
+}
@@ -0,0 +1,52 @@
+package unordered_test
+
+import (
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/interpret/unordered"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func TestProto_Clone(t *testing.T) {
+	interpret := func(t *testing.T, input string) *unordered.Proto {
+		t.Helper()
+		proto, err := protoparser.Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+		got, err := unordered.InterpretProto(proto)
+		if err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+		return got
+	}
+
+	proto := interpret(t, `syntax = "proto3";
+message M {
+  // id is the ID.
+  string id = 1;
+  enum E { A = 0; }
+}
+`)
+	got := proto.Clone()
+	if !got.Equal(proto) {
+		t.Errorf("got %v, but want %v", got, proto)
+	}
+
+	got.ProtoBody.Messages[0].MessageBody.Fields[0].FieldName = "name"
+	if got.Equal(proto) {
+		t.Errorf("got the equal protos, but want the different ones")
+	}
+	if name := proto.ProtoBody.Messages[0].MessageBody.Fields[0].FieldName; name != "id" {
+		t.Errorf("got %q, but want %q", name, "id")
+	}
+
+	other := interpret(t, `syntax = "proto3";
+message M { enum E { A = 0; } string id = 1; }
+`)
+	if !proto.Equal(other, parser.WithIgnoreMeta(true), parser.WithIgnoreComments(true)) {
+		t.Errorf("got the different protos, but want the equal ones ignoring the meta and the comments")
+	}
+}
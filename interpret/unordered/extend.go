@@ -10,6 +10,7 @@ import (
 // ExtendBody is unordered in nature, but each slice field preserves the original order.
 type ExtendBody struct {
 	Fields          []*parser.Field
+	Groups          []*parser.GroupField
 	EmptyStatements []*parser.EmptyStatement
 }
 
@@ -53,11 +54,14 @@ func interpretExtendBody(src []parser.Visitee) (
 	error,
 ) {
 	var fields []*parser.Field
+	var groups []*parser.GroupField
 	var emptyStatements []*parser.EmptyStatement
 	for _, s := range src {
 		switch t := s.(type) {
 		case *parser.Field:
 			fields = append(fields, t)
+		case *parser.GroupField:
+			groups = append(groups, t)
 		case *parser.EmptyStatement:
 			emptyStatements = append(emptyStatements, t)
 		default:
@@ -66,6 +70,7 @@ func interpretExtendBody(src []parser.Visitee) (
 	}
 	return &ExtendBody{
 		Fields:          fields,
+		Groups:          groups,
 		EmptyStatements: emptyStatements,
 	}, nil
 }
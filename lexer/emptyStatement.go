@@ -15,6 +15,7 @@ func (lex *Lexer) ReadEmptyStatement() error {
 	if lex.Token == scanner.TSEMICOLON {
 		return nil
 	}
+	err := lex.unexpected(lex.Text, ";")
 	lex.UnNext()
-	return lex.unexpected(lex.Text, ";")
+	return err
 }
@@ -11,6 +11,7 @@ func (lex *Lexer) unexpected(found, expected string) error {
 		Pos:      lex.Pos.Position,
 		Expected: expected,
 		Found:    lex.Text,
+		Text:     lex.Text,
 	}
 	if lex.debug {
 		_, file, line, _ := runtime.Caller(1)
@@ -7,6 +7,7 @@ import (
 	"runtime"
 
 	"github.com/yoheimuta/go-protoparser/v4/lexer/scanner"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
 )
 
 // Lexer is a lexer.
@@ -50,6 +51,13 @@ func WithFilename(filename string) Option {
 	}
 }
 
+// WithColumnEncoding is an option for scanner.Option.
+func WithColumnEncoding(encoding meta.Encoding) Option {
+	return func(l *Lexer) {
+		l.scannerOpts = append(l.scannerOpts, scanner.WithColumnEncoding(encoding))
+	}
+}
+
 // NewLexer creates a new lexer.
 func NewLexer(input io.Reader, opts ...Option) *Lexer {
 	lex := new(Lexer)
@@ -192,6 +200,23 @@ func (lex *Lexer) ConsumeToken(t scanner.Token) {
 	lex.UnNext()
 }
 
+// ColumnEncoding returns the unit counted by the column of the positions.
+func (lex *Lexer) ColumnEncoding() meta.Encoding {
+	return lex.scanner.ColumnEncoding()
+}
+
+// KeepSource makes the lexer keep the text read from the input for Source.
+// It must be called before the first scan.
+func (lex *Lexer) KeepSource() {
+	lex.scanner.KeepSource()
+}
+
+// Source returns all the text read from the input so far.
+// It returns an empty string unless KeepSource is called.
+func (lex *Lexer) Source() string {
+	return lex.scanner.Source()
+}
+
 // FindMidComments finds comments between from and to.
 func (lex *Lexer) FindMidComments(from scanner.Position, to scanner.Position) []scanner.Text {
 	comments := lex.scanner.GetScannedComments()
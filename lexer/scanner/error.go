@@ -13,6 +13,9 @@ func (s *Scanner) unexpected(found rune, expected string) error {
 		Expected: expected,
 		Found:    string(found),
 	}
+	if found != eof {
+		err.Text = string(found)
+	}
 	err.SetOccured(file, line)
 	return err
 }
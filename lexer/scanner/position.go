@@ -12,6 +12,8 @@ type Position struct {
 
 	// columns is a map which the key is a line number and the value is a column number.
 	columns map[int]int
+	// encoding is the unit counted by the column.
+	encoding meta.Encoding
 }
 
 // NewPosition creates a new Position.
@@ -26,6 +28,11 @@ func NewPosition() *Position {
 	}
 }
 
+// Encoding returns the unit counted by the column.
+func (pos Position) Encoding() meta.Encoding {
+	return pos.encoding
+}
+
 // String stringify the position.
 func (pos Position) String() string {
 	return pos.Position.String()
@@ -42,7 +49,7 @@ func (pos *Position) Advance(r rune) {
 		pos.Line++
 		pos.Column = 1
 	} else {
-		pos.Column++
+		pos.Column += pos.encoding.Len(r)
 	}
 }
 
@@ -65,6 +72,6 @@ func (pos *Position) Revert(r rune) {
 		pos.Line--
 		pos.Column = pos.columns[pos.Line]
 	} else {
-		pos.Column--
+		pos.Column -= pos.encoding.Len(r)
 	}
 }
@@ -3,7 +3,10 @@ package scanner
 import (
 	"bufio"
 	"io"
+	"strings"
 	"unicode"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
 )
 
 var eof = rune(0)
@@ -29,6 +32,11 @@ type Scanner struct {
 	// comments are all the scanned comments.
 	// These can be duplicated.
 	comments []Text
+
+	// keepSource is whether to keep the text read from r in source.
+	keepSource bool
+	// source is all the text read from r so far. It is kept only when keepSource is true.
+	source strings.Builder
 }
 
 // Option is an option for scanner.NewScanner.
@@ -41,6 +49,14 @@ func WithFilename(filename string) Option {
 	}
 }
 
+// WithColumnEncoding is an option to set the unit counted by the column of the positions.
+// The default is meta.EncodingRune.
+func WithColumnEncoding(encoding meta.Encoding) Option {
+	return func(l *Scanner) {
+		l.pos.encoding = encoding
+	}
+}
+
 // NewScanner returns a new instance of Scanner.
 func NewScanner(r io.Reader, opts ...Option) *Scanner {
 	s := &Scanner{
@@ -72,6 +88,9 @@ func (s *Scanner) read() (r rune) {
 	if err != nil {
 		return eof
 	}
+	if s.keepSource {
+		s.source.WriteRune(ch)
+	}
 	return ch
 }
 
@@ -102,6 +121,11 @@ func (s *Scanner) UnScan() Position {
 	return *s.pos
 }
 
+// ColumnEncoding returns the unit counted by the column of the positions.
+func (s *Scanner) ColumnEncoding() meta.Encoding {
+	return s.pos.encoding
+}
+
 // Scan returns the next token and text value.
 func (s *Scanner) Scan() (Token, string, Position, error) {
 	s.lastScanRaw = s.lastScanRaw[:0]
@@ -120,6 +144,18 @@ func (s *Scanner) SetLastScanRaw(raw []rune) {
 	s.lastScanRaw = raw
 }
 
+// KeepSource makes the scanner keep the text read from the input for Source.
+// It must be called before the first scan.
+func (s *Scanner) KeepSource() {
+	s.keepSource = true
+}
+
+// Source returns all the text read from the input so far.
+// It returns an empty string unless KeepSource is called.
+func (s *Scanner) Source() string {
+	return s.source.String()
+}
+
 // GetScannedComments returns all the uniquely scanned comments.
 func (s *Scanner) GetScannedComments() []Text {
 	var uniqueComments []Text
@@ -762,3 +762,99 @@ func TestScanner_UnScan(t *testing.T) {
 		})
 	}
 }
+
+func TestScanner_Source(t *testing.T) {
+	input := "message  A { // comment\n}\n"
+	s := scanner.NewScanner(strings.NewReader(input), scanner.WithFilename("test.proto"))
+	s.KeepSource()
+
+	_, _, _, err := s.Scan()
+	if err != nil {
+		t.Errorf("got err %v, but want nil", err)
+	}
+	s.UnScan()
+	if got := s.Source(); !strings.HasPrefix(input, got) {
+		t.Errorf("got %q, but want a prefix of %q", got, input)
+	}
+
+	for {
+		token, _, _, err := s.Scan()
+		if err != nil {
+			t.Errorf("got err %v, but want nil", err)
+			return
+		}
+		if token == scanner.TEOF {
+			break
+		}
+	}
+	if got := s.Source(); got != input {
+		t.Errorf("got %q, but want %q", got, input)
+	}
+}
+
+func TestScanner_SourceNotKept(t *testing.T) {
+	s := scanner.NewScanner(strings.NewReader("message A {}\n"))
+	for {
+		token, _, _, err := s.Scan()
+		if err != nil {
+			t.Errorf("got err %v, but want nil", err)
+			return
+		}
+		if token == scanner.TEOF {
+			break
+		}
+	}
+	if got := s.Source(); got != "" {
+		t.Errorf("got %q, but want empty", got)
+	}
+}
+
+func TestScanner_ColumnEncoding(t *testing.T) {
+	tests := []struct {
+		name         string
+		inputOpts    []scanner.Option
+		wantEncoding meta.Encoding
+		wantColumn   int
+	}{
+		{
+			name:         "count runes by default",
+			wantEncoding: meta.EncodingRune,
+			wantColumn:   8,
+		},
+		{
+			name:         "count UTF-16 code units",
+			inputOpts:    []scanner.Option{scanner.WithColumnEncoding(meta.EncodingUTF16)},
+			wantEncoding: meta.EncodingUTF16,
+			wantColumn:   10,
+		},
+		{
+			name:         "count bytes",
+			inputOpts:    []scanner.Option{scanner.WithColumnEncoding(meta.EncodingUTF8)},
+			wantEncoding: meta.EncodingUTF8,
+			wantColumn:   16,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			s := scanner.NewScanner(strings.NewReader(`"😀😀" あ a`), test.inputOpts...)
+			if got := s.ColumnEncoding(); got != test.wantEncoding {
+				t.Errorf("got %v, but want %v", got, test.wantEncoding)
+			}
+
+			s.Mode = scanner.ScanStrLit | scanner.ScanIdent
+			var pos scanner.Position
+			for i := 0; i < 3; i++ {
+				_, _, pos, _ = s.Scan()
+			}
+			if pos.Column != test.wantColumn {
+				t.Errorf("got %d, but want %d", pos.Column, test.wantColumn)
+			}
+			// UnScan puts back the space before the token as well.
+			if got := s.UnScan(); got.Column != test.wantColumn-1 {
+				t.Errorf("got %d, but want %d after UnScan", got.Column, test.wantColumn-1)
+			}
+		})
+	}
+}
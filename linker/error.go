@@ -0,0 +1,54 @@
+package linker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+var (
+	// ErrUnresolved is the cause of a ReferenceError when no declaration matches the reference.
+	ErrUnresolved = errors.New("unresolved reference")
+	// ErrAmbiguous is the cause of a ReferenceError when more than one declaration matches the reference.
+	ErrAmbiguous = errors.New("ambiguous reference")
+)
+
+// ReferenceError is the error of a type reference that cannot be resolved to a single declaration.
+type ReferenceError struct {
+	// Pos is the position of the element having the reference.
+	Pos meta.Position
+	// Name is the referenced name as written.
+	Name string
+	// Candidates are the declarations matching the reference, if Err is ErrAmbiguous.
+	Candidates []*Symbol
+	// Detail describes the cause in detail, if any.
+	Detail string
+	// Err is the cause, either ErrUnresolved or ErrAmbiguous.
+	Err error
+}
+
+func (e *ReferenceError) Error() string {
+	msg := fmt.Sprintf("%s: %v %q", e.Pos, e.Err, e.Name)
+	if e.Detail != "" {
+		msg += ", " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap returns the cause.
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the list of errors found while linking.
+type Errors []*ReferenceError
+
+func (e Errors) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
@@ -0,0 +1,361 @@
+// Package linker resolves the type references in Protocol Buffer files to their declarations.
+package linker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// Reference is a type reference in a file.
+type Reference struct {
+	// Name is the referenced name as written, like "Message" or ".foo.bar.Message".
+	Name string
+	// Scope is the fully qualified name of the scope where the reference is written.
+	Scope string
+	// Node is the element having the reference, which is either *parser.Field, *parser.MapField,
+	// *parser.OneofField, *parser.RPCRequest, *parser.RPCResponse or *parser.Extend.
+	Node interface{}
+	// File is the file where the reference is written.
+	File *resolver.File
+	// Symbol is the declaration the name is resolved to, or nil if the reference cannot be resolved.
+	Symbol *Symbol
+}
+
+// Result is the result of linking a set of files.
+type Result struct {
+	// Symbols are the symbols declared in the files.
+	Symbols *SymbolTable
+	// References are the type references in the order of the files and the elements.
+	References []*Reference
+
+	references map[interface{}]*Reference
+}
+
+// ReferenceOf returns the reference of the node, which is either *parser.Field, *parser.MapField,
+// *parser.OneofField, *parser.RPCRequest, *parser.RPCResponse or *parser.Extend.
+// It returns nil if the node has no type reference, like a field of a scalar type.
+func (r *Result) ReferenceOf(node interface{}) *Reference {
+	return r.references[node]
+}
+
+// Link builds the symbol table from the files and resolves every type reference in them,
+// following the scoping rules of Protocol Buffers.
+// A reference is resolved only to the declarations in the same file or in the files it imports,
+// including the ones imported publicly by them.
+//
+// If some references cannot be resolved, it returns Errors along with the result,
+// in which those references have no symbol.
+func Link(set *resolver.FileSet) (*Result, error) {
+	l := &linker{
+		set: set,
+		result: &Result{
+			Symbols:    newSymbolTable(),
+			references: make(map[interface{}]*Reference),
+		},
+	}
+	for _, file := range set.Sorted() {
+		l.declareFile(file)
+	}
+	for _, file := range set.Sorted() {
+		l.resolveFile(file)
+	}
+	if 0 < len(l.errs) {
+		return l.result, l.errs
+	}
+	return l.result, nil
+}
+
+type linker struct {
+	set    *resolver.FileSet
+	result *Result
+	errs   Errors
+
+	// file and visible are the file being resolved and the set of files visible from it.
+	file    *resolver.File
+	visible map[*resolver.File]bool
+}
+
+// PackageName returns the package name of the proto, or "" if it has no package statement.
+func PackageName(proto *parser.Proto) string {
+	name := ""
+	for _, body := range proto.ProtoBody {
+		if pkg, ok := body.(*parser.Package); ok {
+			name = pkg.Name
+		}
+	}
+	return name
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func (l *linker) declareFile(file *resolver.File) {
+	var pkg *parser.Package
+	for _, body := range file.Proto.ProtoBody {
+		if p, ok := body.(*parser.Package); ok {
+			pkg = p
+		}
+	}
+	scope := ""
+	if pkg != nil {
+		for _, name := range strings.Split(pkg.Name, ".") {
+			scope = join(scope, name)
+			l.result.Symbols.add(&Symbol{
+				FullName: scope,
+				Kind:     SymbolKindPackage,
+				File:     file,
+				Node:     pkg,
+			})
+		}
+	}
+	l.declareBody(file, scope, file.Proto.ProtoBody)
+}
+
+func (l *linker) declareBody(file *resolver.File, scope string, body []parser.Visitee) {
+	for _, b := range body {
+		switch e := b.(type) {
+		case *parser.Message:
+			name := join(scope, e.MessageName)
+			l.declare(file, name, SymbolKindMessage, e)
+			l.declareBody(file, name, e.MessageBody)
+		case *parser.GroupField:
+			name := join(scope, e.GroupName)
+			l.declare(file, name, SymbolKindMessage, e)
+			l.declareBody(file, name, e.MessageBody)
+		case *parser.Enum:
+			l.declare(file, join(scope, e.EnumName), SymbolKindEnum, e)
+		case *parser.Service:
+			l.declare(file, join(scope, e.ServiceName), SymbolKindService, e)
+		case *parser.Extend:
+			// The groups in the extend body are declared in the scope enclosing the extend.
+			l.declareBody(file, scope, e.ExtendBody)
+		}
+	}
+}
+
+func (l *linker) declare(file *resolver.File, fullName string, kind SymbolKind, node parser.Visitee) {
+	l.result.Symbols.add(&Symbol{
+		FullName: fullName,
+		Kind:     kind,
+		File:     file,
+		Node:     node,
+	})
+}
+
+func (l *linker) resolveFile(file *resolver.File) {
+	l.file = file
+	l.visible = visibleFiles(l.set, file)
+	l.resolveBody(PackageName(file.Proto), file.Proto.ProtoBody)
+}
+
+// visibleFiles returns the file, the files it imports and the files imported publicly by them transitively.
+func visibleFiles(set *resolver.FileSet, file *resolver.File) map[*resolver.File]bool {
+	visible := map[*resolver.File]bool{
+		file: true,
+	}
+	var addPublic func(f *resolver.File)
+	addPublic = func(f *resolver.File) {
+		for _, imported := range imports(set, f, true) {
+			if !visible[imported] {
+				visible[imported] = true
+				addPublic(imported)
+			}
+		}
+	}
+	for _, imported := range imports(set, file, false) {
+		visible[imported] = true
+		addPublic(imported)
+	}
+	return visible
+}
+
+func imports(set *resolver.FileSet, file *resolver.File, publicOnly bool) []*resolver.File {
+	var files []*resolver.File
+	for _, body := range file.Proto.ProtoBody {
+		i, ok := body.(*parser.Import)
+		if !ok || (publicOnly && i.Modifier != parser.ImportModifierPublic) {
+			continue
+		}
+		if imported, ok := set.Files[resolver.ImportPath(i)]; ok {
+			files = append(files, imported)
+		}
+	}
+	return files
+}
+
+func (l *linker) resolveBody(scope string, body []parser.Visitee) {
+	for _, b := range body {
+		switch e := b.(type) {
+		case *parser.Message:
+			l.resolveBody(join(scope, e.MessageName), e.MessageBody)
+		case *parser.GroupField:
+			l.resolveBody(join(scope, e.GroupName), e.MessageBody)
+		case *parser.Field:
+			l.resolve(e, e.Type, scope, e.Meta.Pos)
+		case *parser.MapField:
+			l.resolve(e, e.Type, scope, e.Meta.Pos)
+		case *parser.Oneof:
+			for _, field := range e.OneofFields {
+				l.resolve(field, field.Type, scope, field.Meta.Pos)
+			}
+		case *parser.Extend:
+			l.resolve(e, e.MessageType, scope, e.Meta.Pos)
+			l.resolveBody(scope, e.ExtendBody)
+		case *parser.Service:
+			serviceScope := join(scope, e.ServiceName)
+			for _, s := range e.ServiceBody {
+				rpc, ok := s.(*parser.RPC)
+				if !ok {
+					continue
+				}
+				if rpc.RPCRequest != nil {
+					l.resolve(rpc.RPCRequest, rpc.RPCRequest.MessageType, serviceScope, rpc.Meta.Pos)
+				}
+				if rpc.RPCResponse != nil {
+					l.resolve(rpc.RPCResponse, rpc.RPCResponse.MessageType, serviceScope, rpc.Meta.Pos)
+				}
+			}
+		}
+	}
+}
+
+// scalarTypes are the types of fields that refer to no declaration.
+var scalarTypes = map[string]bool{
+	"double":   true,
+	"float":    true,
+	"int32":    true,
+	"int64":    true,
+	"uint32":   true,
+	"uint64":   true,
+	"sint32":   true,
+	"sint64":   true,
+	"fixed32":  true,
+	"fixed64":  true,
+	"sfixed32": true,
+	"sfixed64": true,
+	"bool":     true,
+	"string":   true,
+	"bytes":    true,
+}
+
+func (l *linker) resolve(node interface{}, name string, scope string, pos meta.Position) {
+	if scalarTypes[name] {
+		return
+	}
+	ref := &Reference{
+		Name:  name,
+		Scope: scope,
+		Node:  node,
+		File:  l.file,
+	}
+	l.result.References = append(l.result.References, ref)
+	l.result.references[node] = ref
+
+	symbols, detail := l.lookup(name, scope)
+	switch {
+	case len(symbols) == 1:
+		ref.Symbol = symbols[0]
+	case 1 < len(symbols):
+		l.errs = append(l.errs, &ReferenceError{
+			Pos:        pos,
+			Name:       name,
+			Candidates: symbols,
+			Detail:     fmt.Sprintf("%q is declared %d times", symbols[0].FullName, len(symbols)),
+			Err:        ErrAmbiguous,
+		})
+	default:
+		l.errs = append(l.errs, &ReferenceError{
+			Pos:    pos,
+			Name:   name,
+			Detail: detail,
+			Err:    ErrUnresolved,
+		})
+	}
+}
+
+// lookup finds the types the name refers to from the scope.
+// Like protoc, it searches the scope and then its parents for the first component of the name.
+// Once the first component is found, the rest must be declared in it.
+func (l *linker) lookup(name string, scope string) ([]*Symbol, string) {
+	if strings.HasPrefix(name, ".") {
+		if types := l.types(name[1:]); 0 < len(types) {
+			return types, ""
+		}
+		return nil, l.notImported(name[1:])
+	}
+
+	first := name
+	if i := strings.IndexByte(name, '.'); 0 <= i {
+		first = name[:i]
+	}
+	for {
+		candidates := l.visibleSymbols(join(scope, first))
+		if first == name {
+			if types := filterTypes(candidates); 0 < len(types) {
+				return types, ""
+			}
+		} else if 0 < len(candidates) {
+			fullName := join(scope, name)
+			if types := l.types(fullName); 0 < len(types) {
+				return types, ""
+			}
+			if detail := l.notImported(fullName); detail != "" {
+				return nil, detail
+			}
+			return nil, fmt.Sprintf("it is resolved to %q, which is not declared as a type", fullName)
+		}
+
+		if scope == "" {
+			return nil, ""
+		}
+		scope = parent(scope)
+	}
+}
+
+func (l *linker) types(fullName string) []*Symbol {
+	return filterTypes(l.visibleSymbols(fullName))
+}
+
+// notImported describes the type declared with the full name in a file that is not visible, if any.
+func (l *linker) notImported(fullName string) string {
+	types := filterTypes(l.result.Symbols.Lookup(fullName))
+	if len(types) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%q is declared in %q, which is not imported", fullName, types[0].File.Path)
+}
+
+func (l *linker) visibleSymbols(fullName string) []*Symbol {
+	var symbols []*Symbol
+	for _, s := range l.result.Symbols.Lookup(fullName) {
+		if s.Kind == SymbolKindPackage || l.visible[s.File] {
+			symbols = append(symbols, s)
+		}
+	}
+	return symbols
+}
+
+func filterTypes(symbols []*Symbol) []*Symbol {
+	var types []*Symbol
+	for _, s := range symbols {
+		if s.IsType() {
+			types = append(types, s)
+		}
+	}
+	return types
+}
+
+func parent(scope string) string {
+	if i := strings.LastIndexByte(scope, '.'); 0 <= i {
+		return scope[:i]
+	}
+	return ""
+}
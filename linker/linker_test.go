@@ -0,0 +1,280 @@
+package linker_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+func resolve(t *testing.T, files map[string]string, paths ...string) *resolver.FileSet {
+	set, err := resolver.Resolve(
+		paths,
+		resolver.WithFiles(files),
+	)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	return set
+}
+
+func TestLink(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputFiles     map[string]string
+		wantReferences []string
+		wantErrs       []string
+	}{
+		{
+			name: "resolving references in the scopes",
+			inputFiles: map[string]string{
+				"a.proto": `
+syntax = "proto3";
+package foo.bar;
+message Outer {
+  message Inner {
+    Outer outer = 1;
+    Inner inner = 2;
+  }
+  Inner inner = 1;
+  Outer.Inner qualified = 2;
+  .foo.bar.Outer absolute = 3;
+  bar.Outer partial = 4;
+  map<string, Kind> kinds = 5;
+  oneof o {
+    Inner.Kind inner_kind = 6;
+  }
+  enum Kind { KIND_UNSPECIFIED = 0; }
+  string scalar = 7;
+}
+service S {
+  rpc Get (Outer) returns (stream foo.bar.Outer.Inner);
+}
+`,
+			},
+			wantReferences: []string{
+				"Outer in foo.bar.Outer.Inner => foo.bar.Outer",
+				"Inner in foo.bar.Outer.Inner => foo.bar.Outer.Inner",
+				"Inner in foo.bar.Outer => foo.bar.Outer.Inner",
+				"Outer.Inner in foo.bar.Outer => foo.bar.Outer.Inner",
+				".foo.bar.Outer in foo.bar.Outer => foo.bar.Outer",
+				"bar.Outer in foo.bar.Outer => foo.bar.Outer",
+				"Kind in foo.bar.Outer => foo.bar.Outer.Kind",
+				"Inner.Kind in foo.bar.Outer => ",
+				"Outer in foo.bar.S => foo.bar.Outer",
+				"foo.bar.Outer.Inner in foo.bar.S => foo.bar.Outer.Inner",
+			},
+			wantErrs: []string{
+				`<input>:15:5: unresolved reference "Inner.Kind", it is resolved to "foo.bar.Outer.Inner.Kind", which is not declared as a type`,
+			},
+		},
+		{
+			name: "resolving references across files",
+			inputFiles: map[string]string{
+				"a.proto": `
+package foo;
+import "b.proto";
+message A {
+  bar.B b = 1;
+  .bar.B absolute = 2;
+  bar.Public public = 3;
+  bar.Private private = 4;
+  group G = 5 {
+    G g = 1;
+  }
+}
+extend bar.B {
+  A a = 100;
+}
+`,
+				"b.proto": `
+package bar;
+import public "public.proto";
+import "private.proto";
+message B {}
+`,
+				"public.proto": `
+package bar;
+message Public {}
+`,
+				"private.proto": `
+package bar;
+message Private {}
+`,
+			},
+			wantReferences: []string{
+				"bar.B in foo.A => bar.B",
+				".bar.B in foo.A => bar.B",
+				"bar.Public in foo.A => bar.Public",
+				"bar.Private in foo.A => ",
+				"G in foo.A.G => foo.A.G",
+				"bar.B in foo => bar.B",
+				"A in foo => foo.A",
+			},
+			wantErrs: []string{
+				`<input>:8:3: unresolved reference "bar.Private", "bar.Private" is declared in "private.proto", which is not imported`,
+			},
+		},
+		{
+			name: "resolving the groups declared in extend bodies",
+			inputFiles: map[string]string{
+				"a.proto": `
+syntax = "proto2";
+package foo;
+message A {
+  extensions 100 to 200;
+}
+message B {
+  extend A {
+    optional group G = 100 {
+      optional G g = 1;
+    }
+  }
+  optional G g = 1;
+}
+extend A {
+  repeated group H = 101 {}
+}
+message C {
+  optional B.G g = 1;
+  optional H h = 2;
+}
+`,
+			},
+			wantReferences: []string{
+				"A in foo.B => foo.A",
+				"G in foo.B.G => foo.B.G",
+				"G in foo.B => foo.B.G",
+				"A in foo => foo.A",
+				"B.G in foo.C => foo.B.G",
+				"H in foo.C => foo.H",
+			},
+		},
+		{
+			name: "reporting ambiguous references",
+			inputFiles: map[string]string{
+				"a.proto": `
+import "b.proto";
+import "c.proto";
+message A {
+  Dup dup = 1;
+}
+`,
+				"b.proto": `message Dup {}`,
+				"c.proto": `enum Dup { DUP = 0; }`,
+			},
+			wantReferences: []string{
+				"Dup in A => ",
+			},
+			wantErrs: []string{
+				`<input>:5:3: ambiguous reference "Dup", "Dup" is declared 2 times`,
+			},
+		},
+		{
+			name: "skipping non-type symbols",
+			inputFiles: map[string]string{
+				"a.proto": `
+package foo;
+message A {
+  message foo {}
+  foo f = 1;
+}
+service Svc {}
+message B {
+  Svc svc = 1;
+}
+`,
+			},
+			wantReferences: []string{
+				"foo in foo.A => foo.A.foo",
+				"Svc in foo.B => ",
+			},
+			wantErrs: []string{
+				`<input>:9:3: unresolved reference "Svc"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			set := resolve(t, test.inputFiles, "a.proto")
+			got, err := linker.Link(set)
+
+			var gotErrs []string
+			if err != nil {
+				var errs linker.Errors
+				if !errors.As(err, &errs) {
+					t.Fatalf("got err %T, but want linker.Errors", err)
+				}
+				for _, e := range errs {
+					gotErrs = append(gotErrs, strings.Replace(e.Error(), "a.proto", "<input>", 1))
+				}
+			}
+			if !reflect.DeepEqual(gotErrs, test.wantErrs) {
+				t.Errorf("got %v, but want %v", gotErrs, test.wantErrs)
+			}
+
+			var gotReferences []string
+			for _, ref := range got.References {
+				if ref.File.Path != "a.proto" {
+					continue
+				}
+				fullName := ""
+				if ref.Symbol != nil {
+					fullName = ref.Symbol.FullName
+				}
+				gotReferences = append(gotReferences, ref.Name+" in "+ref.Scope+" => "+fullName)
+				if got.ReferenceOf(ref.Node) != ref {
+					t.Errorf("got %v, but want %v", got.ReferenceOf(ref.Node), ref)
+				}
+			}
+			if !reflect.DeepEqual(gotReferences, test.wantReferences) {
+				t.Errorf("got %v, but want %v", gotReferences, test.wantReferences)
+			}
+		})
+	}
+}
+
+func TestSymbolTable(t *testing.T) {
+	set := resolve(t, map[string]string{
+		"a.proto": `
+package foo.bar;
+message A {
+  enum E { E_UNSPECIFIED = 0; }
+}
+`,
+	}, "a.proto")
+	got, err := linker.Link(set)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	for _, test := range []struct {
+		fullName string
+		wantKind linker.SymbolKind
+	}{
+		{fullName: "foo", wantKind: linker.SymbolKindPackage},
+		{fullName: "foo.bar", wantKind: linker.SymbolKindPackage},
+		{fullName: ".foo.bar.A", wantKind: linker.SymbolKindMessage},
+		{fullName: "foo.bar.A.E", wantKind: linker.SymbolKindEnum},
+	} {
+		symbols := got.Symbols.Lookup(test.fullName)
+		if len(symbols) != 1 {
+			t.Errorf("got %v, but want a symbol %s", symbols, test.fullName)
+			continue
+		}
+		if symbols[0].Kind != test.wantKind {
+			t.Errorf("got %v, but want %v", symbols[0].Kind, test.wantKind)
+		}
+	}
+
+	message := set.Files["a.proto"].Proto.ProtoBody[1].(*parser.Message)
+	if got.Symbols.SymbolOf(message).FullName != "foo.bar.A" {
+		t.Errorf("got %v, but want foo.bar.A", got.Symbols.SymbolOf(message))
+	}
+}
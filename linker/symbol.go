@@ -0,0 +1,90 @@
+package linker
+
+import (
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// SymbolKind is the kind of declaration a symbol names.
+type SymbolKind uint
+
+// SymbolKind values.
+const (
+	SymbolKindPackage SymbolKind = iota
+	SymbolKindMessage
+	SymbolKindEnum
+	SymbolKindService
+)
+
+func (k SymbolKind) String() string {
+	switch k {
+	case SymbolKindPackage:
+		return "package"
+	case SymbolKindMessage:
+		return "message"
+	case SymbolKindEnum:
+		return "enum"
+	case SymbolKindService:
+		return "service"
+	}
+	return "unknown"
+}
+
+// Symbol is a declaration named by a fully qualified name.
+type Symbol struct {
+	// FullName is the fully qualified name without the leading dot, like "foo.bar.Message".
+	FullName string
+	Kind     SymbolKind
+	// File is the file declaring the symbol.
+	// For a package, it is the first file declaring the package.
+	File *resolver.File
+	// Node is the declaration, which is either *parser.Package, *parser.Message, *parser.GroupField,
+	// *parser.Enum or *parser.Service.
+	Node parser.Visitee
+}
+
+// IsType reports whether the symbol can be used as the type of a field.
+func (s *Symbol) IsType() bool {
+	return s.Kind == SymbolKindMessage || s.Kind == SymbolKindEnum
+}
+
+// SymbolTable holds the symbols declared in a set of files.
+type SymbolTable struct {
+	symbols map[string][]*Symbol
+	nodes   map[parser.Visitee]*Symbol
+}
+
+func newSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		symbols: make(map[string][]*Symbol),
+		nodes:   make(map[parser.Visitee]*Symbol),
+	}
+}
+
+// Lookup returns the symbols declared with the fully qualified name, with or without the leading dot.
+// It returns more than one symbol if the name is declared more than once.
+func (t *SymbolTable) Lookup(fullName string) []*Symbol {
+	if 0 < len(fullName) && fullName[0] == '.' {
+		fullName = fullName[1:]
+	}
+	return t.symbols[fullName]
+}
+
+// SymbolOf returns the symbol declared by the node, or nil if the node declares nothing.
+func (t *SymbolTable) SymbolOf(node parser.Visitee) *Symbol {
+	return t.nodes[node]
+}
+
+func (t *SymbolTable) add(symbol *Symbol) {
+	if symbol.Kind == SymbolKindPackage {
+		for _, s := range t.symbols[symbol.FullName] {
+			if s.Kind == SymbolKindPackage {
+				return
+			}
+		}
+	}
+	t.symbols[symbol.FullName] = append(t.symbols[symbol.FullName], symbol)
+	if symbol.Node != nil {
+		t.nodes[symbol.Node] = symbol
+	}
+}
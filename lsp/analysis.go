@@ -0,0 +1,218 @@
+package lsp
+
+import (
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/diagnostic"
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+	"github.com/yoheimuta/go-protoparser/v4/validator"
+)
+
+// analysis is the result of analyzing a file along with the files it imports.
+type analysis struct {
+	file  *file
+	diags diagnostic.Diagnostics
+
+	// set and linked are nil if the imports cannot be resolved.
+	set    *resolver.FileSet
+	linked *linker.Result
+	// files are the indexed files of the set keyed by their filenames.
+	files map[string]*file
+}
+
+// analyze parses, resolves, links and validates the file.
+func (s *Server) analyze(filename string) *analysis {
+	if a, ok := s.analyses[filename]; ok {
+		return a
+	}
+	a := &analysis{files: make(map[string]*file)}
+	s.analyses[filename] = a
+
+	text, err := s.read(filename)
+	if err != nil {
+		a.diags = diagnostic.FromError(err)
+		a.file = newFile(filename, "", nil, nil, nil)
+		return a
+	}
+
+	// The file is parsed by itself first to report every syntax error and to provide the symbols
+	// even if it cannot be resolved.
+	// The spans let the validator point at the clashing names and numbers.
+	proto, err := protoparser.Parse(
+		strings.NewReader(text),
+		protoparser.WithFilename(filename),
+		protoparser.WithRecovery(true),
+		protoparser.WithSpans(true),
+	)
+	a.diags = append(a.diags, diagnostic.FromError(err)...)
+	if err == nil {
+		importPath, importPaths := s.importPathOf(filename)
+		set, err := resolver.Resolve(
+			[]string{importPath},
+			resolver.WithImportPaths(importPaths...),
+			resolver.WithAccessor(s.open),
+			resolver.WithParseOptions(protoparser.WithSpans(true)),
+		)
+		// The unresolved imports are reported as the diagnostics, and the resolved files are still linked.
+		a.diags = append(a.diags, diagnostic.FromError(err)...)
+		if root, ok := set.Files[importPath]; ok {
+			a.set = set
+			proto = root.Proto
+			// The unresolved references are reported as the diagnostics, and the rest are still available.
+			a.linked, err = linker.Link(set)
+			a.diags = append(a.diags, diagnostic.FromError(err)...)
+		}
+		a.diags = append(a.diags, validator.Validate(proto)...)
+	}
+
+	a.file = newFile(filename, text, proto, a.set, a.linked)
+	a.files[filename] = a.file
+	return a
+}
+
+// fileOf returns the indexed file of the set, or nil if the file is not in the set.
+func (a *analysis) fileOf(s *Server, filename string) *file {
+	if f, ok := a.files[filename]; ok {
+		return f
+	}
+	if a.set == nil {
+		return nil
+	}
+	for _, f := range a.set.Files {
+		if f.Filename != filename {
+			continue
+		}
+		text, err := s.read(filename)
+		if err != nil {
+			return nil
+		}
+		a.files[filename] = newFile(filename, text, f.Proto, a.set, a.linked)
+		return a.files[filename]
+	}
+	return nil
+}
+
+// read returns the text of the opened document, or the content of the file.
+func (s *Server) read(filename string) (string, error) {
+	if doc, ok := s.docs[filename]; ok {
+		return doc.text, nil
+	}
+	reader, err := s.accessor(filename)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = reader.Close() }()
+	content, err := io.ReadAll(reader)
+	return string(content), err
+}
+
+// open opens the file for the resolver, preferring the opened document.
+func (s *Server) open(filename string) (io.ReadCloser, error) {
+	if doc, ok := s.docs[filepath.Clean(filename)]; ok {
+		return io.NopCloser(strings.NewReader(doc.text)), nil
+	}
+	return s.accessor(filename)
+}
+
+// importPathOf returns the import path of the file and the directories where the files it imports are searched.
+// The file is imported relative to the first root folder or import path containing it,
+// or otherwise to its directory.
+func (s *Server) importPathOf(filename string) (string, []string) {
+	var dirs []string
+	for _, dir := range append(append([]string(nil), s.roots...), s.importPaths...) {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		dirs = append(dirs, dir)
+	}
+	for i, dir := range dirs {
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.ToSlash(rel), append([]string{dir}, append(dirs[:i:i], dirs[i+1:]...)...)
+	}
+	return filepath.Base(filename), append([]string{filepath.Dir(filename)}, dirs...)
+}
+
+// filenameOf returns the filename of the file URI.
+func filenameOf(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	path := u.Path
+	// The URIs of the Windows paths are like file:///C:/dir/file.proto.
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.Clean(filepath.FromSlash(path)), true
+}
+
+// uriOf returns the file URI of the filename.
+func uriOf(filename string) string {
+	path := filepath.ToSlash(filename)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func sortedKeys(docs map[string]*document) []string {
+	var keys []string
+	for key := range docs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diagnostics converts the diagnostics of the analyzed file into the ones of the protocol.
+// The diagnostics of the imported files are reported at the beginning of the file.
+func (s *Server) diagnostics(a *analysis) []Diagnostic {
+	diags := []Diagnostic{}
+	for _, d := range a.diags {
+		diag := Diagnostic{
+			Severity: DiagnosticSeverity(d.Severity),
+			Code:     string(d.Code),
+			Source:   "protoparser",
+			Message:  d.Message,
+		}
+		if d.Range.Start.Filename == a.file.filename && d.Range.Start.Line != 0 {
+			diag.Range = a.file.rangeOf(d.Range)
+		} else if d.Range.Start.Line != 0 {
+			diag.Message = d.Error()
+		}
+		for _, related := range d.Related {
+			f := a.fileOf(s, related.Range.Start.Filename)
+			if f == nil {
+				continue
+			}
+			diag.RelatedInformation = append(diag.RelatedInformation, DiagnosticRelatedInformation{
+				Location: Location{URI: uriOf(f.filename), Range: f.rangeOf(related.Range)},
+				Message:  related.Message,
+			})
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+// rangeOf converts the range of a diagnostic. The range of an unknown length spans the word at the start.
+func (f *file) rangeOf(r diagnostic.Range) Range {
+	start := r.Start.Offset
+	end := r.End.Offset
+	if r.End.Line == 0 {
+		end = start
+		for end < len(f.src.text) && isNameChar(f.src.text[end]) {
+			end++
+		}
+	}
+	return f.src.rangeOf(start, end)
+}
@@ -0,0 +1,381 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// documentSymbol returns the messages, the enums, the services and their members declared in the document.
+func (s *Server) documentSymbol(params *DocumentSymbolParams) []DocumentSymbol {
+	filename, ok := filenameOf(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+	f := s.analyze(filename).file
+	if f.proto == nil {
+		return []DocumentSymbol{}
+	}
+	return f.symbols(f.proto.ProtoBody)
+}
+
+func (f *file) symbols(body []parser.Visitee) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, element := range body {
+		switch e := element.(type) {
+		case *parser.Message:
+			symbol := f.symbol(e, e.MessageName, "", SymbolKindStruct, e.Meta)
+			symbol.Children = f.symbols(e.MessageBody)
+			symbols = append(symbols, symbol)
+		case *parser.Field:
+			symbols = append(symbols, f.symbol(e, e.FieldName, e.Type, SymbolKindField, e.Meta))
+		case *parser.MapField:
+			symbols = append(symbols, f.symbol(e, e.MapName, "map<"+e.KeyType+", "+e.Type+">", SymbolKindField, e.Meta))
+		case *parser.GroupField:
+			symbol := f.symbol(e, e.GroupName, "group", SymbolKindStruct, e.Meta)
+			symbol.Children = f.symbols(e.MessageBody)
+			symbols = append(symbols, symbol)
+		case *parser.Oneof:
+			for _, field := range e.OneofFields {
+				symbols = append(symbols, f.symbol(field, field.FieldName, field.Type, SymbolKindField, field.Meta))
+			}
+		case *parser.Enum:
+			symbol := f.symbol(e, e.EnumName, "", SymbolKindEnum, e.Meta)
+			symbol.Children = f.symbols(e.EnumBody)
+			symbols = append(symbols, symbol)
+		case *parser.EnumField:
+			symbols = append(symbols, f.symbol(e, e.Ident, e.Number, SymbolKindEnumMember, e.Meta))
+		case *parser.Service:
+			symbol := f.symbol(e, e.ServiceName, "", SymbolKindInterface, e.Meta)
+			symbol.Children = f.symbols(e.ServiceBody)
+			symbols = append(symbols, symbol)
+		case *parser.RPC:
+			symbols = append(symbols, f.symbol(e, e.RPCName, rpcSignature(e), SymbolKindMethod, e.Meta))
+		}
+	}
+	return symbols
+}
+
+func (f *file) symbol(node parser.Visitee, name string, detail string, kind SymbolKind, m meta.Meta) DocumentSymbol {
+	r := f.metaRange(m)
+	selection := Range{Start: r.Start, End: r.Start}
+	if o := f.declOf(node); o != nil {
+		selection = f.src.rangeOf(o.start, o.end)
+	}
+	return DocumentSymbol{
+		Name:           name,
+		Detail:         detail,
+		Kind:           kind,
+		Range:          r,
+		SelectionRange: selection,
+	}
+}
+
+// metaRange returns the range of the element from its first token to its last one.
+func (f *file) metaRange(m meta.Meta) Range {
+	end := m.Pos.Offset
+	if m.Pos.Offset < m.LastPos.Offset {
+		end = m.LastPos.Offset
+	}
+	if end < len(f.src.text) {
+		end++
+	}
+	return f.src.rangeOf(m.Pos.Offset, end)
+}
+
+func rpcSignature(rpc *parser.RPC) string {
+	stream := func(isStream bool) string {
+		if isStream {
+			return "stream "
+		}
+		return ""
+	}
+	var request, response string
+	if rpc.RPCRequest != nil {
+		request = stream(rpc.RPCRequest.IsStream) + rpc.RPCRequest.MessageType
+	}
+	if rpc.RPCResponse != nil {
+		response = stream(rpc.RPCResponse.IsStream) + rpc.RPCResponse.MessageType
+	}
+	return fmt.Sprintf("(%s) returns (%s)", request, response)
+}
+
+// lookup returns the analysis of the document and the occurrence at the position, or nil.
+func (s *Server) lookup(params *TextDocumentPositionParams) (*analysis, *occurrence) {
+	filename, ok := filenameOf(params.TextDocument.URI)
+	if !ok {
+		return nil, nil
+	}
+	a := s.analyze(filename)
+	return a, a.file.occurrenceAt(a.file.src.offset(params.Position))
+}
+
+// target returns the declaration the occurrence names or refers to, along with the file declaring it.
+func (s *Server) target(a *analysis, o *occurrence) (*file, *occurrence) {
+	if o.decl != nil {
+		return a.file, o
+	}
+	if o.ref == nil || o.ref.Symbol == nil || o.ref.Symbol.File == nil {
+		return nil, nil
+	}
+	f := a.fileOf(s, o.ref.Symbol.File.Filename)
+	if f == nil {
+		return nil, nil
+	}
+	decl := f.declOf(o.ref.Symbol.Node)
+	if decl == nil {
+		return nil, nil
+	}
+	return f, decl
+}
+
+// hover shows the declaration at the position, or the one referred to at the position,
+// along with its leading comments.
+func (s *Server) hover(params *TextDocumentPositionParams) *Hover {
+	a, o := s.lookup(params)
+	if o == nil {
+		return nil
+	}
+	_, decl := s.target(a, o)
+	if decl == nil {
+		return nil
+	}
+
+	value := "```proto\n" + declaration(decl) + "\n```"
+	if comments := leadingComments(decl.decl); comments != "" {
+		value += "\n\n" + comments
+	}
+	r := a.file.src.rangeOf(o.start, o.end)
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: value},
+		Range:    &r,
+	}
+}
+
+// declaration returns the summary of the declaration like the one written in the file.
+func declaration(o *occurrence) string {
+	switch n := o.decl.(type) {
+	case *parser.Message:
+		return "message " + o.fullName
+	case *parser.Enum:
+		return "enum " + o.fullName
+	case *parser.Service:
+		return "service " + o.fullName
+	case *parser.RPC:
+		return "rpc " + n.RPCName + rpcSignature(n)
+	case *parser.Field:
+		label := ""
+		switch {
+		case n.IsRepeated:
+			label = "repeated "
+		case n.IsRequired:
+			label = "required "
+		case n.IsOptional:
+			label = "optional "
+		}
+		return fmt.Sprintf("%s%s %s = %s", label, n.Type, n.FieldName, n.FieldNumber)
+	case *parser.MapField:
+		return fmt.Sprintf("map<%s, %s> %s = %s", n.KeyType, n.Type, n.MapName, n.FieldNumber)
+	case *parser.GroupField:
+		return fmt.Sprintf("group %s = %s", o.fullName, n.FieldNumber)
+	case *parser.OneofField:
+		return fmt.Sprintf("%s %s = %s", n.Type, n.FieldName, n.FieldNumber)
+	case *parser.Oneof:
+		return "oneof " + n.OneofName
+	case *parser.EnumField:
+		return fmt.Sprintf("%s = %s", n.Ident, n.Number)
+	}
+	return o.fullName
+}
+
+func leadingComments(node parser.Visitee) string {
+	var comments []*parser.Comment
+	switch n := node.(type) {
+	case *parser.Message:
+		comments = n.Comments
+	case *parser.Enum:
+		comments = n.Comments
+	case *parser.Service:
+		comments = n.Comments
+	case *parser.RPC:
+		comments = n.Comments
+	case *parser.Field:
+		comments = n.Comments
+	case *parser.MapField:
+		comments = n.Comments
+	case *parser.GroupField:
+		comments = n.Comments
+	case *parser.OneofField:
+		comments = n.Comments
+	case *parser.Oneof:
+		comments = n.Comments
+	case *parser.EnumField:
+		comments = n.Comments
+	}
+
+	var lines []string
+	for _, comment := range comments {
+		for _, line := range comment.Lines() {
+			lines = append(lines, strings.TrimPrefix(strings.TrimRight(line, " \t\r"), " "))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// definition returns the declaration of the type referred to at the position,
+// or the file imported at the position.
+func (s *Server) definition(params *TextDocumentPositionParams) []Location {
+	a, o := s.lookup(params)
+	if o == nil {
+		return []Location{}
+	}
+	if o.imported != "" {
+		return []Location{{URI: uriOf(o.imported)}}
+	}
+	f, decl := s.target(a, o)
+	if decl == nil {
+		return []Location{}
+	}
+	return []Location{{URI: uriOf(f.filename), Range: f.src.rangeOf(decl.start, decl.end)}}
+}
+
+// references returns the references to the type declared or referred to at the position
+// in the opened documents and the files in the root folders of the workspace.
+func (s *Server) references(params *ReferenceParams) []Location {
+	a, o := s.lookup(&params.TextDocumentPositionParams)
+	locations := []Location{}
+	if o == nil {
+		return locations
+	}
+	f, decl := s.target(a, o)
+	if decl == nil {
+		return locations
+	}
+	if params.Context.IncludeDeclaration {
+		locations = append(locations, Location{URI: uriOf(f.filename), Range: f.src.rangeOf(decl.start, decl.end)})
+	}
+
+	for _, filename := range s.workspaceFiles() {
+		other := s.analyze(filename)
+		for _, ref := range other.file.occurrences {
+			if ref.ref == nil || ref.ref.Symbol == nil || ref.ref.Symbol.File == nil {
+				continue
+			}
+			if ref.ref.Symbol.FullName != decl.fullName || ref.ref.Symbol.File.Filename != f.filename {
+				continue
+			}
+			locations = append(locations, Location{
+				URI:   uriOf(filename),
+				Range: other.file.src.rangeOf(ref.start, ref.end),
+			})
+		}
+	}
+	return locations
+}
+
+// workspaceFiles returns the filenames of the opened documents and the .proto files in the root folders.
+func (s *Server) workspaceFiles() []string {
+	seen := make(map[string]bool)
+	var filenames []string
+	add := func(filename string) {
+		if !seen[filename] {
+			seen[filename] = true
+			filenames = append(filenames, filename)
+		}
+	}
+	for _, filename := range sortedKeys(s.docs) {
+		add(filename)
+	}
+	for _, root := range s.roots {
+		_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() && path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if !info.IsDir() && strings.HasSuffix(path, ".proto") {
+				add(filepath.Clean(path))
+			}
+			return nil
+		})
+	}
+	return filenames
+}
+
+// foldingRange returns the ranges of the elements and the comments spanning several lines.
+func (s *Server) foldingRange(params *FoldingRangeParams) []FoldingRange {
+	filename, ok := filenameOf(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+	f := s.analyze(filename).file
+	ranges := []FoldingRange{}
+	if f.proto == nil {
+		return ranges
+	}
+	add := func(start, end meta.Position, kind string) {
+		startLine := f.src.position(start.Offset).Line
+		endLine := f.src.position(end.Offset).Line
+		if startLine < endLine {
+			ranges = append(ranges, FoldingRange{StartLine: startLine, EndLine: endLine, Kind: kind})
+		}
+	}
+	comments := func(comments []*parser.Comment) {
+		if 0 < len(comments) {
+			add(comments[0].Meta.Pos, comments[len(comments)-1].Meta.LastPos, "comment")
+		}
+	}
+
+	var body func([]parser.Visitee)
+	body = func(elements []parser.Visitee) {
+		for _, element := range elements {
+			switch e := element.(type) {
+			case *parser.Comment:
+				add(e.Meta.Pos, e.Meta.LastPos, "comment")
+			case *parser.Option:
+				comments(e.Comments)
+				add(e.Meta.Pos, e.Meta.LastPos, "")
+			case *parser.Message:
+				comments(e.Comments)
+				add(e.Meta.Pos, e.Meta.LastPos, "")
+				body(e.MessageBody)
+			case *parser.Field:
+				comments(e.Comments)
+			case *parser.GroupField:
+				comments(e.Comments)
+				add(e.Meta.Pos, e.Meta.LastPos, "")
+				body(e.MessageBody)
+			case *parser.Oneof:
+				comments(e.Comments)
+				add(e.Meta.Pos, e.Meta.LastPos, "")
+				for _, field := range e.OneofFields {
+					comments(field.Comments)
+				}
+			case *parser.Extend:
+				comments(e.Comments)
+				add(e.Meta.Pos, e.Meta.LastPos, "")
+				body(e.ExtendBody)
+			case *parser.Enum:
+				comments(e.Comments)
+				add(e.Meta.Pos, e.Meta.LastPos, "")
+				body(e.EnumBody)
+			case *parser.EnumField:
+				comments(e.Comments)
+			case *parser.Service:
+				comments(e.Comments)
+				add(e.Meta.Pos, e.Meta.LastPos, "")
+				body(e.ServiceBody)
+			case *parser.RPC:
+				comments(e.Comments)
+				add(e.Meta.Pos, e.Meta.LastPos, "")
+			}
+		}
+	}
+	body(f.proto.ProtoBody)
+	return ranges
+}
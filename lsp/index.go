@@ -0,0 +1,221 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/linker"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// occurrence is a name written in a file, which is either the name of a declaration,
+// a type reference, or the location of an import statement.
+type occurrence struct {
+	// start and end are the byte offsets of the name.
+	start int
+	end   int
+
+	// decl is the node declaring the name, or nil.
+	decl parser.Visitee
+	// fullName is the fully qualified name of decl.
+	fullName string
+	// ref is the type reference, or nil.
+	ref *linker.Reference
+	// imported is the filename of the file imported at the location, or "".
+	imported string
+}
+
+// file is an analyzed file.
+type file struct {
+	filename    string
+	src         *source
+	proto       *parser.Proto
+	occurrences []*occurrence
+}
+
+// newFile indexes the names written in the proto.
+// linked and set may be nil if the file is not linked.
+func newFile(filename string, text string, proto *parser.Proto, set *resolver.FileSet, linked *linker.Result) *file {
+	f := &file{
+		filename: filename,
+		src:      newSource(text),
+		proto:    proto,
+	}
+	if proto == nil {
+		return f
+	}
+	x := &indexer{file: f, linked: linked}
+	scope := linker.PackageName(proto)
+	for _, element := range proto.ProtoBody {
+		if i, ok := element.(*parser.Import); ok && set != nil {
+			if imported, ok := set.Files[resolver.ImportPath(i)]; ok {
+				if start, end := x.find(i.Meta, i.Location, i.Meta.Pos.Offset); 0 <= start {
+					x.add(&occurrence{start: start, end: end, imported: imported.Filename})
+				}
+			}
+		}
+	}
+	x.body(scope, proto.ProtoBody)
+	return f
+}
+
+// occurrenceAt returns the occurrence enclosing the byte offset, or nil.
+func (f *file) occurrenceAt(offset int) *occurrence {
+	for _, o := range f.occurrences {
+		if o.start <= offset && offset <= o.end {
+			return o
+		}
+	}
+	return nil
+}
+
+// declOf returns the occurrence of the name declared by the node, or nil.
+func (f *file) declOf(node parser.Visitee) *occurrence {
+	for _, o := range f.occurrences {
+		if o.decl == node {
+			return o
+		}
+	}
+	return nil
+}
+
+type indexer struct {
+	file   *file
+	linked *linker.Result
+}
+
+func (x *indexer) add(o *occurrence) {
+	x.file.occurrences = append(x.file.occurrences, o)
+}
+
+func (x *indexer) decl(node parser.Visitee, fullName string, m meta.Meta, name string, from int) int {
+	start, end := x.find(m, name, from)
+	if start < 0 {
+		return from
+	}
+	x.add(&occurrence{start: start, end: end, decl: node, fullName: fullName})
+	return end
+}
+
+func (x *indexer) ref(node interface{}, m meta.Meta, name string, from int) int {
+	start, end := x.find(m, name, from)
+	if start < 0 {
+		return from
+	}
+	o := &occurrence{start: start, end: end}
+	if x.linked != nil {
+		o.ref = x.linked.ReferenceOf(node)
+	}
+	if o.ref != nil {
+		x.add(o)
+	}
+	return end
+}
+
+// find returns the byte offsets of the first name written as a whole word within the element
+// at or after the offset from, or -1 if it is not found.
+func (x *indexer) find(m meta.Meta, name string, from int) (int, int) {
+	text := x.file.src.text
+	limit := len(text)
+	if m.Pos.Offset < m.LastPos.Offset && m.LastPos.Offset < limit {
+		limit = m.LastPos.Offset + 1
+	}
+	if name == "" || from < 0 || limit < from {
+		return -1, -1
+	}
+	for i := from; i+len(name) <= limit; {
+		j := strings.Index(text[i:limit], name)
+		if j < 0 {
+			return -1, -1
+		}
+		start, end := i+j, i+j+len(name)
+		if (start == 0 || !isNameChar(text[start-1])) && (end == len(text) || !isNameChar(text[end])) {
+			return start, end
+		}
+		i = start + 1
+	}
+	return -1, -1
+}
+
+// findByte returns the offset just after the first c at or after the offset from, or from if it is not found.
+func (x *indexer) findByte(c byte, from int) int {
+	if len(x.file.src.text) < from {
+		return from
+	}
+	if i := strings.IndexByte(x.file.src.text[from:], c); 0 <= i {
+		return from + i + 1
+	}
+	return from
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '.' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func (x *indexer) body(scope string, body []parser.Visitee) {
+	for _, element := range body {
+		switch e := element.(type) {
+		case *parser.Message:
+			fullName := join(scope, e.MessageName)
+			x.decl(e, fullName, e.Meta, e.MessageName, e.Meta.Pos.Offset+len("message"))
+			x.body(fullName, e.MessageBody)
+		case *parser.Field:
+			end := x.ref(e, e.Meta, e.Type, e.Meta.Pos.Offset)
+			x.decl(e, join(scope, e.FieldName), e.Meta, e.FieldName, end)
+		case *parser.MapField:
+			end := x.ref(e, e.Meta, e.Type, x.findByte(',', e.Meta.Pos.Offset))
+			x.decl(e, join(scope, e.MapName), e.Meta, e.MapName, x.findByte('>', end))
+		case *parser.GroupField:
+			fullName := join(scope, e.GroupName)
+			x.decl(e, fullName, e.Meta, e.GroupName, e.Meta.Pos.Offset)
+			x.body(fullName, e.MessageBody)
+		case *parser.Oneof:
+			x.decl(e, join(scope, e.OneofName), e.Meta, e.OneofName, e.Meta.Pos.Offset+len("oneof"))
+			for _, field := range e.OneofFields {
+				end := x.ref(field, field.Meta, field.Type, field.Meta.Pos.Offset)
+				x.decl(field, join(scope, field.FieldName), field.Meta, field.FieldName, end)
+			}
+		case *parser.Extend:
+			x.ref(e, e.Meta, e.MessageType, e.Meta.Pos.Offset+len("extend"))
+			x.body(scope, e.ExtendBody)
+		case *parser.Enum:
+			fullName := join(scope, e.EnumName)
+			x.decl(e, fullName, e.Meta, e.EnumName, e.Meta.Pos.Offset+len("enum"))
+			for _, b := range e.EnumBody {
+				if value, ok := b.(*parser.EnumField); ok {
+					// The enum values are the siblings of their enum.
+					x.decl(value, join(scope, value.Ident), value.Meta, value.Ident, value.Meta.Pos.Offset)
+				}
+			}
+		case *parser.Service:
+			fullName := join(scope, e.ServiceName)
+			x.decl(e, fullName, e.Meta, e.ServiceName, e.Meta.Pos.Offset+len("service"))
+			for _, b := range e.ServiceBody {
+				if rpc, ok := b.(*parser.RPC); ok {
+					x.rpc(fullName, rpc)
+				}
+			}
+		}
+	}
+}
+
+func (x *indexer) rpc(scope string, rpc *parser.RPC) {
+	end := x.decl(rpc, join(scope, rpc.RPCName), rpc.Meta, rpc.RPCName, rpc.Meta.Pos.Offset+len("rpc"))
+	if rpc.RPCRequest != nil {
+		end = x.ref(rpc.RPCRequest, rpc.Meta, rpc.RPCRequest.MessageType, x.findByte('(', end))
+	}
+	if rpc.RPCResponse != nil {
+		if _, returns := x.find(rpc.Meta, "returns", end); 0 <= returns {
+			end = returns
+		}
+		x.ref(rpc.RPCResponse, rpc.Meta, rpc.RPCResponse.MessageType, x.findByte('(', end))
+	}
+}
@@ -0,0 +1,286 @@
+// Package jsonrpc2 implements the JSON-RPC 2.0 connection framed by the Content-Length headers,
+// which the Language Server Protocol uses over stdio.
+//
+// The same Conn serves as a server and as a client, so that a server can be tested with
+// an in-process client connected through pipes.
+package jsonrpc2
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Error codes defined by JSON-RPC 2.0 and the Language Server Protocol.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// ErrClosed is returned by Call when the connection is closed before the response arrives.
+var ErrClosed = errors.New("jsonrpc2: connection closed")
+
+// Error is the error object of a response.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc2: code %d: %s", e.Code, e.Message)
+}
+
+// Errorf returns an Error with the code and the formatted message.
+func Errorf(code int, format string, a ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// Request is a request or a notification received by a Conn.
+type Request struct {
+	Method string
+	Params json.RawMessage
+	// ID is the raw id of the request, or nil if it is a notification.
+	ID json.RawMessage
+}
+
+// IsNotification reports whether the request expects no response.
+func (r *Request) IsNotification() bool {
+	return r.ID == nil
+}
+
+// Handler handles the requests and the notifications received by a Conn.
+// The returned result or error is sent back unless the request is a notification.
+// The requests are handled one by one in the order they are received.
+type Handler func(ctx context.Context, conn *Conn, req *Request) (result interface{}, err error)
+
+// message is the wire format of every message.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      json.RawMessage  `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *Error           `json:"error,omitempty"`
+}
+
+type response struct {
+	result json.RawMessage
+	err    error
+}
+
+// Conn is a JSON-RPC 2.0 connection.
+type Conn struct {
+	r       *bufio.Reader
+	w       io.Writer
+	handler Handler
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[string]chan *response
+	closed  bool
+}
+
+// NewConn returns a connection reading the messages from r and writing them to w.
+// handler may be nil if the connection receives no request, like a client.
+func NewConn(r io.Reader, w io.Writer, handler Handler) *Conn {
+	return &Conn{
+		r:       bufio.NewReader(r),
+		w:       w,
+		handler: handler,
+		pending: make(map[string]chan *response),
+	}
+}
+
+// Run reads and dispatches the messages until r reaches EOF or ctx is canceled.
+// It returns nil at EOF.
+func (c *Conn) Run(ctx context.Context) error {
+	defer c.close()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := c.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if err := c.write(&message{ID: json.RawMessage("null"), Error: Errorf(CodeParseError, "%v", err)}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "" {
+			c.deliver(&msg)
+			continue
+		}
+		if err := c.dispatch(ctx, &msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Conn) dispatch(ctx context.Context, msg *message) error {
+	req := &Request{
+		Method: msg.Method,
+		Params: msg.Params,
+		ID:     msg.ID,
+	}
+	var result interface{}
+	var err error
+	if c.handler == nil {
+		err = Errorf(CodeMethodNotFound, "method %q is not found", req.Method)
+	} else {
+		result, err = c.handler(ctx, c, req)
+	}
+	if req.IsNotification() {
+		return nil
+	}
+
+	reply := &message{ID: req.ID}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = Errorf(CodeInternalError, "%v", err)
+		}
+		reply.Error = rpcErr
+	} else {
+		data, err := json.Marshal(result)
+		if err != nil {
+			reply.Error = Errorf(CodeInternalError, "%v", err)
+		} else {
+			raw := json.RawMessage(data)
+			reply.Result = &raw
+		}
+	}
+	return c.write(reply)
+}
+
+func (c *Conn) deliver(msg *message) {
+	c.mu.Lock()
+	ch, ok := c.pending[string(msg.ID)]
+	delete(c.pending, string(msg.ID))
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	resp := &response{}
+	switch {
+	case msg.Error != nil:
+		resp.err = msg.Error
+	case msg.Result != nil:
+		resp.result = *msg.Result
+	}
+	ch <- resp
+}
+
+func (c *Conn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for id, ch := range c.pending {
+		ch <- &response{err: ErrClosed}
+		delete(c.pending, id)
+	}
+}
+
+// Call sends the request and waits for the response, which is decoded into result unless it is nil.
+// Run must be running to receive the response.
+func (c *Conn) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	c.nextID++
+	id := json.RawMessage(strconv.FormatInt(c.nextID, 10))
+	ch := make(chan *response, 1)
+	c.pending[string(id)] = ch
+	c.mu.Unlock()
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	if err := c.write(&message{ID: id, Method: method, Params: data}); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.err != nil {
+			return resp.err
+		}
+		if result == nil || resp.result == nil {
+			return nil
+		}
+		return json.Unmarshal(resp.result, result)
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, string(id))
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Notify sends the notification.
+func (c *Conn) Notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(&message{Method: method, Params: data})
+}
+
+func (c *Conn) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(data)
+	return err
+}
+
+// read reads the content of the next message.
+func (c *Conn) read() ([]byte, error) {
+	header, err := textproto.NewReader(c.r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("jsonrpc2: failed to read the header, err %w", err)
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil {
+		return nil, fmt.Errorf("jsonrpc2: invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return nil, fmt.Errorf("jsonrpc2: failed to read the content, err %w", err)
+	}
+	return data, nil
+}
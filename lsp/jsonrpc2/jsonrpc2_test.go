@@ -0,0 +1,95 @@
+package jsonrpc2_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lsp/jsonrpc2"
+)
+
+func connect(t *testing.T, handler jsonrpc2.Handler) *jsonrpc2.Conn {
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		_ = clientWriter.Close()
+		_ = serverWriter.Close()
+	})
+
+	server := jsonrpc2.NewConn(serverReader, serverWriter, handler)
+	go func() { _ = server.Run(ctx) }()
+	client := jsonrpc2.NewConn(clientReader, clientWriter, nil)
+	go func() { _ = client.Run(ctx) }()
+	return client
+}
+
+func TestConn_Call(t *testing.T) {
+	notified := make(chan string, 1)
+	client := connect(t, func(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		switch req.Method {
+		case "echo":
+			var s string
+			if err := json.Unmarshal(req.Params, &s); err != nil {
+				return nil, err
+			}
+			return s, nil
+		case "nothing":
+			return nil, nil
+		case "notify":
+			notified <- req.Method
+			return nil, nil
+		}
+		return nil, jsonrpc2.Errorf(jsonrpc2.CodeMethodNotFound, "method %q is not found", req.Method)
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		wantResult string
+		wantCode   int
+	}{
+		{
+			name:       "result",
+			method:     "echo",
+			wantResult: "hello",
+		},
+		{
+			name:   "null result",
+			method: "nothing",
+		},
+		{
+			name:     "error",
+			method:   "unknown",
+			wantCode: jsonrpc2.CodeMethodNotFound,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+			err := client.Call(context.Background(), test.method, "hello", &got)
+			var rpcErr *jsonrpc2.Error
+			switch {
+			case test.wantCode != 0:
+				if !errors.As(err, &rpcErr) || rpcErr.Code != test.wantCode {
+					t.Errorf("got err %v, but want code %d", err, test.wantCode)
+				}
+			case err != nil:
+				t.Errorf("got err %v, but want nil", err)
+			case got != test.wantResult:
+				t.Errorf("got %q, but want %q", got, test.wantResult)
+			}
+		})
+	}
+
+	if err := client.Notify("notify", nil); err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if got := <-notified; got != "notify" {
+		t.Errorf("got %q, but want %q", got, "notify")
+	}
+}
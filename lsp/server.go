@@ -0,0 +1,267 @@
+// Package lsp implements a Language Server Protocol server for Protocol Buffer files.
+//
+// The server provides the diagnostics of the parser, the resolver, the linker and the validator,
+// the document symbols, the hover showing the leading comments of the declarations,
+// the go-to-definition and the find-references of the type references across the imports,
+// and the folding ranges. It synchronizes the whole text of each document.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/yoheimuta/go-protoparser/v4/lsp/jsonrpc2"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// Option is an option for NewServer.
+type Option func(*Server)
+
+// WithImportPaths is an option to set the directories where the imported files are searched
+// in addition to the root folders of the workspace.
+func WithImportPaths(importPaths ...string) Option {
+	return func(s *Server) {
+		s.importPaths = importPaths
+	}
+}
+
+// WithAccessor is an option to set the function to open the files which are not opened by the client.
+// The default is os.Open.
+func WithAccessor(accessor resolver.FileAccessor) Option {
+	return func(s *Server) {
+		s.accessor = accessor
+	}
+}
+
+// Server is a Language Server Protocol server.
+type Server struct {
+	importPaths []string
+	accessor    resolver.FileAccessor
+
+	// roots are the root folders of the workspace.
+	roots []string
+	// docs are the documents opened by the client keyed by their filenames.
+	docs map[string]*document
+	// analyses are the analyzed files keyed by their filenames. They are discarded when any document changes.
+	analyses map[string]*analysis
+
+	conn *jsonrpc2.Conn
+	exit context.CancelFunc
+}
+
+type document struct {
+	uri     string
+	version int
+	text    string
+}
+
+// NewServer creates a new Server.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		accessor: func(filename string) (io.ReadCloser, error) {
+			return os.Open(filename)
+		},
+		docs:     make(map[string]*document),
+		analyses: make(map[string]*analysis),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Serve serves the client which sends the messages to r and receives the ones from w,
+// like the standard input and output.
+// It returns nil when the client sends the exit notification or closes r.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.exit = cancel
+	s.conn = jsonrpc2.NewConn(r, w, s.handle)
+
+	err := s.conn.Run(ctx)
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(&params), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		return nil, nil
+	case "exit":
+		s.exit()
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didOpen(&params)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didChange(&params)
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didSave(&params)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didClose(&params)
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return s.documentSymbol(&params), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(&params), nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(&params), nil
+	case "textDocument/references":
+		var params ReferenceParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return s.references(&params), nil
+	case "textDocument/foldingRange":
+		var params FoldingRangeParams
+		if err := unmarshal(req, &params); err != nil {
+			return nil, err
+		}
+		return s.foldingRange(&params), nil
+	}
+	if req.IsNotification() {
+		// The unknown notifications, like $/cancelRequest, are ignored.
+		return nil, nil
+	}
+	return nil, jsonrpc2.Errorf(jsonrpc2.CodeMethodNotFound, "method %q is not supported", req.Method)
+}
+
+func unmarshal(req *jsonrpc2.Request, params interface{}) error {
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		return jsonrpc2.Errorf(jsonrpc2.CodeInvalidParams, "invalid params of %s: %v", req.Method, err)
+	}
+	return nil
+}
+
+func (s *Server) initialize(params *InitializeParams) *InitializeResult {
+	for _, folder := range params.WorkspaceFolders {
+		if filename, ok := filenameOf(folder.URI); ok {
+			s.roots = append(s.roots, filename)
+		}
+	}
+	if len(s.roots) == 0 && params.RootURI != "" {
+		if filename, ok := filenameOf(params.RootURI); ok {
+			s.roots = append(s.roots, filename)
+		}
+	}
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			PositionEncoding: "utf-16",
+			TextDocumentSync: TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    TextDocumentSyncKindFull,
+				Save:      true,
+			},
+			DocumentSymbolProvider: true,
+			HoverProvider:          true,
+			DefinitionProvider:     true,
+			ReferencesProvider:     true,
+			FoldingRangeProvider:   true,
+		},
+		ServerInfo: &ServerInfo{Name: "protolsp"},
+	}
+}
+
+func (s *Server) didOpen(params *DidOpenTextDocumentParams) error {
+	filename, ok := filenameOf(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+	s.docs[filename] = &document{
+		uri:     params.TextDocument.URI,
+		version: params.TextDocument.Version,
+		text:    params.TextDocument.Text,
+	}
+	return s.changed()
+}
+
+func (s *Server) didChange(params *DidChangeTextDocumentParams) error {
+	filename, ok := filenameOf(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+	doc, ok := s.docs[filename]
+	if !ok || len(params.ContentChanges) == 0 {
+		return nil
+	}
+	doc.version = params.TextDocument.Version
+	doc.text = params.ContentChanges[len(params.ContentChanges)-1].Text
+	return s.changed()
+}
+
+func (s *Server) didSave(*DidSaveTextDocumentParams) error {
+	// The other files may be changed along with the saved one.
+	return s.changed()
+}
+
+func (s *Server) didClose(params *DidCloseTextDocumentParams) error {
+	filename, ok := filenameOf(params.TextDocument.URI)
+	if !ok {
+		return nil
+	}
+	delete(s.docs, filename)
+	if err := s.conn.Notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         params.TextDocument.URI,
+		Diagnostics: []Diagnostic{},
+	}); err != nil {
+		return err
+	}
+	return s.changed()
+}
+
+// changed discards the analyzed files and publishes the diagnostics of every opened document,
+// since a change of a document can affect the ones importing it.
+func (s *Server) changed() error {
+	s.analyses = make(map[string]*analysis)
+	for _, filename := range sortedKeys(s.docs) {
+		doc := s.docs[filename]
+		if err := s.conn.Notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         doc.uri,
+			Version:     doc.version,
+			Diagnostics: s.diagnostics(s.analyze(filename)),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+package lsp_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoheimuta/go-protoparser/v4/lsp"
+	"github.com/yoheimuta/go-protoparser/v4/lsp/jsonrpc2"
+)
+
+// client is an in-process client connected to a server through pipes.
+type client struct {
+	t    *testing.T
+	conn *jsonrpc2.Conn
+	root string
+
+	diagnostics chan *lsp.PublishDiagnosticsParams
+}
+
+func newClient(t *testing.T, files map[string]string) *client {
+	root := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- lsp.NewServer().Serve(ctx, serverReader, serverWriter)
+		_ = serverWriter.Close()
+	}()
+
+	c := &client{
+		t:           t,
+		root:        root,
+		diagnostics: make(chan *lsp.PublishDiagnosticsParams, 100),
+	}
+	c.conn = jsonrpc2.NewConn(clientReader, clientWriter, func(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+		if req.Method == "textDocument/publishDiagnostics" {
+			var params lsp.PublishDiagnosticsParams
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, err
+			}
+			c.diagnostics <- &params
+		}
+		return nil, nil
+	})
+	go func() { _ = c.conn.Run(ctx) }()
+	t.Cleanup(func() {
+		_ = c.conn.Notify("exit", nil)
+		if err := <-served; err != nil {
+			t.Errorf("got err %v, but want nil", err)
+		}
+		cancel()
+		_ = clientWriter.Close()
+	})
+
+	var result lsp.InitializeResult
+	c.call("initialize", &lsp.InitializeParams{RootURI: c.uri("")}, &result)
+	if !result.Capabilities.DefinitionProvider {
+		t.Fatalf("got %v, but want the definition provider", result.Capabilities)
+	}
+	if err := c.conn.Notify("initialized", struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func (c *client) uri(name string) string {
+	path := filepath.ToSlash(filepath.Join(c.root, filepath.FromSlash(name)))
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return "file://" + path
+}
+
+func (c *client) call(method string, params interface{}, result interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.conn.Call(ctx, method, params, result); err != nil {
+		c.t.Fatalf("got err %v, but want nil", err)
+	}
+}
+
+// open opens the file and returns the diagnostics published for it.
+func (c *client) open(name string) []lsp.Diagnostic {
+	content, err := os.ReadFile(filepath.Join(c.root, filepath.FromSlash(name)))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return c.change(name, string(content), "textDocument/didOpen")
+}
+
+func (c *client) change(name string, text string, method string) []lsp.Diagnostic {
+	var params interface{}
+	if method == "textDocument/didOpen" {
+		params = &lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{URI: c.uri(name), LanguageID: "proto", Version: 1, Text: text},
+		}
+	} else {
+		params = &lsp.DidChangeTextDocumentParams{
+			TextDocument:   lsp.VersionedTextDocumentIdentifier{URI: c.uri(name), Version: 2},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: text}},
+		}
+	}
+	if err := c.conn.Notify(method, params); err != nil {
+		c.t.Fatal(err)
+	}
+	for {
+		select {
+		case diags := <-c.diagnostics:
+			if diags.URI == c.uri(name) {
+				return diags.Diagnostics
+			}
+		case <-time.After(10 * time.Second):
+			c.t.Fatalf("got no diagnostics of %s", name)
+		}
+	}
+}
+
+func (c *client) position(name string, line, character int) lsp.TextDocumentPositionParams {
+	return lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: c.uri(name)},
+		Position:     lsp.Position{Line: line, Character: character},
+	}
+}
+
+func rangeOf(line, start, end int) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{Line: line, Character: start},
+		End:   lsp.Position{Line: line, Character: end},
+	}
+}
+
+var workspace = map[string]string{
+	"a/a.proto": `syntax = "proto3";
+package a;
+
+// Item is an item.
+// It has a name.
+message Item {
+  string name = 1;
+}
+`,
+	"b.proto": `syntax = "proto3";
+package b;
+
+import "a/a.proto";
+
+/* 💬 */ message List {
+  repeated a.Item items = 1;
+  oneof choice {
+    a.Item first = 2;
+  }
+}
+
+service Lister {
+  rpc Get(List) returns (stream a.Item);
+}
+`,
+}
+
+func TestServer_diagnostics(t *testing.T) {
+	c := newClient(t, workspace)
+	if got := c.open("b.proto"); len(got) != 0 {
+		t.Errorf("got %v, but want no diagnostics", got)
+	}
+
+	got := c.change("b.proto", `syntax = "proto3";
+package b;
+
+message List {
+  Missing m = 1;
+  int32 n = 1;
+}
+`, "textDocument/didChange")
+	var summaries []string
+	for _, diag := range got {
+		summaries = append(summaries, diag.Code)
+	}
+	wantSummaries := []string{"unresolved-reference", "duplicate-field-number"}
+	if !reflect.DeepEqual(summaries, wantSummaries) {
+		t.Errorf("got %v, but want %v", summaries, wantSummaries)
+	}
+	if got[0].Range != rangeOf(4, 2, 9) {
+		t.Errorf("got %v, but want %v", got[0].Range, rangeOf(4, 2, 9))
+	}
+
+	got = c.change("b.proto", `syntax = "proto3";
+message {
+`, "textDocument/didChange")
+	if len(got) == 0 || got[0].Severity != lsp.DiagnosticSeverityError || got[0].Range.Start.Line != 1 {
+		t.Errorf("got %v, but want a syntax error at the line 1", got)
+	}
+}
+
+func TestServer_documentSymbol(t *testing.T) {
+	c := newClient(t, workspace)
+	c.open("b.proto")
+
+	var got []lsp.DocumentSymbol
+	c.call("textDocument/documentSymbol", &lsp.DocumentSymbolParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: c.uri("b.proto")},
+	}, &got)
+
+	var summarize func(symbols []lsp.DocumentSymbol, indent string) []string
+	summarize = func(symbols []lsp.DocumentSymbol, indent string) []string {
+		var lines []string
+		for _, s := range symbols {
+			lines = append(lines, indent+s.Name+" "+s.Detail)
+			lines = append(lines, summarize(s.Children, indent+"  ")...)
+		}
+		return lines
+	}
+	want := []string{
+		"List ",
+		"  items a.Item",
+		"  first a.Item",
+		"Lister ",
+		"  Get (List) returns (stream a.Item)",
+	}
+	if lines := summarize(got, ""); !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %v, but want %v", lines, want)
+	}
+	// The column of the message name counts the emoji as two UTF-16 code units.
+	if got[0].SelectionRange != rangeOf(5, 17, 21) {
+		t.Errorf("got %v, but want %v", got[0].SelectionRange, rangeOf(5, 17, 21))
+	}
+	if got[0].Range.Start.Line != 5 || got[0].Range.End.Line != 10 {
+		t.Errorf("got %v, but want the lines from 5 to 10", got[0].Range)
+	}
+}
+
+func TestServer_hover(t *testing.T) {
+	c := newClient(t, workspace)
+	c.open("b.proto")
+
+	var got lsp.Hover
+	c.call("textDocument/hover", c.position("b.proto", 6, 14), &got)
+	want := "```proto\nmessage a.Item\n```\n\nItem is an item.\nIt has a name."
+	if got.Contents.Value != want {
+		t.Errorf("got %q, but want %q", got.Contents.Value, want)
+	}
+	if *got.Range != rangeOf(6, 11, 17) {
+		t.Errorf("got %v, but want %v", *got.Range, rangeOf(6, 11, 17))
+	}
+}
+
+func TestServer_definition(t *testing.T) {
+	c := newClient(t, workspace)
+	c.open("b.proto")
+
+	tests := []struct {
+		name     string
+		position lsp.TextDocumentPositionParams
+		want     []lsp.Location
+	}{
+		{
+			name:     "field type in another file",
+			position: c.position("b.proto", 6, 12),
+			want:     []lsp.Location{{URI: c.uri("a/a.proto"), Range: rangeOf(5, 8, 12)}},
+		},
+		{
+			name:     "oneof field type",
+			position: c.position("b.proto", 8, 4),
+			want:     []lsp.Location{{URI: c.uri("a/a.proto"), Range: rangeOf(5, 8, 12)}},
+		},
+		{
+			name:     "rpc request in the same file",
+			position: c.position("b.proto", 13, 11),
+			want:     []lsp.Location{{URI: c.uri("b.proto"), Range: rangeOf(5, 17, 21)}},
+		},
+		{
+			name:     "rpc response",
+			position: c.position("b.proto", 13, 33),
+			want:     []lsp.Location{{URI: c.uri("a/a.proto"), Range: rangeOf(5, 8, 12)}},
+		},
+		{
+			name:     "import",
+			position: c.position("b.proto", 3, 10),
+			want:     []lsp.Location{{URI: c.uri("a/a.proto")}},
+		},
+		{
+			name:     "scalar type",
+			position: c.position("b.proto", 6, 3),
+			want:     []lsp.Location{},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var got []lsp.Location
+			c.call("textDocument/definition", &test.position, &got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, but want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestServer_references(t *testing.T) {
+	c := newClient(t, workspace)
+	c.open("a/a.proto")
+
+	var got []lsp.Location
+	c.call("textDocument/references", &lsp.ReferenceParams{
+		TextDocumentPositionParams: c.position("a/a.proto", 5, 9),
+		Context:                    lsp.ReferenceContext{IncludeDeclaration: true},
+	}, &got)
+	want := []lsp.Location{
+		{URI: c.uri("a/a.proto"), Range: rangeOf(5, 8, 12)},
+		{URI: c.uri("b.proto"), Range: rangeOf(6, 11, 17)},
+		{URI: c.uri("b.proto"), Range: rangeOf(8, 4, 10)},
+		{URI: c.uri("b.proto"), Range: rangeOf(13, 32, 38)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, but want %v", got, want)
+	}
+}
+
+func TestServer_foldingRange(t *testing.T) {
+	c := newClient(t, workspace)
+	c.open("b.proto")
+
+	var got []lsp.FoldingRange
+	c.call("textDocument/foldingRange", &lsp.FoldingRangeParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: c.uri("b.proto")},
+	}, &got)
+	want := []lsp.FoldingRange{
+		{StartLine: 5, EndLine: 10},
+		{StartLine: 7, EndLine: 9},
+		{StartLine: 12, EndLine: 14},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, but want %v", got, want)
+	}
+}
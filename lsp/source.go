@@ -0,0 +1,35 @@
+package lsp
+
+import (
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// source is the text of a file, which converts the byte offsets into the positions of the protocol,
+// whose characters count the UTF-16 code units, and vice versa.
+type source struct {
+	text  string
+	index *meta.LineIndex
+}
+
+func newSource(text string) *source {
+	return &source{text: text, index: meta.NewLineIndex(text)}
+}
+
+// position returns the position of the byte offset.
+func (s *source) position(offset int) Position {
+	pos := s.index.Position(offset)
+	return Position{
+		Line:      pos.Line - 1,
+		Character: s.index.Column(pos.Offset, meta.EncodingUTF16) - 1,
+	}
+}
+
+// offset returns the byte offset of the position.
+// The position beyond the end of its line is clamped to the end.
+func (s *source) offset(pos Position) int {
+	return s.index.Offset(pos.Line+1, pos.Character+1, meta.EncodingUTF16)
+}
+
+func (s *source) rangeOf(start, end int) Range {
+	return Range{Start: s.position(start), End: s.position(end)}
+}
@@ -0,0 +1,307 @@
+// Package migrate rewrites Protocol Buffer files using proto2 or proto3 into the ones using editions,
+// keeping their wire and API semantics the same.
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/yoheimuta/go-protoparser/v4/interpret/features"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/printer"
+)
+
+const defaultEdition = "2023"
+
+// Option is an option for ToEditions and Fprint.
+type Option func(*config)
+
+type config struct {
+	edition     string
+	printerOpts []printer.Option
+}
+
+// WithEdition is an option to set the edition to migrate to, such as "2023" and "2024". The default is "2023".
+func WithEdition(edition string) Option {
+	return func(c *config) {
+		c.edition = edition
+	}
+}
+
+// WithPrinterOptions is an option to set the options used by Fprint to print the migrated file.
+func WithPrinterOptions(opts ...printer.Option) Option {
+	return func(c *config) {
+		c.printerOpts = opts
+	}
+}
+
+// ToEditions rewrites the proto using proto2 or proto3 into the one using the edition in place.
+//
+// The features which differ from the defaults of the edition are set by the options of the file,
+// like "option features.field_presence = IMPLICIT;" for proto3.
+// The labels and the options of each field which have no place in editions are turned into
+// the equivalent features options of the field:
+//
+//   - The optional labels become "features.field_presence = EXPLICIT" if needed, and the required labels
+//     become "features.field_presence = LEGACY_REQUIRED".
+//   - The packed options become "features.repeated_field_encoding" if needed.
+//   - The groups become the nested messages and the fields with "features.message_encoding = DELIMITED".
+//   - The reserved names become identifiers.
+//
+// It returns an error if the proto already uses editions.
+func ToEditions(proto *parser.Proto, opts ...Option) error {
+	return newConfig(opts).toEditions(proto)
+}
+
+// Fprint rewrites the proto into the one using editions with ToEditions and prints it to w.
+func Fprint(w io.Writer, proto *parser.Proto, opts ...Option) error {
+	c := newConfig(opts)
+	if err := c.toEditions(proto); err != nil {
+		return err
+	}
+	return printer.Fprint(w, proto, c.printerOpts...)
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		edition: defaultEdition,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *config) toEditions(proto *parser.Proto) error {
+	if proto.Edition != nil {
+		return fmt.Errorf("%s: the file already uses edition %q", proto.Edition.Meta.Pos, proto.Edition.Edition)
+	}
+	syntax := "proto2"
+	if proto.Syntax != nil {
+		syntax = proto.Syntax.ProtobufVersion
+	}
+	from, err := features.Defaults(legacyEditions[syntax])
+	if err != nil {
+		return fmt.Errorf("unsupported syntax %q", syntax)
+	}
+	edition, ok := editionOf(c.edition)
+	if !ok {
+		return fmt.Errorf("invalid edition %q, want 2023 or later", c.edition)
+	}
+	to, err := features.Defaults(edition)
+	if err != nil {
+		return err
+	}
+
+	m := &migrator{
+		file: from,
+	}
+	proto.ProtoBody = m.body(proto.ProtoBody)
+	proto.ProtoBody = insertFileOptions(proto.ProtoBody, fileOptions(from, to))
+
+	proto.Edition = &parser.Edition{
+		Edition:      c.edition,
+		EditionQuote: strconv.Quote(c.edition),
+	}
+	if proto.Syntax != nil {
+		proto.Edition.Comments = proto.Syntax.Comments
+		proto.Edition.InlineComment = proto.Syntax.InlineComment
+		proto.Edition.Meta = proto.Syntax.Meta
+	}
+	proto.Syntax = nil
+	return nil
+}
+
+// editionOf returns the edition named like "2023", excluding the legacy, the maximum and the test-only ones.
+func editionOf(name string) (descriptorpb.Edition, bool) {
+	value, ok := descriptorpb.Edition_value["EDITION_"+name]
+	edition := descriptorpb.Edition(value)
+	if !ok ||
+		edition < descriptorpb.Edition_EDITION_2023 ||
+		edition == descriptorpb.Edition_EDITION_MAX ||
+		strings.HasSuffix(name, "_TEST_ONLY") {
+		return 0, false
+	}
+	return edition, true
+}
+
+var legacyEditions = map[string]descriptorpb.Edition{
+	"proto2": descriptorpb.Edition_EDITION_PROTO2,
+	"proto3": descriptorpb.Edition_EDITION_PROTO3,
+}
+
+// fileOptions returns the features options which keep the features of from in the edition whose defaults are to.
+func fileOptions(from, to *descriptorpb.FeatureSet) []*parser.Option {
+	var options []*parser.Option
+	add := func(name string, from, to fmt.Stringer) {
+		if from.String() != to.String() {
+			options = append(options, &parser.Option{OptionName: "features." + name, Constant: from.String()})
+		}
+	}
+	add("field_presence", from.GetFieldPresence(), to.GetFieldPresence())
+	add("enum_type", from.GetEnumType(), to.GetEnumType())
+	add("repeated_field_encoding", from.GetRepeatedFieldEncoding(), to.GetRepeatedFieldEncoding())
+	add("utf8_validation", from.GetUtf8Validation(), to.GetUtf8Validation())
+	add("message_encoding", from.GetMessageEncoding(), to.GetMessageEncoding())
+	add("json_format", from.GetJsonFormat(), to.GetJsonFormat())
+	return options
+}
+
+// insertFileOptions inserts the options after the package and the import statements at the top of the body.
+func insertFileOptions(body []parser.Visitee, options []*parser.Option) []parser.Visitee {
+	i := 0
+	for i < len(body) && isHeader(body[i]) {
+		i++
+	}
+
+	var inserted []parser.Visitee
+	inserted = append(inserted, body[:i]...)
+	for _, option := range options {
+		inserted = append(inserted, option)
+	}
+	return append(inserted, body[i:]...)
+}
+
+func isHeader(element parser.Visitee) bool {
+	switch element.(type) {
+	case *parser.Package, *parser.Import, *parser.Comment:
+		return true
+	}
+	return false
+}
+
+type migrator struct {
+	// file is the features of the original file.
+	file *descriptorpb.FeatureSet
+}
+
+// body migrates the declarations placed in the body of a file or a message.
+func (m *migrator) body(body []parser.Visitee) []parser.Visitee {
+	var migrated []parser.Visitee
+	for _, element := range body {
+		switch e := element.(type) {
+		case *parser.Message:
+			e.MessageBody = m.body(e.MessageBody)
+		case *parser.Enum:
+			m.enum(e)
+		case *parser.Field:
+			m.field(e, false)
+		case *parser.GroupField:
+			message, field := m.group(e)
+			migrated = append(migrated, message, field)
+			continue
+		case *parser.Oneof:
+			for _, field := range e.OneofFields {
+				field.FieldOptions = m.fieldOptions(field.FieldOptions, false)
+			}
+		case *parser.Reserved:
+			m.reserved(e)
+		case *parser.Extend:
+			for i, field := range e.ExtendBody {
+				switch f := field.(type) {
+				case *parser.Field:
+					m.field(f, true)
+				case *parser.GroupField:
+					// The message of the group is declared in the scope enclosing the extend.
+					message, field := m.group(f)
+					migrated = append(migrated, message)
+					e.ExtendBody[i] = field
+				}
+			}
+		}
+		migrated = append(migrated, element)
+	}
+	return migrated
+}
+
+func (m *migrator) enum(enum *parser.Enum) {
+	for _, element := range enum.EnumBody {
+		if reserved, ok := element.(*parser.Reserved); ok {
+			m.reserved(reserved)
+		}
+	}
+}
+
+// reserved turns the reserved names into identifiers.
+func (m *migrator) reserved(reserved *parser.Reserved) {
+	for i, name := range reserved.FieldNames {
+		if 2 <= len(name) && (name[0] == '"' || name[0] == '\'') && name[len(name)-1] == name[0] {
+			reserved.FieldNames[i] = name[1 : len(name)-1]
+		}
+	}
+}
+
+// field turns the labels and the options of the field into the features options.
+// The extension fields can not specify the field presence, and the singular ones always have the explicit one.
+func (m *migrator) field(field *parser.Field, extension bool) {
+	var options []*parser.FieldOption
+	switch {
+	case extension:
+	case field.IsRequired:
+		options = append(options, featureOption("field_presence", descriptorpb.FeatureSet_LEGACY_REQUIRED))
+	case field.IsOptional && m.file.GetFieldPresence() != descriptorpb.FeatureSet_EXPLICIT:
+		options = append(options, featureOption("field_presence", descriptorpb.FeatureSet_EXPLICIT))
+	}
+	field.IsRequired = false
+	field.IsOptional = false
+	field.FieldOptions = append(options, m.fieldOptions(field.FieldOptions, field.IsRepeated)...)
+}
+
+// fieldOptions replaces the packed option with the features option if it changes the encoding.
+func (m *migrator) fieldOptions(options []*parser.FieldOption, repeated bool) []*parser.FieldOption {
+	var migrated []*parser.FieldOption
+	for _, option := range options {
+		if option.OptionName != "packed" {
+			migrated = append(migrated, option)
+			continue
+		}
+
+		encoding := descriptorpb.FeatureSet_EXPANDED
+		if option.Constant == "true" {
+			encoding = descriptorpb.FeatureSet_PACKED
+		}
+		if repeated && encoding != m.file.GetRepeatedFieldEncoding() {
+			migrated = append(migrated, featureOption("repeated_field_encoding", encoding))
+		}
+	}
+	return migrated
+}
+
+// group turns the group into the nested message and the delimited field of it.
+func (m *migrator) group(group *parser.GroupField) (*parser.Message, *parser.Field) {
+	message := &parser.Message{
+		MessageName:                  group.GroupName,
+		MessageBody:                  m.body(group.MessageBody),
+		Comments:                     group.Comments,
+		InlineComment:                group.InlineComment,
+		InlineCommentBehindLeftCurly: group.InlineCommentBehindLeftCurly,
+		Meta:                         meta.Meta{Pos: group.Meta.Pos, LastPos: group.Meta.LastPos},
+	}
+
+	var options []*parser.FieldOption
+	if group.IsRequired {
+		options = append(options, featureOption("field_presence", descriptorpb.FeatureSet_LEGACY_REQUIRED))
+	}
+	options = append(options, featureOption("message_encoding", descriptorpb.FeatureSet_DELIMITED))
+	field := &parser.Field{
+		IsRepeated:   group.IsRepeated,
+		Type:         group.GroupName,
+		FieldName:    strings.ToLower(group.GroupName),
+		FieldNumber:  group.FieldNumber,
+		FieldOptions: options,
+		Meta:         meta.Meta{Pos: group.Meta.Pos, LastPos: group.Meta.LastPos},
+	}
+	return message, field
+}
+
+func featureOption(name string, value fmt.Stringer) *parser.FieldOption {
+	return &parser.FieldOption{
+		OptionName: "features." + name,
+		Constant:   value.String(),
+	}
+}
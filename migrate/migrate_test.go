@@ -0,0 +1,265 @@
+package migrate_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/interpret/features"
+	"github.com/yoheimuta/go-protoparser/v4/migrate"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+const proto2Input = `// The comment of the syntax.
+syntax = "proto2";
+package foo;
+import "b.proto";
+option go_package = "foo";
+message A {
+  optional int32 a = 1;
+  required string b = 2 [default = "x"];
+  repeated int32 c = 3 [packed = true];
+  repeated int32 d = 4 [packed = false];
+  // The comment of the group.
+  optional group G = 5 {
+    optional int32 e = 1;
+  }
+  reserved "f", 'g';
+  extensions 100 to 200;
+}
+extend A {
+  optional int32 h = 100;
+}
+enum E {
+  E_A = 1;
+  reserved "E_B";
+}
+`
+
+const proto3Input = `syntax = "proto3";
+message A {
+  int32 a = 1;
+  optional int32 b = 2;
+  repeated int32 c = 3 [packed = false];
+  repeated int32 d = 4 [packed = true];
+  string e = 5;
+  oneof o {
+    int32 f = 6;
+  }
+}
+`
+
+func TestFprint(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		inputOpts  []protoparser.Option
+		wantOutput string
+	}{
+		{
+			name:  "migrating proto2",
+			input: proto2Input,
+			wantOutput: `// The comment of the syntax.
+edition = "2023";
+package foo;
+import "b.proto";
+option features.enum_type = CLOSED;
+option features.repeated_field_encoding = EXPANDED;
+option features.utf8_validation = NONE;
+option features.json_format = LEGACY_BEST_EFFORT;
+option go_package = "foo";
+message A {
+  int32 a = 1;
+  string b = 2 [features.field_presence = LEGACY_REQUIRED, default = "x"];
+  repeated int32 c = 3 [features.repeated_field_encoding = PACKED];
+  repeated int32 d = 4;
+  // The comment of the group.
+  message G {
+    int32 e = 1;
+  }
+  G g = 5 [features.message_encoding = DELIMITED];
+  reserved f, g;
+  extensions 100 to 200;
+}
+extend A {
+  int32 h = 100;
+}
+enum E {
+  E_A = 1;
+  reserved E_B;
+}
+`,
+		},
+		{
+			name:      "migrating proto3 parsed with the trivia",
+			input:     proto3Input,
+			inputOpts: []protoparser.Option{protoparser.WithTrivia(true)},
+			wantOutput: `edition = "2023";
+option features.field_presence = IMPLICIT;
+message A {
+  int32 a = 1;
+  int32 b = 2 [features.field_presence = EXPLICIT];
+  repeated int32 c = 3 [features.repeated_field_encoding = EXPANDED];
+  repeated int32 d = 4;
+  string e = 5;
+  oneof o {
+    int32 f = 6;
+  }
+}
+`,
+		},
+		{
+			name: "migrating extensions",
+			input: `syntax = "proto3";
+import "google/protobuf/descriptor.proto";
+extend google.protobuf.FieldOptions {
+  optional int32 a = 50000;
+  int32 b = 50001;
+  repeated int32 c = 50002;
+}
+`,
+			wantOutput: `edition = "2023";
+import "google/protobuf/descriptor.proto";
+option features.field_presence = IMPLICIT;
+extend google.protobuf.FieldOptions {
+  int32 a = 50000;
+  int32 b = 50001;
+  repeated int32 c = 50002;
+}
+`,
+		},
+		{
+			name: "migrating a group in an extend",
+			input: `syntax = "proto2";
+extend A {
+  optional group G = 100 {
+    optional int32 a = 1;
+  }
+}
+`,
+			wantOutput: `edition = "2023";
+option features.enum_type = CLOSED;
+option features.repeated_field_encoding = EXPANDED;
+option features.utf8_validation = NONE;
+option features.json_format = LEGACY_BEST_EFFORT;
+message G {
+  int32 a = 1;
+}
+extend A {
+  G g = 100 [features.message_encoding = DELIMITED];
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p, err := protoparser.Parse(strings.NewReader(test.input), test.inputOpts...)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			var b bytes.Buffer
+			if err := migrate.Fprint(&b, p); err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if got := b.String(); got != test.wantOutput {
+				t.Errorf("got %s, but want %s", got, test.wantOutput)
+			}
+		})
+	}
+}
+
+// fieldFeatures returns the features of the fields keyed by their names.
+func fieldFeatures(t *testing.T, input string, migrated bool) map[string]string {
+	p, err := protoparser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if migrated {
+		if err := migrate.ToEditions(p); err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+	}
+	result, err := features.Resolve(p)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	got := make(map[string]string)
+	describe := func(name string, element parser.Visitee, presence bool, packed bool) {
+		got[name] = result.Of(element).String() + " " + boolString("presence", presence) + " " + boolString("packed", packed)
+	}
+	var body func(scope string, elements []parser.Visitee)
+	body = func(scope string, elements []parser.Visitee) {
+		for _, element := range elements {
+			switch e := element.(type) {
+			case *parser.Message:
+				body(scope+e.MessageName+".", e.MessageBody)
+			case *parser.Field:
+				describe(scope+e.FieldName, e, result.HasPresence(e), result.IsPacked(e))
+			case *parser.GroupField:
+				describe(scope+strings.ToLower(e.GroupName), e, result.HasPresence(e), false)
+				body(scope+e.GroupName+".", e.MessageBody)
+			case *parser.Oneof:
+				for _, f := range e.OneofFields {
+					describe(scope+f.FieldName, f, result.HasPresence(f), false)
+				}
+			case *parser.Extend:
+				body(scope+"extend.", e.ExtendBody)
+			case *parser.Enum:
+				describe(scope+e.EnumName, e, false, false)
+			}
+		}
+	}
+	body("", p.ProtoBody)
+	return got
+}
+
+func boolString(name string, b bool) string {
+	if b {
+		return name
+	}
+	return "no " + name
+}
+
+func TestToEditions_keepsFeatures(t *testing.T) {
+	for _, input := range []string{proto2Input, proto3Input} {
+		before := fieldFeatures(t, input, false)
+		after := fieldFeatures(t, input, true)
+		for name, want := range before {
+			if got := after[name]; got != want {
+				t.Errorf("%s: got %s, but want %s", name, got, want)
+			}
+		}
+		if len(after) != len(before) {
+			t.Errorf("got %v, but want %v", after, before)
+		}
+	}
+}
+
+func TestToEditions_editions(t *testing.T) {
+	p, err := protoparser.Parse(strings.NewReader(`edition = "2023";`))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	err = migrate.ToEditions(p)
+	if want := `<input>:1:1: the file already uses edition "2023"`; err == nil || err.Error() != want {
+		t.Errorf("got %v, but want %v", err, want)
+	}
+}
+
+func TestToEditions_invalidEdition(t *testing.T) {
+	for _, edition := range []string{"PROTO2", "PROTO3", "LEGACY", "MAX", "99999_TEST_ONLY", "2022"} {
+		p, err := protoparser.Parse(strings.NewReader(`syntax = "proto3";`))
+		if err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+		err = migrate.ToEditions(p, migrate.WithEdition(edition))
+		if want := fmt.Sprintf("invalid edition %q, want 2023 or later", edition); err == nil || err.Error() != want {
+			t.Errorf("got %v, but want %v", err, want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package astjson encodes the parsed tree of the parser package into JSON and decodes it back losslessly.
+//
+// Each node is encoded into an object whose "kind" member is the name of its type, like "Message",
+// "Field" or "RPCRequest", followed by its fields named as in Go in the order of the declaration.
+// The kind lets the decoder restore the concrete type of each element of the bodies,
+// such as Message.MessageBody, which encoding/json cannot. The other values, like meta.Meta,
+// are encoded as encoding/json does.
+//
+// For example, a field is encoded as follows, omitting some members:
+//
+//	{"kind":"Field","IsRepeated":false,...,"Type":"string","FieldName":"id","FieldNumber":"1",...}
+package astjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// kindKey is the name of the member holding the kind of a node.
+const kindKey = "kind"
+
+// kinds are the types of the nodes keyed by their kinds.
+var kinds = func() map[string]reflect.Type {
+	kinds := make(map[string]reflect.Type)
+	for _, node := range []parser.Node{
+		&parser.Proto{},
+		&parser.Comment{},
+		&parser.Syntax{},
+		&parser.Edition{},
+		&parser.Import{},
+		&parser.Package{},
+		&parser.Option{},
+		&parser.OptionName{},
+		&parser.OptionNamePart{},
+		&parser.OptionValue{},
+		&parser.OptionValueField{},
+		&parser.EmptyStatement{},
+		&parser.Message{},
+		&parser.Field{},
+		&parser.FieldOption{},
+		&parser.MapField{},
+		&parser.GroupField{},
+		&parser.Oneof{},
+		&parser.OneofField{},
+		&parser.Enum{},
+		&parser.EnumField{},
+		&parser.EnumValueOption{},
+		&parser.Reserved{},
+		&parser.Range{},
+		&parser.Extensions{},
+		&parser.Declaration{},
+		&parser.Extend{},
+		&parser.Service{},
+		&parser.RPC{},
+		&parser.RPCRequest{},
+		&parser.RPCResponse{},
+	} {
+		t := reflect.TypeOf(node).Elem()
+		kinds[t.Name()] = t
+	}
+	return kinds
+}()
+
+// kindOf returns the kind of the struct type, or "" if it is not a node.
+func kindOf(t reflect.Type) string {
+	if kinds[t.Name()] == t {
+		return t.Name()
+	}
+	return ""
+}
+
+// Marshal returns the JSON encoding of the node, which is one of the nodes listed in parser.Node.
+func Marshal(node parser.Node) ([]byte, error) {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Ptr || v.IsNil() || kindOf(v.Type().Elem()) == "" {
+		return nil, fmt.Errorf("astjson: unsupported node %T", node)
+	}
+	e := &encoder{}
+	if err := e.encode(v, "$"); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// MarshalIndent is like Marshal but applies json.Indent to format the output.
+func MarshalIndent(node parser.Node, prefix, indent string) ([]byte, error) {
+	b, err := Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, prefix, indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) encode(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.encode(v.Elem(), path)
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		e.buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if 0 < i {
+				e.buf.WriteByte(',')
+			}
+			if err := e.encode(v.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+		return nil
+	case reflect.Struct:
+		e.buf.WriteByte('{')
+		first := true
+		if kind := kindOf(v.Type()); kind != "" {
+			e.buf.WriteString(strconv.Quote(kindKey) + ":" + strconv.Quote(kind))
+			first = false
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if !first {
+				e.buf.WriteByte(',')
+			}
+			first = false
+			e.buf.WriteString(strconv.Quote(field.Name) + ":")
+			if err := e.encode(v.Field(i), path+"."+field.Name); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte('}')
+		return nil
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Errorf("astjson: %s: %v", path, err)
+	}
+	e.buf.Write(b)
+	return nil
+}
@@ -0,0 +1,154 @@
+package astjson_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/astjson"
+)
+
+func TestMarshal_roundTrip(t *testing.T) {
+	filenames, err := filepath.Glob(filepath.Join("..", "..", "_testdata", "*.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, filename := range filenames {
+		filename := filename
+		t.Run(filepath.Base(filename), func(t *testing.T) {
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proto, err := protoparser.Parse(
+				strings.NewReader(string(content)),
+				protoparser.WithFilename(filepath.Base(filename)),
+				protoparser.WithTrivia(true),
+				protoparser.WithSpans(true),
+				protoparser.WithOptionName(true),
+				protoparser.WithOptionValue(true),
+			)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			data, err := astjson.Marshal(proto)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			got, err := astjson.UnmarshalProto(data)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if !reflect.DeepEqual(got, proto) {
+				t.Errorf("got %v, but want %v", got, proto)
+			}
+
+			again, err := astjson.Marshal(got)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if string(again) != string(data) {
+				t.Errorf("got %s, but want %s", again, data)
+			}
+		})
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	field := &parser.Field{
+		Type:         "string",
+		FieldName:    "id",
+		FieldNumber:  "1",
+		FieldOptions: []*parser.FieldOption{{OptionName: "deprecated", Constant: "true"}},
+	}
+	message := &parser.Message{
+		MessageName: "M",
+		MessageBody: []parser.Visitee{field, &parser.Comment{Raw: "// end"}},
+	}
+
+	got, err := astjson.Marshal(message)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	for _, want := range []string{
+		`{"kind":"Message","MessageName":"M",`,
+		`"MessageBody":[{"kind":"Field","IsRepeated":false,`,
+		`"FieldOptions":[{"kind":"FieldOption","OptionName":"deprecated","Name":null,"Constant":"true",`,
+		`{"kind":"Comment","Raw":"// end",`,
+		`"Meta":{"Pos":{"Filename":"","Offset":0,"Line":0,"Column":0},`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("got %s, but want it to contain %s", got, want)
+		}
+	}
+
+	indented, err := astjson.MarshalIndent(message, "", "  ")
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if !strings.HasPrefix(string(indented), "{\n  \"kind\": \"Message\",\n  \"MessageName\": \"M\",\n") {
+		t.Errorf("got %s, but want the indented one", indented)
+	}
+
+	node, err := astjson.Unmarshal(indented)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if !reflect.DeepEqual(node, parser.Node(message)) {
+		t.Errorf("got %v, but want %v", node, message)
+	}
+}
+
+func TestUnmarshal_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "missing the kind",
+			input:   `{"MessageName":"M"}`,
+			wantErr: `astjson: $: missing "kind"`,
+		},
+		{
+			name:    "an unknown kind",
+			input:   `{"kind":"Proto","ProtoBody":[{"kind":"Mesage"}]}`,
+			wantErr: `astjson: $.ProtoBody[0]: unknown kind "Mesage"`,
+		},
+		{
+			name:    "an unknown member",
+			input:   `{"kind":"Proto","ProtoBody":[{"kind":"Message","MessageNme":"M"}]}`,
+			wantErr: `astjson: $.ProtoBody[0]: unknown member "MessageNme" of Message`,
+		},
+		{
+			name:    "a mismatched kind",
+			input:   `{"kind":"RPC","RPCRequest":{"kind":"RPCResponse"}}`,
+			wantErr: `astjson: $.RPCRequest: got kind "RPCResponse", but want "RPCRequest"`,
+		},
+		{
+			name:    "an invalid value",
+			input:   `{"kind":"Field","FieldName":1}`,
+			wantErr: `astjson: $.FieldName: json: cannot unmarshal number into Go value of type string`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := astjson.Unmarshal([]byte(test.input))
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("got %v, but want %v", err, test.wantErr)
+			}
+		})
+	}
+
+	if _, err := astjson.UnmarshalProto([]byte(`{"kind":"Message"}`)); err == nil {
+		t.Errorf("got nil, but want an error for the kind other than Proto")
+	}
+}
@@ -0,0 +1,136 @@
+package astjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// Unmarshal parses the JSON encoding of a node and returns the node.
+// The members unknown to the types are reported as errors.
+func Unmarshal(data []byte) (parser.Node, error) {
+	var node parser.Node
+	v := reflect.ValueOf(&node).Elem()
+	if err := decode(v, json.RawMessage(data), "$"); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// UnmarshalProto parses the JSON encoding of a *parser.Proto.
+func UnmarshalProto(data []byte) (*parser.Proto, error) {
+	var proto *parser.Proto
+	if err := decode(reflect.ValueOf(&proto).Elem(), json.RawMessage(data), "$"); err != nil {
+		return nil, err
+	}
+	return proto, nil
+}
+
+func isNull(data json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+// decode decodes data into v, which is settable.
+func decode(v reflect.Value, data json.RawMessage, path string) error {
+	if isNull(data) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return fmt.Errorf("astjson: %s: %v", path, err)
+		}
+		var kind string
+		if err := json.Unmarshal(object[kindKey], &kind); err != nil || kind == "" {
+			return fmt.Errorf("astjson: %s: missing %q", path, kindKey)
+		}
+		t, ok := kinds[kind]
+		if !ok {
+			return fmt.Errorf("astjson: %s: unknown kind %q", path, kind)
+		}
+		ptr := reflect.New(t)
+		if !ptr.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("astjson: %s: kind %q is not allowed here", path, kind)
+		}
+		if err := decodeStruct(ptr.Elem(), object, path); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	case reflect.Ptr:
+		ptr := reflect.New(v.Type().Elem())
+		if err := decode(ptr.Elem(), data, path); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	case reflect.Slice:
+		var elements []json.RawMessage
+		if err := json.Unmarshal(data, &elements); err != nil {
+			return fmt.Errorf("astjson: %s: %v", path, err)
+		}
+		s := reflect.MakeSlice(v.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := decode(s.Index(i), element, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return fmt.Errorf("astjson: %s: %v", path, err)
+		}
+		return decodeStruct(v, object, path)
+	}
+
+	if err := json.Unmarshal(data, v.Addr().Interface()); err != nil {
+		return fmt.Errorf("astjson: %s: %v", path, err)
+	}
+	return nil
+}
+
+func decodeStruct(v reflect.Value, object map[string]json.RawMessage, path string) error {
+	if kind := kindOf(v.Type()); kind != "" {
+		if data, ok := object[kindKey]; ok {
+			var got string
+			if err := json.Unmarshal(data, &got); err != nil || got != kind {
+				return fmt.Errorf("astjson: %s: got kind %s, but want %q", path, data, kind)
+			}
+			delete(object, kindKey)
+		}
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		data, ok := object[field.Name]
+		if !ok {
+			continue
+		}
+		delete(object, field.Name)
+		if err := decode(v.Field(i), data, path+"."+field.Name); err != nil {
+			return err
+		}
+	}
+	if 0 < len(object) {
+		var names []string
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("astjson: %s: unknown member %q of %s", path, names[0], v.Type().Name())
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package astutil
+
+import (
+	"reflect"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// takeOverComments gives n the leading comments and the inline comment of old
+// if n is another element which has none of them.
+func takeOverComments(n, old parser.Node) {
+	if n == nil || old == nil || n == old {
+		return
+	}
+	to := reflect.Indirect(reflect.ValueOf(n))
+	from := reflect.Indirect(reflect.ValueOf(old))
+	if to.Kind() != reflect.Struct || from.Kind() != reflect.Struct {
+		return
+	}
+
+	toComments, fromComments := to.FieldByName("Comments"), from.FieldByName("Comments")
+	toInline, fromInline := to.FieldByName("InlineComment"), from.FieldByName("InlineComment")
+	if !toComments.IsValid() || !toInline.IsValid() || !fromComments.IsValid() || !fromInline.IsValid() {
+		return
+	}
+	if toComments.Len() != 0 || !toInline.IsNil() {
+		return
+	}
+	toComments.Set(fromComments)
+	toInline.Set(fromInline)
+}
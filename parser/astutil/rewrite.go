@@ -0,0 +1,390 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// This file is derived from golang.org/x/tools/go/ast/astutil/rewrite.go
+// and adapted to the tree of the parser package.
+
+// Package astutil provides the utilities to rewrite the parsed tree of the parser package.
+package astutil
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// An ApplyFunc is invoked by Apply for each node n before and/or after
+// the node's children, using a Cursor describing the current node and providing operations on it.
+//
+// The return value of ApplyFunc controls the syntax tree traversal. See Apply for details.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses the tree recursively, starting with root, and calling pre and post for each node
+// as described below, like golang.org/x/tools/go/ast/astutil.Apply. Apply returns the tree,
+// possibly modified.
+//
+// If pre is not nil, it is called for each node before the node's children are traversed (pre-order).
+// If pre returns false, no children are traversed, and post is not called for that node.
+//
+// If post is not nil, and a prior call of pre didn't return false, post is called for each node
+// after its children are traversed (post-order). If post returns false, traversal is terminated and
+// Apply returns immediately.
+//
+// Only the nodes set to the fields are traversed; the nil fields, like a missing InlineComment, are skipped.
+// The children are traversed in the same order as parser.Walk.
+func Apply(root parser.Node, pre, post ApplyFunc) (result parser.Node) {
+	parent := &struct{ Node parser.Node }{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+	a := &application{pre: pre, post: post}
+	a.apply(parent, "Node", nil, root)
+	return
+}
+
+var abort = new(int) // singleton, to signal termination of Apply
+
+// A Cursor describes a node encountered during Apply.
+// Information about the node and its parent is available from the Node, Parent, Name, and Index methods.
+//
+// If p is a variable of type and value of the current parent node c.Parent(), and f is the field
+// identifier with name c.Name(), the following invariants hold:
+//
+//	p.f            == c.Node()  if c.Index() <  0
+//	p.f[c.Index()] == c.Node()  if c.Index() >= 0
+//
+// The methods Replace, Delete, InsertBefore, and InsertAfter can be used to change the tree.
+type Cursor struct {
+	parent parser.Node
+	name   string
+	iter   *iterator // valid if non-nil
+	node   parser.Node
+}
+
+// Node returns the current Node.
+func (c *Cursor) Node() parser.Node { return c.node }
+
+// Parent returns the parent of the current Node.
+// It is an internal holder of the root when the current Node is the root.
+func (c *Cursor) Parent() parser.Node { return c.parent }
+
+// Name returns the name of the parent Node field that contains the current Node,
+// like "MessageBody" or "FieldOptions".
+// If the parent is the holder of the root, Name returns "Node".
+func (c *Cursor) Name() string { return c.name }
+
+// Index reports the index >= 0 of the current Node in the slice of Nodes that contains it,
+// or a value < 0 if the current Node is not part of a slice.
+// The index of the current node changes if InsertBefore is called while processing the current node.
+func (c *Cursor) Index() int {
+	if c.iter != nil {
+		return c.iter.index
+	}
+	return -1
+}
+
+// field returns the current node's parent field value.
+func (c *Cursor) field() reflect.Value {
+	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
+}
+
+// Replace replaces the current Node with n. The replacement node is not walked by Apply.
+//
+// If n has neither leading comments nor an inline comment, it takes over the ones of the current Node,
+// so that replacing an element with its modified copy keeps its comments.
+func (c *Cursor) Replace(n parser.Node) {
+	takeOverComments(n, c.node)
+
+	v := c.field()
+	if i := c.Index(); i >= 0 {
+		v = v.Index(i)
+	}
+	v.Set(valueOf(n, v.Type()))
+	c.node = n
+}
+
+// Delete deletes the current Node from its containing slice, along with its leading comments
+// and its inline comment. If the current Node is not part of a slice, Delete panics.
+// As a special case, if the current node is a comment attached to an element,
+// Delete only detaches it from the element.
+func (c *Cursor) Delete() {
+	i := c.Index()
+	if i < 0 {
+		if _, ok := c.node.(*parser.Comment); ok && c.field().Kind() == reflect.Ptr {
+			c.field().Set(reflect.Zero(c.field().Type()))
+			c.node = nil
+			return
+		}
+		panic("Delete node not contained in slice")
+	}
+	v := c.field()
+	l := v.Len()
+	reflect.Copy(v.Slice(i, l), v.Slice(i+1, l))
+	v.Index(l - 1).Set(reflect.Zero(v.Type().Elem()))
+	v.SetLen(l - 1)
+	c.iter.step--
+}
+
+// InsertAfter inserts n after the current Node in its containing slice.
+// If the current Node is not part of a slice, InsertAfter panics.
+// Apply does not walk n.
+func (c *Cursor) InsertAfter(n parser.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertAfter node not contained in slice")
+	}
+	v := c.field()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+2, l), v.Slice(i+1, l))
+	v.Index(i + 1).Set(valueOf(n, v.Type().Elem()))
+	c.iter.step++
+}
+
+// InsertBefore inserts n before the current Node in its containing slice.
+// If the current Node is not part of a slice, InsertBefore panics.
+// Apply will not walk n.
+func (c *Cursor) InsertBefore(n parser.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertBefore node not contained in slice")
+	}
+	v := c.field()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+1, l), v.Slice(i, l))
+	v.Index(i).Set(valueOf(n, v.Type().Elem()))
+	c.iter.index++
+}
+
+// valueOf returns the value of n to set to the field of the type t.
+func valueOf(n parser.Node, t reflect.Type) reflect.Value {
+	if n == nil {
+		return reflect.Zero(t)
+	}
+	v := reflect.ValueOf(n)
+	if !v.Type().AssignableTo(t) {
+		panic(fmt.Sprintf("astutil: cannot use %T as %v", n, t))
+	}
+	return v
+}
+
+// application carries all the shared data so we can pass it around cheaply.
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+	iter      iterator
+}
+
+// An iterator controls iteration over a slice of nodes.
+type iterator struct {
+	index, step int
+}
+
+func (a *application) apply(parent parser.Node, name string, iter *iterator, n parser.Node) {
+	// avoid heap-allocating a new cursor for each apply call; reuse a.cursor instead
+	saved := a.cursor
+	a.cursor.parent = parent
+	a.cursor.name = name
+	a.cursor.iter = iter
+	a.cursor.node = n
+
+	if a.pre != nil && !a.pre(&a.cursor) {
+		a.cursor = saved
+		return
+	}
+
+	// walk children
+	switch n := n.(type) {
+	case *parser.Proto:
+		a.applyField(n, "Syntax", n.Syntax)
+		a.applyField(n, "Edition", n.Edition)
+		a.applyList(n, "ProtoBody")
+
+	case *parser.Comment:
+		// nothing to do
+
+	case *parser.Syntax:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Edition:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Import:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Package:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Option:
+		a.applyList(n, "Comments")
+		a.applyField(n, "Name", n.Name)
+		a.applyField(n, "Value", n.Value)
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.EmptyStatement:
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Message:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineCommentBehindLeftCurly", n.InlineCommentBehindLeftCurly)
+		a.applyList(n, "MessageBody")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Field:
+		a.applyList(n, "Comments")
+		a.applyList(n, "FieldOptions")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.MapField:
+		a.applyList(n, "Comments")
+		a.applyList(n, "FieldOptions")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.GroupField:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineCommentBehindLeftCurly", n.InlineCommentBehindLeftCurly)
+		a.applyList(n, "MessageBody")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.FieldOption:
+		a.applyField(n, "Name", n.Name)
+		a.applyField(n, "Value", n.Value)
+
+	case *parser.Oneof:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineCommentBehindLeftCurly", n.InlineCommentBehindLeftCurly)
+		a.applyList(n, "Options")
+		a.applyList(n, "OneofFields")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.OneofField:
+		a.applyList(n, "Comments")
+		a.applyList(n, "FieldOptions")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Enum:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineCommentBehindLeftCurly", n.InlineCommentBehindLeftCurly)
+		a.applyList(n, "EnumBody")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.EnumField:
+		a.applyList(n, "Comments")
+		a.applyList(n, "EnumValueOptions")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.EnumValueOption:
+		a.applyField(n, "Name", n.Name)
+		a.applyField(n, "Value", n.Value)
+
+	case *parser.Reserved:
+		a.applyList(n, "Comments")
+		a.applyList(n, "Ranges")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Extensions:
+		a.applyList(n, "Comments")
+		a.applyList(n, "Ranges")
+		a.applyField(n, "InlineCommentBehindLeftSquare", n.InlineCommentBehindLeftSquare)
+		a.applyList(n, "Declarations")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Range:
+		// nothing to do
+
+	case *parser.Declaration:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineCommentBehindLeftCurly", n.InlineCommentBehindLeftCurly)
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Extend:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineCommentBehindLeftCurly", n.InlineCommentBehindLeftCurly)
+		a.applyList(n, "ExtendBody")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.Service:
+		a.applyList(n, "Comments")
+		a.applyField(n, "InlineCommentBehindLeftCurly", n.InlineCommentBehindLeftCurly)
+		a.applyList(n, "ServiceBody")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.RPC:
+		a.applyList(n, "Comments")
+		a.applyField(n, "RPCRequest", n.RPCRequest)
+		a.applyField(n, "RPCResponse", n.RPCResponse)
+		a.applyList(n, "EmbeddedComments")
+		a.applyField(n, "InlineCommentBehindLeftCurly", n.InlineCommentBehindLeftCurly)
+		a.applyList(n, "Options")
+		a.applyField(n, "InlineComment", n.InlineComment)
+
+	case *parser.RPCRequest, *parser.RPCResponse:
+		// nothing to do
+
+	case *parser.OptionName:
+		a.applyList(n, "Parts")
+
+	case *parser.OptionNamePart:
+		// nothing to do
+
+	case *parser.OptionValue:
+		a.applyList(n, "Fields")
+		a.applyList(n, "Elements")
+
+	case *parser.OptionValueField:
+		a.applyField(n, "Value", n.Value)
+
+	default:
+		panic(fmt.Sprintf("Apply: unexpected node type %T", n))
+	}
+
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(abort)
+	}
+
+	a.cursor = saved
+}
+
+// applyField applies to the node set to the field of the parent unless it is nil.
+func (a *application) applyField(parent parser.Node, name string, n parser.Node) {
+	if v := reflect.ValueOf(n); v.Kind() == reflect.Ptr && v.IsNil() {
+		return
+	}
+	a.apply(parent, name, nil, n)
+}
+
+func (a *application) applyList(parent parser.Node, name string) {
+	// avoid heap-allocating a new iterator for each applyList call; reuse a.iter instead
+	saved := a.iter
+	a.iter.index = 0
+	for {
+		// must reload parent.name each time, since cursor modifications might change it
+		v := reflect.Indirect(reflect.ValueOf(parent)).FieldByName(name)
+		if a.iter.index >= v.Len() {
+			break
+		}
+
+		// element x may be nil in a broken tree - be cautious
+		var x parser.Node
+		if e := v.Index(a.iter.index); e.IsValid() && !e.IsNil() {
+			x = e.Interface()
+		}
+
+		a.iter.step = 1
+		if x != nil {
+			a.apply(parent, name, &a.iter, x)
+		}
+		a.iter.index += a.iter.step
+	}
+	a.iter = saved
+}
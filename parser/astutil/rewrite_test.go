@@ -0,0 +1,279 @@
+package astutil_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/astutil"
+	"github.com/yoheimuta/go-protoparser/v4/printer"
+)
+
+const input = `syntax = "proto3";
+
+// S is a service.
+service S {
+  // Get gets.
+  rpc Get(Req) returns (Res);
+  // List lists.
+  rpc List(Req) returns (Res) {
+    option deprecated = true;
+  }
+}
+
+message Req {
+  // id is the ID.
+  string id = 1; // id
+  int32 page = 2;
+}
+`
+
+func isDeprecated(rpc *parser.RPC) bool {
+	for _, option := range rpc.Options {
+		if option.OptionName == "deprecated" && option.Constant == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		pre        astutil.ApplyFunc
+		post       astutil.ApplyFunc
+		wantOutput string
+	}{
+		{
+			name: "deleting the deprecated RPCs along with their comments",
+			pre: func(c *astutil.Cursor) bool {
+				if rpc, ok := c.Node().(*parser.RPC); ok && isDeprecated(rpc) {
+					c.Delete()
+				}
+				return true
+			},
+			wantOutput: `syntax = "proto3";
+
+// S is a service.
+service S {
+  // Get gets.
+  rpc Get(Req) returns (Res);
+}
+
+message Req {
+  // id is the ID.
+  string id = 1; // id
+  int32 page = 2;
+}
+`,
+		},
+		{
+			name: "adding a field option to every field",
+			post: func(c *astutil.Cursor) bool {
+				if field, ok := c.Node().(*parser.Field); ok {
+					field.FieldOptions = append(field.FieldOptions, &parser.FieldOption{
+						OptionName: "deprecated",
+						Constant:   "true",
+					})
+				}
+				return true
+			},
+			wantOutput: `syntax = "proto3";
+
+// S is a service.
+service S {
+  // Get gets.
+  rpc Get(Req) returns (Res);
+  // List lists.
+  rpc List(Req) returns (Res) {
+    option deprecated = true;
+  }
+}
+
+message Req {
+  // id is the ID.
+  string id = 1 [deprecated = true]; // id
+  int32 page = 2 [deprecated = true];
+}
+`,
+		},
+		{
+			name: "replacing a field keeping its comments and inserting the fields around it",
+			pre: func(c *astutil.Cursor) bool {
+				field, ok := c.Node().(*parser.Field)
+				if !ok || field.FieldName != "id" {
+					return true
+				}
+				c.InsertBefore(&parser.Field{Type: "string", FieldName: "before", FieldNumber: "3"})
+				c.Replace(&parser.Field{Type: "int64", FieldName: "id", FieldNumber: "1"})
+				c.InsertAfter(&parser.Field{Type: "string", FieldName: "after", FieldNumber: "4"})
+				return true
+			},
+			wantOutput: `syntax = "proto3";
+
+// S is a service.
+service S {
+  // Get gets.
+  rpc Get(Req) returns (Res);
+  // List lists.
+  rpc List(Req) returns (Res) {
+    option deprecated = true;
+  }
+}
+
+message Req {
+  string before = 3;
+  // id is the ID.
+  int64 id = 1; // id
+  string after = 4;
+  int32 page = 2;
+}
+`,
+		},
+		{
+			name: "detaching the inline comments",
+			pre: func(c *astutil.Cursor) bool {
+				if c.Name() == "InlineComment" {
+					c.Delete()
+				}
+				return true
+			},
+			wantOutput: `syntax = "proto3";
+
+// S is a service.
+service S {
+  // Get gets.
+  rpc Get(Req) returns (Res);
+  // List lists.
+  rpc List(Req) returns (Res) {
+    option deprecated = true;
+  }
+}
+
+message Req {
+  // id is the ID.
+  string id = 1;
+  int32 page = 2;
+}
+`,
+		},
+		{
+			name: "skipping the children of the service",
+			pre: func(c *astutil.Cursor) bool {
+				if _, ok := c.Node().(*parser.Service); ok {
+					return false
+				}
+				if _, ok := c.Node().(*parser.RPC); ok {
+					c.Delete()
+				}
+				return true
+			},
+			wantOutput: input,
+		},
+		{
+			name: "terminating the traversal",
+			post: func(c *astutil.Cursor) bool {
+				if _, ok := c.Node().(*parser.Field); ok {
+					c.Delete()
+					return false
+				}
+				return true
+			},
+			wantOutput: `syntax = "proto3";
+
+// S is a service.
+service S {
+  // Get gets.
+  rpc Get(Req) returns (Res);
+  // List lists.
+  rpc List(Req) returns (Res) {
+    option deprecated = true;
+  }
+}
+
+message Req {
+  int32 page = 2;
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			proto, err := protoparser.Parse(strings.NewReader(input), protoparser.WithTrivia(true))
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			got := astutil.Apply(proto, test.pre, test.post)
+			if got != parser.Node(proto) {
+				t.Errorf("got %v, but want the root %v", got, proto)
+			}
+
+			var b bytes.Buffer
+			if err := printer.Fprint(&b, proto); err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if b.String() != test.wantOutput {
+				t.Errorf("got %s, but want %s", b.String(), test.wantOutput)
+			}
+		})
+	}
+}
+
+func TestApply_cursor(t *testing.T) {
+	proto, err := protoparser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	type visit struct {
+		name  string
+		index int
+	}
+	var got []visit
+	astutil.Apply(proto, func(c *astutil.Cursor) bool {
+		switch c.Node().(type) {
+		case *parser.Proto, *parser.Service, *parser.RPC, *parser.RPCRequest, *parser.Option, *parser.Field:
+			got = append(got, visit{name: c.Name(), index: c.Index()})
+		}
+		return true
+	}, nil)
+
+	want := []visit{
+		{name: "Node", index: -1},
+		{name: "ProtoBody", index: 0},
+		{name: "ServiceBody", index: 0},
+		{name: "RPCRequest", index: -1},
+		{name: "ServiceBody", index: 1},
+		{name: "RPCRequest", index: -1},
+		{name: "Options", index: 0},
+		{name: "MessageBody", index: 0},
+		{name: "MessageBody", index: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, but want %v", got, want)
+	}
+}
+
+func TestApply_replacingRoot(t *testing.T) {
+	proto, err := protoparser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	replaced := &parser.Proto{}
+	got := astutil.Apply(proto, func(c *astutil.Cursor) bool {
+		if _, ok := c.Node().(*parser.Proto); ok {
+			c.Replace(replaced)
+			return false
+		}
+		return true
+	}, nil)
+	if got != parser.Node(replaced) {
+		t.Errorf("got %v, but want %v", got, replaced)
+	}
+}
@@ -0,0 +1,177 @@
+package parser
+
+import "reflect"
+
+// Clone returns a deep copy of the node, which shares nothing with the original.
+// The node can be any element of the parsed tree, including *Proto, or a value built from them,
+// like the ones of the unordered package. The elements referred to more than once in the node,
+// like the comments shared by Syntax and Edition, are still shared in the copy.
+func Clone(node Node) Node {
+	if node == nil {
+		return nil
+	}
+	c := &cloner{copies: make(map[cloneKey]reflect.Value)}
+	return c.clone(reflect.ValueOf(node)).Interface()
+}
+
+type cloneKey struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+type cloner struct {
+	// copies are the copied pointers and slices keyed by the originals.
+	copies map[cloneKey]reflect.Value
+}
+
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		key := cloneKey{typ: v.Type(), ptr: v.Pointer()}
+		if dst, ok := c.copies[key]; ok {
+			return dst
+		}
+		dst := reflect.New(v.Type().Elem())
+		c.copies[key] = dst
+		dst.Elem().Set(c.clone(v.Elem()))
+		return dst
+	case reflect.Interface:
+		dst := reflect.New(v.Type()).Elem()
+		if !v.IsNil() {
+			dst.Set(c.clone(v.Elem()))
+		}
+		return dst
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		key := cloneKey{typ: v.Type(), ptr: v.Pointer(), len: v.Len()}
+		if dst, ok := c.copies[key]; ok {
+			return dst
+		}
+		dst := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		c.copies[key] = dst
+		for i := 0; i < v.Len(); i++ {
+			dst.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return dst
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		dst := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(c.clone(iter.Key()), c.clone(iter.Value()))
+		}
+		return dst
+	case reflect.Struct:
+		dst := reflect.New(v.Type()).Elem()
+		// The unexported fields are copied as they are.
+		dst.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(c.clone(v.Field(i)))
+			}
+		}
+		return dst
+	}
+	return v
+}
+
+// Clone returns a deep copy of the proto.
+func (p *Proto) Clone() *Proto { return Clone(p).(*Proto) }
+
+// Clone returns a deep copy of the comment.
+func (c *Comment) Clone() *Comment { return Clone(c).(*Comment) }
+
+// Clone returns a deep copy of the syntax.
+func (s *Syntax) Clone() *Syntax { return Clone(s).(*Syntax) }
+
+// Clone returns a deep copy of the edition.
+func (s *Edition) Clone() *Edition { return Clone(s).(*Edition) }
+
+// Clone returns a deep copy of the import.
+func (i *Import) Clone() *Import { return Clone(i).(*Import) }
+
+// Clone returns a deep copy of the package.
+func (p *Package) Clone() *Package { return Clone(p).(*Package) }
+
+// Clone returns a deep copy of the option.
+func (o *Option) Clone() *Option { return Clone(o).(*Option) }
+
+// Clone returns a deep copy of the option name.
+func (n *OptionName) Clone() *OptionName { return Clone(n).(*OptionName) }
+
+// Clone returns a deep copy of the part of the option name.
+func (p *OptionNamePart) Clone() *OptionNamePart { return Clone(p).(*OptionNamePart) }
+
+// Clone returns a deep copy of the option value.
+func (v *OptionValue) Clone() *OptionValue { return Clone(v).(*OptionValue) }
+
+// Clone returns a deep copy of the field of the option value.
+func (f *OptionValueField) Clone() *OptionValueField { return Clone(f).(*OptionValueField) }
+
+// Clone returns a deep copy of the empty statement.
+func (e *EmptyStatement) Clone() *EmptyStatement { return Clone(e).(*EmptyStatement) }
+
+// Clone returns a deep copy of the message.
+func (m *Message) Clone() *Message { return Clone(m).(*Message) }
+
+// Clone returns a deep copy of the field.
+func (f *Field) Clone() *Field { return Clone(f).(*Field) }
+
+// Clone returns a deep copy of the field option.
+func (o *FieldOption) Clone() *FieldOption { return Clone(o).(*FieldOption) }
+
+// Clone returns a deep copy of the map field.
+func (m *MapField) Clone() *MapField { return Clone(m).(*MapField) }
+
+// Clone returns a deep copy of the group field.
+func (f *GroupField) Clone() *GroupField { return Clone(f).(*GroupField) }
+
+// Clone returns a deep copy of the oneof.
+func (o *Oneof) Clone() *Oneof { return Clone(o).(*Oneof) }
+
+// Clone returns a deep copy of the oneof field.
+func (f *OneofField) Clone() *OneofField { return Clone(f).(*OneofField) }
+
+// Clone returns a deep copy of the enum.
+func (e *Enum) Clone() *Enum { return Clone(e).(*Enum) }
+
+// Clone returns a deep copy of the enum field.
+func (f *EnumField) Clone() *EnumField { return Clone(f).(*EnumField) }
+
+// Clone returns a deep copy of the enum value option.
+func (o *EnumValueOption) Clone() *EnumValueOption { return Clone(o).(*EnumValueOption) }
+
+// Clone returns a deep copy of the reserved.
+func (r *Reserved) Clone() *Reserved { return Clone(r).(*Reserved) }
+
+// Clone returns a deep copy of the range.
+func (r *Range) Clone() *Range { return Clone(r).(*Range) }
+
+// Clone returns a deep copy of the extensions.
+func (e *Extensions) Clone() *Extensions { return Clone(e).(*Extensions) }
+
+// Clone returns a deep copy of the declaration.
+func (d *Declaration) Clone() *Declaration { return Clone(d).(*Declaration) }
+
+// Clone returns a deep copy of the extend.
+func (m *Extend) Clone() *Extend { return Clone(m).(*Extend) }
+
+// Clone returns a deep copy of the service.
+func (s *Service) Clone() *Service { return Clone(s).(*Service) }
+
+// Clone returns a deep copy of the RPC.
+func (r *RPC) Clone() *RPC { return Clone(r).(*RPC) }
+
+// Clone returns a deep copy of the RPC request.
+func (r *RPCRequest) Clone() *RPCRequest { return Clone(r).(*RPCRequest) }
+
+// Clone returns a deep copy of the RPC response.
+func (r *RPCResponse) Clone() *RPCResponse { return Clone(r).(*RPCResponse) }
@@ -0,0 +1,97 @@
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func TestClone(t *testing.T) {
+	input := `syntax = "proto3";
+// M is a message.
+message M {
+  string id = 1 [(validate) = { min_len: 1 }]; // id
+  oneof o { int32 n = 2; }
+}
+service S {
+  rpc Get(M) returns (M) { option deprecated = true; }
+}
+`
+	p := parser.NewParser(
+		lexer.NewLexer(strings.NewReader(input)),
+		parser.WithPermissive(true),
+		parser.WithTrivia(true),
+		parser.WithSpans(true),
+		parser.WithOptionName(true),
+		parser.WithOptionValue(true),
+	)
+	proto, err := p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	got := proto.Clone()
+	if !reflect.DeepEqual(got, proto) {
+		t.Errorf("got %v, but want %v", got, proto)
+	}
+
+	pointers := make(map[parser.Node]bool)
+	parser.Inspect(proto, func(node parser.Node) bool {
+		if node != nil {
+			pointers[node] = true
+		}
+		return true
+	})
+	parser.Inspect(got, func(node parser.Node) bool {
+		if node != nil && pointers[node] {
+			t.Errorf("got the node %T shared with the original", node)
+		}
+		return true
+	})
+
+	message := got.ProtoBody[0].(*parser.Message)
+	field := message.MessageBody[0].(*parser.Field)
+	field.FieldName = "name"
+	field.FieldOptions[0].Value.Fields[0].Value.Text = "2"
+	message.Comments[0].Raw = "// changed"
+	got.ProtoBody = append(got.ProtoBody[:1], &parser.EmptyStatement{})
+
+	original := proto.ProtoBody[0].(*parser.Message)
+	originalField := original.MessageBody[0].(*parser.Field)
+	if originalField.FieldName != "id" {
+		t.Errorf("got %q, but want %q", originalField.FieldName, "id")
+	}
+	if text := originalField.FieldOptions[0].Value.Fields[0].Value.Text; text != "1" {
+		t.Errorf("got %q, but want %q", text, "1")
+	}
+	if raw := original.Comments[0].Raw; raw != "// M is a message." {
+		t.Errorf("got %q, but want %q", raw, "// M is a message.")
+	}
+	if _, ok := proto.ProtoBody[1].(*parser.Service); !ok {
+		t.Errorf("got %T, but want *parser.Service", proto.ProtoBody[1])
+	}
+}
+
+func TestClone_sharedElements(t *testing.T) {
+	comments := []*parser.Comment{{Raw: "// shared"}}
+	proto := &parser.Proto{
+		Syntax:  &parser.Syntax{ProtobufVersion: "proto3", Comments: comments},
+		Edition: &parser.Edition{Edition: "2023", Comments: comments},
+	}
+
+	got := proto.Clone()
+	if got.Syntax.Comments[0] == comments[0] {
+		t.Errorf("got the comment shared with the original")
+	}
+	if got.Syntax.Comments[0] != got.Edition.Comments[0] {
+		t.Errorf("got %p and %p, but want the same comment", got.Syntax.Comments[0], got.Edition.Comments[0])
+	}
+
+	var nilField *parser.Field
+	if got := nilField.Clone(); got != nil {
+		t.Errorf("got %v, but want nil", got)
+	}
+}
@@ -12,6 +12,11 @@ type Declaration struct {
 	Type     string
 	Reserved bool
 	Repeated bool
+	// NumberSpan, FullNameSpan and TypeSpan are the ranges of Number, FullName and Type.
+	// They are set with the spans option.
+	NumberSpan   meta.Span
+	FullNameSpan meta.Span
+	TypeSpan     meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -78,6 +83,7 @@ func (p *Parser) ParseDeclaration() (*Declaration, error) {
 	var number string
 	var fullName string
 	var typeStr string
+	var numberSpan, fullNameSpan, typeSpan meta.Span
 	var repeated bool
 	var reserved bool
 
@@ -101,6 +107,7 @@ func (p *Parser) ParseDeclaration() (*Declaration, error) {
 				return nil, p.unexpected("number")
 			}
 			number = p.lex.Text
+			numberSpan = p.span(p.lex.Pos.Position)
 		} else if p.lex.Token == scanner.TFULLNAME {
 			p.lex.Next()
 			if p.lex.Token != scanner.TCOLON {
@@ -111,6 +118,7 @@ func (p *Parser) ParseDeclaration() (*Declaration, error) {
 				return nil, p.unexpected("full_name string")
 			}
 			fullName = p.lex.Text
+			fullNameSpan = p.span(p.lex.Pos.Position)
 		} else if p.lex.Token == scanner.TTYPE {
 			p.lex.Next()
 			if p.lex.Token != scanner.TCOLON {
@@ -121,6 +129,7 @@ func (p *Parser) ParseDeclaration() (*Declaration, error) {
 				return nil, p.unexpected("type string")
 			}
 			typeStr = p.lex.Text
+			typeSpan = p.span(p.lex.Pos.Position)
 		} else if p.lex.Token == scanner.TREPEATED {
 			p.lex.Next()
 			if p.lex.Token != scanner.TCOLON {
@@ -154,5 +163,9 @@ func (p *Parser) ParseDeclaration() (*Declaration, error) {
 		Repeated:                     repeated,
 		InlineCommentBehindLeftCurly: inlineLeftCurly,
 		Meta:                         meta.Meta{Pos: startPos.Position, LastPos: p.lex.Pos.Position},
+
+		NumberSpan:   numberSpan,
+		FullNameSpan: fullNameSpan,
+		TypeSpan:     typeSpan,
 	}, nil
 }
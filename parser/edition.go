@@ -11,6 +11,8 @@ type Edition struct {
 
 	// EditionQuote includes quotes
 	EditionQuote string
+	// EditionSpan is the range of EditionQuote. It is set with the spans option.
+	EditionSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -62,6 +64,7 @@ func (p *Parser) ParseEdition() (*Edition, error) {
 		return nil, p.unexpected("quote")
 	}
 	lq := p.lex.Text
+	quotePos := p.lex.Pos.Position
 
 	p.lex.NextNumberLit()
 	if p.lex.Token != scanner.TINTLIT {
@@ -74,6 +77,7 @@ func (p *Parser) ParseEdition() (*Edition, error) {
 		return nil, p.unexpected("quote")
 	}
 	tq := p.lex.Text
+	editionSpan := p.span(quotePos)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TSEMICOLON {
@@ -83,6 +87,7 @@ func (p *Parser) ParseEdition() (*Edition, error) {
 	return &Edition{
 		Edition:      edition,
 		EditionQuote: lq + edition + tq,
+		EditionSpan:  editionSpan,
 		Meta: meta.Meta{
 			Pos:     startPos.Position,
 			LastPos: p.lex.Pos.Position,
@@ -20,10 +20,25 @@ func (e *parseEnumBodyStatementErr) Error() string {
 	)
 }
 
+// Unwrap returns the error of the alternative which was parsed farthest.
+func (e *parseEnumBodyStatementErr) Unwrap() error {
+	return farthest(e.parseEnumFieldErr, e.parseEmptyStatementErr)
+}
+
 // EnumValueOption is an option of a enumField.
 type EnumValueOption struct {
 	OptionName string
-	Constant   string
+	// Name is the structured OptionName.
+	// It is set only when the parser runs with the option name option.
+	Name     *OptionName
+	Constant string
+	// Value is the structured value of Constant.
+	// It is set only when the parser runs with the option value option.
+	Value *OptionValue
+	// OptionNameSpan and ConstantSpan are the ranges of OptionName and Constant.
+	// They are set with the spans option.
+	OptionNameSpan meta.Span
+	ConstantSpan   meta.Span
 }
 
 // EnumField is a field of enum.
@@ -31,6 +46,9 @@ type EnumField struct {
 	Ident            string
 	Number           string
 	EnumValueOptions []*EnumValueOption
+	// IdentSpan and NumberSpan are the ranges of Ident and Number. They are set with the spans option.
+	IdentSpan  meta.Span
+	NumberSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -62,6 +80,8 @@ func (f *EnumField) Accept(v Visitor) {
 // Enum consists of a name and an enum body.
 type Enum struct {
 	EnumName string
+	// EnumNameSpan is the range of EnumName. It is set with the spans option.
+	EnumNameSpan meta.Span
 	// EnumBody can have options and enum fields.
 	// The element of this is the union of an option, enumField, reserved, and emptyStatement.
 	EnumBody []Visitee
@@ -118,6 +138,7 @@ func (p *Parser) ParseEnum() (*Enum, error) {
 		return nil, p.unexpected("enumName")
 	}
 	enumName := p.lex.Text
+	enumNameSpan := p.span(p.lex.Pos.Position)
 
 	enumBody, inlineLeftCurly, lastPos, err := p.parseEnumBody()
 	if err != nil {
@@ -126,6 +147,7 @@ func (p *Parser) ParseEnum() (*Enum, error) {
 
 	return &Enum{
 		EnumName:                     enumName,
+		EnumNameSpan:                 enumNameSpan,
 		EnumBody:                     enumBody,
 		InlineCommentBehindLeftCurly: inlineLeftCurly,
 		Meta: meta.Meta{
@@ -158,6 +180,9 @@ func (p *Parser) parseEnumBody() (
 		p.lex.NextKeyword()
 		token := p.lex.Token
 		p.lex.UnNext()
+		if p.recoverUnclosedBody(token) {
+			return stmts, inlineLeftCurly, p.lex.Pos, nil
+		}
 
 		var stmt interface {
 			HasInlineCommentSetter
@@ -185,6 +210,9 @@ func (p *Parser) parseEnumBody() (
 		case scanner.TOPTION:
 			option, err := p.ParseOption()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			option.Comments = comments
@@ -193,6 +221,9 @@ func (p *Parser) parseEnumBody() (
 			// See https://developers.google.com/protocol-buffers/docs/proto3#enum_reserved
 			reserved, err := p.ParseReserved()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			reserved.Comments = comments
@@ -208,16 +239,25 @@ func (p *Parser) parseEnumBody() (
 
 			emptyErr := p.lex.ReadEmptyStatement()
 			if emptyErr == nil {
-				stmt = &EmptyStatement{}
+				stmt = p.newEmptyStatement()
 				break
 			}
 
-			return nil, nil, scanner.Position{}, &parseEnumBodyStatementErr{
+			stmtErr := &parseEnumBodyStatementErr{
 				parseEnumFieldErr:      enumFieldErr,
 				parseEmptyStatementErr: emptyErr,
 			}
+			if p.recoverStatement(stmtErr, true) {
+				continue
+			}
+			return nil, nil, scanner.Position{}, stmtErr
 		}
 
+		if _, ok := stmt.(*EmptyStatement); ok && p.bodyIncludingComments {
+			for _, comment := range comments {
+				stmts = append(stmts, Visitee(comment))
+			}
+		}
 		p.MaybeScanInlineComment(stmt)
 		stmts = append(stmts, stmt)
 	}
@@ -232,6 +272,7 @@ func (p *Parser) parseEnumField() (*EnumField, error) {
 	}
 	startPos := p.lex.Pos
 	ident := p.lex.Text
+	identSpan := p.span(startPos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TEQUALS {
@@ -239,9 +280,11 @@ func (p *Parser) parseEnumField() (*EnumField, error) {
 	}
 
 	var intLit string
+	var numberPos meta.Position
 	p.lex.ConsumeToken(scanner.TMINUS)
 	if p.lex.Token == scanner.TMINUS {
 		intLit = "-"
+		numberPos = p.lex.Pos.Position
 	}
 
 	p.lex.NextNumberLit()
@@ -249,6 +292,10 @@ func (p *Parser) parseEnumField() (*EnumField, error) {
 		return nil, p.unexpected("intLit")
 	}
 	intLit += p.lex.Text
+	if intLit == p.lex.Text {
+		numberPos = p.lex.Pos.Position
+	}
+	numberSpan := p.span(numberPos)
 
 	enumValueOptions, err := p.parseEnumValueOptions()
 	if err != nil {
@@ -265,6 +312,9 @@ func (p *Parser) parseEnumField() (*EnumField, error) {
 		Number:           intLit,
 		EnumValueOptions: enumValueOptions,
 		Meta:             meta.Meta{Pos: startPos.Position, LastPos: p.lex.Pos.Position},
+
+		IdentSpan:  identSpan,
+		NumberSpan: numberSpan,
 	}, nil
 }
 
@@ -308,23 +358,29 @@ func (p *Parser) parseEnumValueOptions() ([]*EnumValueOption, error) {
 // enumValueOption = optionName "=" constant
 // See https://developers.google.com/protocol-buffers/docs/reference/proto3-spec#enum_definition
 func (p *Parser) parseEnumValueOption() (*EnumValueOption, error) {
-	optionName, err := p.parseOptionName()
+	optionName, name, err := p.parseOptionName()
 	if err != nil {
 		return nil, err
 	}
+	optionNameSpan := p.span(name.Meta.Pos)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TEQUALS {
 		return nil, p.unexpected("=")
 	}
 
-	constant, err := p.parseOptionConstant()
+	constant, value, err := p.parseOptionConstant()
 	if err != nil {
 		return nil, err
 	}
+	constantSpan := p.span(value.Meta.Pos)
 
 	return &EnumValueOption{
-		OptionName: optionName,
-		Constant:   constant,
+		OptionName:     optionName,
+		Name:           p.optionNameOf(name),
+		Constant:       constant,
+		Value:          p.optionValueOf(value),
+		OptionNameSpan: optionNameSpan,
+		ConstantSpan:   constantSpan,
 	}, nil
 }
@@ -0,0 +1,257 @@
+package parser
+
+import (
+	"reflect"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// EqualOption is an option for Equal.
+type EqualOption func(*equalConfig)
+
+type equalConfig struct {
+	ignoreMeta     bool
+	ignoreComments bool
+}
+
+// WithIgnoreMeta is an option to ignore the meta information, that is, the positions, the spans and the trivia.
+// Two nodes parsed from the differently formatted sources are equal with it.
+func WithIgnoreMeta(ignoreMeta bool) EqualOption {
+	return func(c *equalConfig) {
+		c.ignoreMeta = ignoreMeta
+	}
+}
+
+// WithIgnoreComments is an option to ignore the comments, including the ones placed in the bodies as elements.
+func WithIgnoreComments(ignoreComments bool) EqualOption {
+	return func(c *equalConfig) {
+		c.ignoreComments = ignoreComments
+	}
+}
+
+var (
+	metaType         = reflect.TypeOf(meta.Meta{})
+	protoMetaType    = reflect.TypeOf(&ProtoMeta{})
+	spanType         = reflect.TypeOf(meta.Span{})
+	spansType        = reflect.TypeOf([]meta.Span{})
+	commentType      = reflect.TypeOf(&Comment{})
+	commentsType     = reflect.TypeOf([]*Comment{})
+	visiteeSliceType = reflect.TypeOf([]Visitee{})
+)
+
+// Equal reports whether the nodes are structurally equal.
+// The nodes can be any elements of the parsed tree, including *Proto, or values built from them,
+// like the ones of the unordered package. A nil slice and an empty one are equal.
+func Equal(x, y Node, opts ...EqualOption) bool {
+	config := &equalConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config.equal(reflect.ValueOf(x), reflect.ValueOf(y))
+}
+
+// ignored reports whether the value of the type is out of the comparison.
+func (c *equalConfig) ignored(t reflect.Type) bool {
+	switch t {
+	case metaType, protoMetaType, spanType, spansType:
+		return c.ignoreMeta
+	case commentType, commentsType:
+		return c.ignoreComments
+	}
+	return false
+}
+
+func (c *equalConfig) equal(x, y reflect.Value) bool {
+	if !x.IsValid() || !y.IsValid() {
+		return x.IsValid() == y.IsValid()
+	}
+	if x.Type() != y.Type() {
+		return false
+	}
+
+	switch x.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() == y.IsNil()
+		}
+		return c.equal(x.Elem(), y.Elem())
+	case reflect.Slice:
+		if c.ignoreComments && x.Type() == visiteeSliceType {
+			x, y = withoutComments(x), withoutComments(y)
+		}
+		if x.Len() != y.Len() {
+			return false
+		}
+		for i := 0; i < x.Len(); i++ {
+			if !c.equal(x.Index(i), y.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if x.Len() != y.Len() {
+			return false
+		}
+		iter := x.MapRange()
+		for iter.Next() {
+			yv := y.MapIndex(iter.Key())
+			if !yv.IsValid() || !c.equal(iter.Value(), yv) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < x.NumField(); i++ {
+			if c.ignored(x.Type().Field(i).Type) {
+				continue
+			}
+			if !c.equal(x.Field(i), y.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Bool:
+		return x.Bool() == y.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return x.Int() == y.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return x.Uint() == y.Uint()
+	case reflect.Float32, reflect.Float64:
+		return x.Float() == y.Float()
+	case reflect.String:
+		return x.String() == y.String()
+	}
+	return false
+}
+
+// withoutComments returns the elements except for the comments.
+func withoutComments(v reflect.Value) reflect.Value {
+	elements := reflect.MakeSlice(v.Type(), 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if e := v.Index(i); e.IsNil() || e.Elem().Type() != commentType {
+			elements = reflect.Append(elements, e)
+		}
+	}
+	return elements
+}
+
+// Equal reports whether the proto is equal to the other.
+func (p *Proto) Equal(other *Proto, opts ...EqualOption) bool { return Equal(p, other, opts...) }
+
+// Equal reports whether the comment is equal to the other.
+func (c *Comment) Equal(other *Comment, opts ...EqualOption) bool { return Equal(c, other, opts...) }
+
+// Equal reports whether the syntax is equal to the other.
+func (s *Syntax) Equal(other *Syntax, opts ...EqualOption) bool { return Equal(s, other, opts...) }
+
+// Equal reports whether the edition is equal to the other.
+func (s *Edition) Equal(other *Edition, opts ...EqualOption) bool { return Equal(s, other, opts...) }
+
+// Equal reports whether the import is equal to the other.
+func (i *Import) Equal(other *Import, opts ...EqualOption) bool { return Equal(i, other, opts...) }
+
+// Equal reports whether the package is equal to the other.
+func (p *Package) Equal(other *Package, opts ...EqualOption) bool { return Equal(p, other, opts...) }
+
+// Equal reports whether the option is equal to the other.
+func (o *Option) Equal(other *Option, opts ...EqualOption) bool { return Equal(o, other, opts...) }
+
+// Equal reports whether the option name is equal to the other.
+func (n *OptionName) Equal(other *OptionName, opts ...EqualOption) bool {
+	return Equal(n, other, opts...)
+}
+
+// Equal reports whether the part of the option name is equal to the other.
+func (p *OptionNamePart) Equal(other *OptionNamePart, opts ...EqualOption) bool {
+	return Equal(p, other, opts...)
+}
+
+// Equal reports whether the option value is equal to the other.
+func (v *OptionValue) Equal(other *OptionValue, opts ...EqualOption) bool {
+	return Equal(v, other, opts...)
+}
+
+// Equal reports whether the field of the option value is equal to the other.
+func (f *OptionValueField) Equal(other *OptionValueField, opts ...EqualOption) bool {
+	return Equal(f, other, opts...)
+}
+
+// Equal reports whether the empty statement is equal to the other.
+func (e *EmptyStatement) Equal(other *EmptyStatement, opts ...EqualOption) bool {
+	return Equal(e, other, opts...)
+}
+
+// Equal reports whether the message is equal to the other.
+func (m *Message) Equal(other *Message, opts ...EqualOption) bool { return Equal(m, other, opts...) }
+
+// Equal reports whether the field is equal to the other.
+func (f *Field) Equal(other *Field, opts ...EqualOption) bool { return Equal(f, other, opts...) }
+
+// Equal reports whether the field option is equal to the other.
+func (o *FieldOption) Equal(other *FieldOption, opts ...EqualOption) bool {
+	return Equal(o, other, opts...)
+}
+
+// Equal reports whether the map field is equal to the other.
+func (m *MapField) Equal(other *MapField, opts ...EqualOption) bool { return Equal(m, other, opts...) }
+
+// Equal reports whether the group field is equal to the other.
+func (f *GroupField) Equal(other *GroupField, opts ...EqualOption) bool {
+	return Equal(f, other, opts...)
+}
+
+// Equal reports whether the oneof is equal to the other.
+func (o *Oneof) Equal(other *Oneof, opts ...EqualOption) bool { return Equal(o, other, opts...) }
+
+// Equal reports whether the oneof field is equal to the other.
+func (f *OneofField) Equal(other *OneofField, opts ...EqualOption) bool {
+	return Equal(f, other, opts...)
+}
+
+// Equal reports whether the enum is equal to the other.
+func (e *Enum) Equal(other *Enum, opts ...EqualOption) bool { return Equal(e, other, opts...) }
+
+// Equal reports whether the enum field is equal to the other.
+func (f *EnumField) Equal(other *EnumField, opts ...EqualOption) bool {
+	return Equal(f, other, opts...)
+}
+
+// Equal reports whether the enum value option is equal to the other.
+func (o *EnumValueOption) Equal(other *EnumValueOption, opts ...EqualOption) bool {
+	return Equal(o, other, opts...)
+}
+
+// Equal reports whether the reserved is equal to the other.
+func (r *Reserved) Equal(other *Reserved, opts ...EqualOption) bool { return Equal(r, other, opts...) }
+
+// Equal reports whether the range is equal to the other.
+func (r *Range) Equal(other *Range, opts ...EqualOption) bool { return Equal(r, other, opts...) }
+
+// Equal reports whether the extensions is equal to the other.
+func (e *Extensions) Equal(other *Extensions, opts ...EqualOption) bool {
+	return Equal(e, other, opts...)
+}
+
+// Equal reports whether the declaration is equal to the other.
+func (d *Declaration) Equal(other *Declaration, opts ...EqualOption) bool {
+	return Equal(d, other, opts...)
+}
+
+// Equal reports whether the extend is equal to the other.
+func (m *Extend) Equal(other *Extend, opts ...EqualOption) bool { return Equal(m, other, opts...) }
+
+// Equal reports whether the service is equal to the other.
+func (s *Service) Equal(other *Service, opts ...EqualOption) bool { return Equal(s, other, opts...) }
+
+// Equal reports whether the RPC is equal to the other.
+func (r *RPC) Equal(other *RPC, opts ...EqualOption) bool { return Equal(r, other, opts...) }
+
+// Equal reports whether the RPC request is equal to the other.
+func (r *RPCRequest) Equal(other *RPCRequest, opts ...EqualOption) bool {
+	return Equal(r, other, opts...)
+}
+
+// Equal reports whether the RPC response is equal to the other.
+func (r *RPCResponse) Equal(other *RPCResponse, opts ...EqualOption) bool {
+	return Equal(r, other, opts...)
+}
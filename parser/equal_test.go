@@ -0,0 +1,143 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func TestEqual(t *testing.T) {
+	parse := func(t *testing.T, input string) *parser.Proto {
+		t.Helper()
+		p := parser.NewParser(
+			lexer.NewLexer(strings.NewReader(input)),
+			parser.WithBodyIncludingComments(true),
+			parser.WithSpans(true),
+		)
+		proto, err := p.ParseProto()
+		if err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+		return proto
+	}
+	base := `syntax = "proto3";
+// M is a message.
+message M {
+  string id = 1; // id
+}
+`
+
+	tests := []struct {
+		name      string
+		other     string
+		inputOpts []parser.EqualOption
+		wantEqual bool
+	}{
+		{
+			name:      "comparing the same sources",
+			other:     base,
+			wantEqual: true,
+		},
+		{
+			name: "comparing the differently formatted sources",
+			other: `syntax = "proto3";
+// M is a message.
+message M { string id = 1; // id
+}
+`,
+			wantEqual: false,
+		},
+		{
+			name: "comparing the differently formatted sources ignoring the meta",
+			other: `syntax = "proto3";
+// M is a message.
+message M { string id = 1; // id
+}
+`,
+			inputOpts: []parser.EqualOption{parser.WithIgnoreMeta(true)},
+			wantEqual: true,
+		},
+		{
+			name: "comparing the differently commented sources ignoring the meta",
+			other: `syntax = "proto3";
+message M {
+  // id is the ID.
+  string id = 1;
+  // The end.
+}
+`,
+			inputOpts: []parser.EqualOption{parser.WithIgnoreMeta(true)},
+			wantEqual: false,
+		},
+		{
+			name: "comparing the differently commented sources ignoring the meta and the comments",
+			other: `syntax = "proto3";
+message M {
+  // id is the ID.
+  string id = 1;
+  // The end.
+}
+`,
+			inputOpts: []parser.EqualOption{parser.WithIgnoreMeta(true), parser.WithIgnoreComments(true)},
+			wantEqual: true,
+		},
+		{
+			name: "comparing the different field numbers",
+			other: `syntax = "proto3";
+// M is a message.
+message M {
+  string id = 2; // id
+}
+`,
+			inputOpts: []parser.EqualOption{parser.WithIgnoreMeta(true), parser.WithIgnoreComments(true)},
+			wantEqual: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			x := parse(t, base)
+			y := parse(t, test.other)
+			if got := x.Equal(y, test.inputOpts...); got != test.wantEqual {
+				t.Errorf("got %v, but want %v", got, test.wantEqual)
+			}
+			if got := parser.Equal(y, x, test.inputOpts...); got != test.wantEqual {
+				t.Errorf("got %v, but want %v in the reverse order", got, test.wantEqual)
+			}
+		})
+	}
+}
+
+func TestEqual_nodes(t *testing.T) {
+	field := &parser.Field{Type: "string", FieldName: "id", FieldNumber: "1"}
+
+	tests := []struct {
+		name      string
+		x         parser.Node
+		y         parser.Node
+		wantEqual bool
+	}{
+		{name: "comparing nils", x: nil, y: nil, wantEqual: true},
+		{name: "comparing a node with nil", x: field, y: nil, wantEqual: false},
+		{name: "comparing a node with its copy", x: field, y: field.Clone(), wantEqual: true},
+		{name: "comparing the different types", x: field, y: &parser.OneofField{Type: "string"}, wantEqual: false},
+		{
+			name:      "comparing a nil slice with an empty one",
+			x:         &parser.Message{MessageName: "M"},
+			y:         &parser.Message{MessageName: "M", MessageBody: []parser.Visitee{}},
+			wantEqual: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if got := parser.Equal(test.x, test.y); got != test.wantEqual {
+				t.Errorf("got %v, but want %v", got, test.wantEqual)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ func (p *Parser) unexpected(expected string) error {
 		Pos:      p.lex.Pos.Position,
 		Expected: expected,
 		Found:    fmt.Sprintf("%q(Token=%v, Pos=%s)", p.lex.Text, p.lex.Token, p.lex.Pos),
+		Text:     p.lex.Text,
 	}
 	err.SetOccured(file, line)
 	return err
@@ -20,10 +20,17 @@ func (e *parseExtendBodyStatementErr) Error() string {
 	)
 }
 
+// Unwrap returns the error of the alternative which was parsed farthest.
+func (e *parseExtendBodyStatementErr) Unwrap() error {
+	return farthest(e.parseFieldErr, e.parseEmptyStatementErr)
+}
+
 // Extend consists of a messageType and an extend body.
 type Extend struct {
 	MessageType string
-	// ExtendBody can have fields and emptyStatements
+	// MessageTypeSpan is the range of MessageType. It is set with the spans option.
+	MessageTypeSpan meta.Span
+	// ExtendBody can have fields, groups, and emptyStatements
 	ExtendBody []Visitee
 
 	// Comments are the optional ones placed at the beginning.
@@ -74,10 +81,11 @@ func (p *Parser) ParseExtend() (*Extend, error) {
 	}
 	startPos := p.lex.Pos
 
-	messageType, _, err := p.lex.ReadMessageType()
+	messageType, messageTypePos, err := p.lex.ReadMessageType()
 	if err != nil {
 		return nil, err
 	}
+	messageTypeSpan := p.span(messageTypePos.Position)
 
 	extendBody, inlineLeftCurly, lastPos, err := p.parseExtendBody()
 	if err != nil {
@@ -86,6 +94,7 @@ func (p *Parser) ParseExtend() (*Extend, error) {
 
 	return &Extend{
 		MessageType:                  messageType,
+		MessageTypeSpan:              messageTypeSpan,
 		ExtendBody:                   extendBody,
 		InlineCommentBehindLeftCurly: inlineLeftCurly,
 		Meta: meta.Meta{
@@ -134,6 +143,9 @@ func (p *Parser) parseExtendBody() (
 		p.lex.NextKeyword()
 		token := p.lex.Token
 		p.lex.UnNext()
+		if p.recoverUnclosedBody(token) {
+			return stmts, inlineLeftCurly, p.lex.Pos, nil
+		}
 
 		var stmt interface {
 			HasInlineCommentSetter
@@ -159,26 +171,48 @@ func (p *Parser) parseExtendBody() (
 			}
 			return stmts, inlineLeftCurly, lastPos, nil
 		default:
-			field, fieldErr := p.ParseField()
-			if fieldErr == nil {
-				field.Comments = comments
-				stmt = field
-				break
+			var ferr error
+			if p.peekIsGroup() {
+				groupField, groupErr := p.ParseGroupField()
+				if groupErr == nil {
+					groupField.Comments = comments
+					stmt = groupField
+					break
+				}
+				ferr = groupErr
+				p.lex.UnNext()
+			} else {
+				field, fieldErr := p.ParseField()
+				if fieldErr == nil {
+					field.Comments = comments
+					stmt = field
+					break
+				}
+				ferr = fieldErr
+				p.lex.UnNext()
 			}
-			p.lex.UnNext()
 
 			emptyErr := p.lex.ReadEmptyStatement()
 			if emptyErr == nil {
-				stmt = &EmptyStatement{}
+				stmt = p.newEmptyStatement()
 				break
 			}
 
-			return nil, nil, scanner.Position{}, &parseExtendBodyStatementErr{
-				parseFieldErr:          fieldErr,
+			stmtErr := &parseExtendBodyStatementErr{
+				parseFieldErr:          ferr,
 				parseEmptyStatementErr: emptyErr,
 			}
+			if p.recoverStatement(stmtErr, true) {
+				continue
+			}
+			return nil, nil, scanner.Position{}, stmtErr
 		}
 
+		if _, ok := stmt.(*EmptyStatement); ok && p.bodyIncludingComments {
+			for _, comment := range comments {
+				stmts = append(stmts, Visitee(comment))
+			}
+		}
 		p.MaybeScanInlineComment(stmt)
 		stmts = append(stmts, stmt)
 	}
@@ -126,6 +126,69 @@ extend google.protobuf.MethodOptions {
 				},
 			},
 		},
+		{
+			name: "parsing a group",
+			input: `
+extend Foo {
+  optional group G = 10 {
+    int32 a = 1;
+  }
+}
+`,
+			wantExtend: &parser.Extend{
+				MessageType: "Foo",
+				ExtendBody: []parser.Visitee{
+					&parser.GroupField{
+						IsOptional:  true,
+						GroupName:   "G",
+						FieldNumber: "10",
+						MessageBody: []parser.Visitee{
+							&parser.Field{
+								Type:        "int32",
+								FieldName:   "a",
+								FieldNumber: "1",
+								Meta: meta.Meta{
+									Pos: meta.Position{
+										Offset: 44,
+										Line:   4,
+										Column: 5,
+									},
+									LastPos: meta.Position{
+										Offset: 55,
+										Line:   4,
+										Column: 16,
+									},
+								},
+							},
+						},
+						Meta: meta.Meta{
+							Pos: meta.Position{
+								Offset: 16,
+								Line:   3,
+								Column: 3,
+							},
+							LastPos: meta.Position{
+								Offset: 59,
+								Line:   5,
+								Column: 3,
+							},
+						},
+					},
+				},
+				Meta: meta.Meta{
+					Pos: meta.Position{
+						Offset: 1,
+						Line:   2,
+						Column: 1,
+					},
+					LastPos: meta.Position{
+						Offset: 61,
+						Line:   6,
+						Column: 1,
+					},
+				},
+			},
+		},
 		{
 			name: "parsing a block followed by semicolon",
 			input: `
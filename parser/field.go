@@ -8,7 +8,17 @@ import (
 // FieldOption is an option for the field.
 type FieldOption struct {
 	OptionName string
-	Constant   string
+	// Name is the structured OptionName.
+	// It is set only when the parser runs with the option name option.
+	Name     *OptionName
+	Constant string
+	// Value is the structured value of Constant.
+	// It is set only when the parser runs with the option value option.
+	Value *OptionValue
+	// OptionNameSpan and ConstantSpan are the ranges of OptionName and Constant.
+	// They are set with the spans option.
+	OptionNameSpan meta.Span
+	ConstantSpan   meta.Span
 }
 
 // Field is a normal field that is the basic element of a protocol buffer message.
@@ -20,6 +30,11 @@ type Field struct {
 	FieldName    string
 	FieldNumber  string
 	FieldOptions []*FieldOption
+	// TypeSpan, FieldNameSpan and FieldNumberSpan are the ranges of Type, FieldName and FieldNumber.
+	// They are set with the spans option.
+	TypeSpan        meta.Span
+	FieldNameSpan   meta.Span
+	FieldNumberSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -74,16 +89,18 @@ func (p *Parser) ParseField() (*Field, error) {
 		p.lex.UnNext()
 	}
 
-	typeValue, _, err := p.parseType()
+	typeValue, typePos, err := p.parseType()
 	if err != nil {
 		return nil, p.unexpected("type")
 	}
+	typeSpan := p.span(typePos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TIDENT {
 		return nil, p.unexpected("fieldName")
 	}
 	fieldName := p.lex.Text
+	fieldNameSpan := p.span(p.lex.Pos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TEQUALS {
@@ -94,6 +111,7 @@ func (p *Parser) ParseField() (*Field, error) {
 	if err != nil {
 		return nil, p.unexpected("fieldNumber")
 	}
+	fieldNumberSpan := p.span(p.lex.Pos.Position)
 
 	fieldOptions, err := p.parseFieldOptionsOption()
 	if err != nil {
@@ -114,6 +132,10 @@ func (p *Parser) ParseField() (*Field, error) {
 		FieldNumber:  fieldNumber,
 		FieldOptions: fieldOptions,
 		Meta:         meta.Meta{Pos: startPos.Position, LastPos: p.lex.Pos.Position},
+
+		TypeSpan:        typeSpan,
+		FieldNameSpan:   fieldNameSpan,
+		FieldNumberSpan: fieldNumberSpan,
 	}, nil
 }
 
@@ -166,24 +188,30 @@ func (p *Parser) parseFieldOptions() ([]*FieldOption, error) {
 // fieldOption = optionName "=" constant
 // See https://developers.google.com/protocol-buffers/docs/reference/proto3-spec#field
 func (p *Parser) parseFieldOption() (*FieldOption, error) {
-	optionName, err := p.parseOptionName()
+	optionName, name, err := p.parseOptionName()
 	if err != nil {
 		return nil, err
 	}
+	optionNameSpan := p.span(name.Meta.Pos)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TEQUALS {
 		return nil, p.unexpected("=")
 	}
 
-	constant, err := p.parseOptionConstant()
+	constant, value, err := p.parseOptionConstant()
 	if err != nil {
 		return nil, err
 	}
+	constantSpan := p.span(value.Meta.Pos)
 
 	return &FieldOption{
-		OptionName: optionName,
-		Constant:   constant,
+		OptionName:     optionName,
+		Name:           p.optionNameOf(name),
+		Constant:       constant,
+		Value:          p.optionValueOf(value),
+		OptionNameSpan: optionNameSpan,
+		ConstantSpan:   constantSpan,
 	}, nil
 }
 
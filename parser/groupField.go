@@ -19,6 +19,10 @@ type GroupField struct {
 	// options, oneofs, map fields, extends, reserved, and extensions statements.
 	MessageBody []Visitee
 	FieldNumber string
+	// GroupNameSpan and FieldNumberSpan are the ranges of GroupName and FieldNumber.
+	// They are set with the spans option.
+	GroupNameSpan   meta.Span
+	FieldNumberSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -90,6 +94,7 @@ func (p *Parser) ParseGroupField() (*GroupField, error) {
 		return nil, p.unexpectedf("groupName %q must begin with capital letter.", p.lex.Text)
 	}
 	groupName := p.lex.Text
+	groupNameSpan := p.span(p.lex.Pos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TEQUALS {
@@ -100,6 +105,7 @@ func (p *Parser) ParseGroupField() (*GroupField, error) {
 	if err != nil {
 		return nil, p.unexpected("fieldNumber")
 	}
+	fieldNumberSpan := p.span(p.lex.Pos.Position)
 
 	messageBody, inlineLeftCurly, lastPos, err := p.parseMessageBody()
 	if err != nil {
@@ -114,6 +120,9 @@ func (p *Parser) ParseGroupField() (*GroupField, error) {
 		FieldNumber: fieldNumber,
 		MessageBody: messageBody,
 
+		GroupNameSpan:   groupNameSpan,
+		FieldNumberSpan: fieldNumberSpan,
+
 		InlineCommentBehindLeftCurly: inlineLeftCurly,
 		Meta: meta.Meta{
 			Pos:     startPos.Position,
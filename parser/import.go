@@ -19,6 +19,8 @@ const (
 type Import struct {
 	Modifier ImportModifier
 	Location string
+	// LocationSpan is the range of Location. It is set with the spans option.
+	LocationSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -76,6 +78,7 @@ func (p *Parser) ParseImport() (*Import, error) {
 		return nil, p.unexpected("strLit")
 	}
 	location := p.lex.Text
+	locationSpan := p.span(p.lex.Pos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TSEMICOLON {
@@ -83,8 +86,9 @@ func (p *Parser) ParseImport() (*Import, error) {
 	}
 
 	return &Import{
-		Modifier: modifier,
-		Location: location,
+		Modifier:     modifier,
+		Location:     location,
+		LocationSpan: locationSpan,
 		Meta: meta.Meta{
 			Pos:     startPos.Position,
 			LastPos: p.lex.Pos.Position,
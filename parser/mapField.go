@@ -12,6 +12,12 @@ type MapField struct {
 	MapName      string
 	FieldNumber  string
 	FieldOptions []*FieldOption
+	// KeyTypeSpan, TypeSpan, MapNameSpan and FieldNumberSpan are the ranges of KeyType, Type, MapName
+	// and FieldNumber. They are set with the spans option.
+	KeyTypeSpan     meta.Span
+	TypeSpan        meta.Span
+	MapNameSpan     meta.Span
+	FieldNumberSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -61,16 +67,18 @@ func (p *Parser) ParseMapField() (*MapField, error) {
 	if err != nil {
 		return nil, err
 	}
+	keyTypeSpan := p.span(p.lex.Pos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TCOMMA {
 		return nil, p.unexpected(",")
 	}
 
-	typeValue, _, err := p.parseType()
+	typeValue, typePos, err := p.parseType()
 	if err != nil {
 		return nil, p.unexpected("type")
 	}
+	typeSpan := p.span(typePos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TGREATER {
@@ -82,6 +90,7 @@ func (p *Parser) ParseMapField() (*MapField, error) {
 		return nil, p.unexpected("mapName")
 	}
 	mapName := p.lex.Text
+	mapNameSpan := p.span(p.lex.Pos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TEQUALS {
@@ -92,6 +101,7 @@ func (p *Parser) ParseMapField() (*MapField, error) {
 	if err != nil {
 		return nil, p.unexpected("fieldNumber")
 	}
+	fieldNumberSpan := p.span(p.lex.Pos.Position)
 
 	fieldOptions, err := p.parseFieldOptionsOption()
 	if err != nil {
@@ -110,6 +120,11 @@ func (p *Parser) ParseMapField() (*MapField, error) {
 		FieldNumber:  fieldNumber,
 		FieldOptions: fieldOptions,
 		Meta:         meta.Meta{Pos: startPos.Position, LastPos: p.lex.Pos.Position},
+
+		KeyTypeSpan:     keyTypeSpan,
+		TypeSpan:        typeSpan,
+		MapNameSpan:     mapNameSpan,
+		FieldNumberSpan: fieldNumberSpan,
 	}, nil
 }
 
@@ -20,9 +20,16 @@ func (e *parseMessageBodyStatementErr) Error() string {
 	)
 }
 
+// Unwrap returns the error of the alternative which was parsed farthest.
+func (e *parseMessageBodyStatementErr) Unwrap() error {
+	return farthest(e.parseFieldErr, e.parseEmptyStatementErr)
+}
+
 // Message consists of a message name and a message body.
 type Message struct {
 	MessageName string
+	// MessageNameSpan is the range of MessageName. It is set with the spans option.
+	MessageNameSpan meta.Span
 	// MessageBody can have fields, nested enum definitions, nested message definitions,
 	// options, oneofs, map fields, group fields(proto2 only), extends, reserved, and extensions(proto2 only) statements.
 	MessageBody []Visitee
@@ -79,6 +86,7 @@ func (p *Parser) ParseMessage() (*Message, error) {
 		return nil, p.unexpected("messageName")
 	}
 	messageName := p.lex.Text
+	messageNameSpan := p.span(p.lex.Pos.Position)
 
 	messageBody, inlineLeftCurly, lastPos, err := p.parseMessageBody()
 	if err != nil {
@@ -87,6 +95,7 @@ func (p *Parser) ParseMessage() (*Message, error) {
 
 	return &Message{
 		MessageName:                  messageName,
+		MessageNameSpan:              messageNameSpan,
 		MessageBody:                  messageBody,
 		InlineCommentBehindLeftCurly: inlineLeftCurly,
 		Meta: meta.Meta{
@@ -136,6 +145,9 @@ func (p *Parser) parseMessageBody() (
 		p.lex.NextKeyword()
 		token := p.lex.Token
 		p.lex.UnNext()
+		if p.recoverUnclosedBody(token) {
+			return stmts, inlineLeftCurly, p.lex.Pos, nil
+		}
 
 		var stmt interface {
 			HasInlineCommentSetter
@@ -163,6 +175,9 @@ func (p *Parser) parseMessageBody() (
 		case scanner.TENUM:
 			enum, err := p.ParseEnum()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			enum.Comments = comments
@@ -170,6 +185,9 @@ func (p *Parser) parseMessageBody() (
 		case scanner.TMESSAGE:
 			message, err := p.ParseMessage()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			message.Comments = comments
@@ -177,6 +195,9 @@ func (p *Parser) parseMessageBody() (
 		case scanner.TOPTION:
 			option, err := p.ParseOption()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			option.Comments = comments
@@ -184,6 +205,9 @@ func (p *Parser) parseMessageBody() (
 		case scanner.TONEOF:
 			oneof, err := p.ParseOneof()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			oneof.Comments = comments
@@ -191,6 +215,9 @@ func (p *Parser) parseMessageBody() (
 		case scanner.TMAP:
 			mapField, err := p.ParseMapField()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			mapField.Comments = comments
@@ -198,6 +225,9 @@ func (p *Parser) parseMessageBody() (
 		case scanner.TEXTEND:
 			extend, err := p.ParseExtend()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			extend.Comments = comments
@@ -205,6 +235,9 @@ func (p *Parser) parseMessageBody() (
 		case scanner.TRESERVED:
 			reserved, err := p.ParseReserved()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			reserved.Comments = comments
@@ -212,6 +245,9 @@ func (p *Parser) parseMessageBody() (
 		case scanner.TEXTENSIONS:
 			extensions, err := p.ParseExtensions()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			extensions.Comments = comments
@@ -241,16 +277,25 @@ func (p *Parser) parseMessageBody() (
 
 			emptyErr := p.lex.ReadEmptyStatement()
 			if emptyErr == nil {
-				stmt = &EmptyStatement{}
+				stmt = p.newEmptyStatement()
 				break
 			}
 
-			return nil, nil, scanner.Position{}, &parseMessageBodyStatementErr{
+			stmtErr := &parseMessageBodyStatementErr{
 				parseFieldErr:          ferr,
 				parseEmptyStatementErr: emptyErr,
 			}
+			if p.recoverStatement(stmtErr, true) {
+				continue
+			}
+			return nil, nil, scanner.Position{}, stmtErr
 		}
 
+		if _, ok := stmt.(*EmptyStatement); ok && p.bodyIncludingComments {
+			for _, comment := range comments {
+				stmts = append(stmts, Visitee(comment))
+			}
+		}
 		p.MaybeScanInlineComment(stmt)
 		stmts = append(stmts, stmt)
 	}
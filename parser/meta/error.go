@@ -7,6 +7,9 @@ type Error struct {
 	Pos      Position
 	Expected string
 	Found    string
+	// Text is the verbatim text of the found token.
+	// It is empty if the error is found at the end of the input.
+	Text string
 
 	occuredIn string
 	occuredAt int
@@ -0,0 +1,137 @@
+package meta
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Encoding is the unit counted by a column.
+type Encoding int
+
+// Encoding values.
+const (
+	// EncodingRune counts the Unicode code points, which is what Position.Column counts by default.
+	EncodingRune Encoding = iota
+	// EncodingUTF8 counts the bytes of the UTF-8 encoding.
+	EncodingUTF8
+	// EncodingUTF16 counts the code units of the UTF-16 encoding, which most editor protocols like
+	// the Language Server Protocol use. The characters outside the Basic Multilingual Plane, like emoji,
+	// count as two.
+	EncodingUTF16
+)
+
+// String returns the name of the encoding used by the Language Server Protocol, that is,
+// "utf-32" for EncodingRune, "utf-8" for EncodingUTF8 and "utf-16" for EncodingUTF16.
+func (e Encoding) String() string {
+	switch e {
+	case EncodingRune:
+		return "utf-32"
+	case EncodingUTF8:
+		return "utf-8"
+	case EncodingUTF16:
+		return "utf-16"
+	}
+	return "unknown"
+}
+
+// Len returns the number of the units of the character.
+func (e Encoding) Len(r rune) int {
+	switch e {
+	case EncodingUTF8:
+		return utf8.RuneLen(r)
+	case EncodingUTF16:
+		if 0x10000 <= r && r <= utf8.MaxRune {
+			return 2
+		}
+	}
+	return 1
+}
+
+// LineIndex maps between the byte offsets of a source and its lines and columns in any Encoding.
+// The lines and the columns start at 1, like Position.
+type LineIndex struct {
+	src string
+	// lines are the byte offsets where the lines start.
+	lines []int
+}
+
+// NewLineIndex creates a new LineIndex of the source.
+func NewLineIndex(src string) *LineIndex {
+	lines := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return &LineIndex{src: src, lines: lines}
+}
+
+// LineCount returns the number of the lines.
+func (x *LineIndex) LineCount() int {
+	return len(x.lines)
+}
+
+// Position returns the position of the byte offset, whose Column counts the Unicode code points.
+// The offset out of the source is clamped to it.
+func (x *LineIndex) Position(offset int) Position {
+	offset = x.clamp(offset)
+	line := x.line(offset)
+	return Position{
+		Offset: offset,
+		Line:   line + 1,
+		Column: x.column(line, offset, EncodingRune),
+	}
+}
+
+// Column returns the column of the byte offset in the encoding.
+// The offset out of the source is clamped to it.
+func (x *LineIndex) Column(offset int, enc Encoding) int {
+	offset = x.clamp(offset)
+	return x.column(x.line(offset), offset, enc)
+}
+
+// Offset returns the byte offset of the line and the column in the encoding.
+// The line out of the source is clamped to it, and so is the column beyond the end of the line.
+// The column in the middle of a character, like the second unit of a surrogate pair, is rounded up to
+// the next character.
+func (x *LineIndex) Offset(line, column int, enc Encoding) int {
+	if line < 1 {
+		return 0
+	}
+	if len(x.lines) < line {
+		return len(x.src)
+	}
+	offset := x.lines[line-1]
+	for c := 1; offset < len(x.src) && c < column; {
+		r, size := utf8.DecodeRuneInString(x.src[offset:])
+		if r == '\n' {
+			break
+		}
+		c += enc.Len(r)
+		offset += size
+	}
+	return offset
+}
+
+func (x *LineIndex) clamp(offset int) int {
+	if offset < 0 {
+		return 0
+	}
+	if len(x.src) < offset {
+		return len(x.src)
+	}
+	return offset
+}
+
+// line returns the zero-based line of the offset.
+func (x *LineIndex) line(offset int) int {
+	return sort.Search(len(x.lines), func(i int) bool { return offset < x.lines[i] }) - 1
+}
+
+func (x *LineIndex) column(line, offset int, enc Encoding) int {
+	column := 1
+	for _, r := range x.src[x.lines[line]:offset] {
+		column += enc.Len(r)
+	}
+	return column
+}
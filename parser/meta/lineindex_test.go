@@ -0,0 +1,131 @@
+package meta_test
+
+import (
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// src has the lines with an emoji outside the BMP and a CJK character inside it.
+const src = "syntax = \"proto3\";\n// 😀 あ\nmessage A {}\n"
+
+func TestLineIndex_Column(t *testing.T) {
+	index := meta.NewLineIndex(src)
+
+	tests := []struct {
+		name        string
+		inputOffset int
+		wantPos     meta.Position
+		wantRune    int
+		wantUTF8    int
+		wantUTF16   int
+	}{
+		{
+			name:        "start",
+			inputOffset: 0,
+			wantPos:     meta.Position{Offset: 0, Line: 1, Column: 1},
+			wantRune:    1,
+			wantUTF8:    1,
+			wantUTF16:   1,
+		},
+		{
+			name:        "after the emoji",
+			inputOffset: 26,
+			wantPos:     meta.Position{Offset: 26, Line: 2, Column: 5},
+			wantRune:    5,
+			wantUTF8:    8,
+			wantUTF16:   6,
+		},
+		{
+			name:        "after the CJK character",
+			inputOffset: 30,
+			wantPos:     meta.Position{Offset: 30, Line: 2, Column: 7},
+			wantRune:    7,
+			wantUTF8:    12,
+			wantUTF16:   8,
+		},
+		{
+			name:        "next line",
+			inputOffset: 39,
+			wantPos:     meta.Position{Offset: 39, Line: 3, Column: 9},
+			wantRune:    9,
+			wantUTF8:    9,
+			wantUTF16:   9,
+		},
+		{
+			name:        "beyond the end",
+			inputOffset: 100,
+			wantPos:     meta.Position{Offset: len(src), Line: 4, Column: 1},
+			wantRune:    1,
+			wantUTF8:    1,
+			wantUTF16:   1,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if got := index.Position(test.inputOffset); got != test.wantPos {
+				t.Errorf("got %v, but want %v", got, test.wantPos)
+			}
+			for enc, want := range map[meta.Encoding]int{
+				meta.EncodingRune:  test.wantRune,
+				meta.EncodingUTF8:  test.wantUTF8,
+				meta.EncodingUTF16: test.wantUTF16,
+			} {
+				column := index.Column(test.inputOffset, enc)
+				if column != want {
+					t.Errorf("got %d, but want %d in %s", column, want, enc)
+				}
+				line := test.wantPos.Line
+				if got := index.Offset(line, column, enc); got != test.wantPos.Offset {
+					t.Errorf("got %d, but want %d in %s", got, test.wantPos.Offset, enc)
+				}
+			}
+		})
+	}
+}
+
+func TestLineIndex_Offset(t *testing.T) {
+	index := meta.NewLineIndex(src)
+
+	tests := []struct {
+		name        string
+		inputLine   int
+		inputColumn int
+		inputEnc    meta.Encoding
+		wantOffset  int
+	}{
+		{
+			name:        "inside a surrogate pair",
+			inputLine:   2,
+			inputColumn: 5,
+			inputEnc:    meta.EncodingUTF16,
+			wantOffset:  26,
+		},
+		{
+			name:        "beyond the end of the line",
+			inputLine:   1,
+			inputColumn: 100,
+			inputEnc:    meta.EncodingUTF16,
+			wantOffset:  18,
+		},
+		{
+			name:        "beyond the last line",
+			inputLine:   10,
+			inputColumn: 1,
+			inputEnc:    meta.EncodingRune,
+			wantOffset:  len(src),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got := index.Offset(test.inputLine, test.inputColumn, test.inputEnc)
+			if got != test.wantOffset {
+				t.Errorf("got %d, but want %d", got, test.wantOffset)
+			}
+		})
+	}
+}
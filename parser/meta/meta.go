@@ -4,8 +4,31 @@ package meta
 type Meta struct {
 	// Pos is the source position.
 	Pos Position
-	// LastPos is the last source position.
-	// Currently it is set when the parsed element type is
-	// syntax, package, comment, import, option, message, enum, oneof, rpc or service.
+	// LastPos is the source position of the last character, like the closing ";" or "}".
+	// It is set for every element and comment.
 	LastPos Position
+	// End is the source position just after the last character.
+	// It is set only when the parser runs with the spans option.
+	End Position
+	// Trivia is the surrounding source text.
+	// It is set only when the parser runs with the trivia option.
+	Trivia *Trivia
+}
+
+// Span returns the range of the element from Pos to End.
+func (m Meta) Span() Span {
+	return Span{Pos: m.Pos, End: m.End}
+}
+
+// Span is the range of a token or a sequence of tokens, like an identifier, a type or a literal.
+type Span struct {
+	// Pos is the source position of the first character.
+	Pos Position
+	// End is the source position just after the last character.
+	End Position
+}
+
+// IsValid reports whether the span is set.
+func (s Span) IsValid() bool {
+	return s.Pos.Line != 0 && s.End.Line != 0
 }
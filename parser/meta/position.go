@@ -12,7 +12,8 @@ type Position struct {
 	Offset int
 	// Line is a line number, starting at 1
 	Line int
-	// Column is a column number, starting at 1 (character count per line)
+	// Column is a column number, starting at 1 (character count per line by default).
+	// The parser can count it in another Encoding, and LineIndex converts it between the encodings.
 	Column int
 }
 
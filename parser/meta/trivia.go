@@ -0,0 +1,13 @@
+package meta
+
+// Trivia is the source text around an element that carries no syntactic meaning.
+// It allows reproducing the original source byte for byte.
+type Trivia struct {
+	// Leading is the whitespace placed between the previous token and the element.
+	Leading string
+	// Text is the verbatim source text of the element, from Pos through the end of the token at LastPos.
+	Text string
+	// Trailing is the whitespace placed at the end of the element's body, that is, right before its closing token.
+	// It is empty when the element has no body.
+	Trailing string
+}
@@ -0,0 +1,200 @@
+package parser
+
+import "github.com/yoheimuta/go-protoparser/v4/parser/meta"
+
+// metaWalker visits meta.Meta of every element and comment in the order of the source.
+type metaWalker struct {
+	// visit is called with the meta and the token closing the element's body, or 0 if there is no body.
+	visit func(m *meta.Meta, closing byte)
+}
+
+func (w *metaWalker) walkProto(proto *Proto) {
+	if proto.Syntax != nil {
+		w.walkComments(proto.Syntax.Comments)
+		w.visit(&proto.Syntax.Meta, 0)
+		w.walkComment(proto.Syntax.InlineComment)
+	}
+	if proto.Edition != nil {
+		w.walkComments(proto.Edition.Comments)
+		w.visit(&proto.Edition.Meta, 0)
+		w.walkComment(proto.Edition.InlineComment)
+	}
+	w.walkBody(proto.ProtoBody)
+}
+
+func (w *metaWalker) walkBody(body []Visitee) {
+	for _, b := range body {
+		w.walkElement(b)
+	}
+}
+
+func (w *metaWalker) walkElement(v Visitee) {
+	switch e := v.(type) {
+	case *Comment:
+		w.walkComment(e)
+	case *Import:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, 0)
+		w.walkComment(e.InlineComment)
+	case *Package:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, 0)
+		w.walkComment(e.InlineComment)
+	case *Option:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, 0)
+		w.walkOption(e.Name, e.Value)
+		w.walkComment(e.InlineComment)
+	case *EmptyStatement:
+		w.visit(&e.Meta, 0)
+		w.walkComment(e.InlineComment)
+	case *Message:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, '}')
+		w.walkComment(e.InlineCommentBehindLeftCurly)
+		w.walkBody(e.MessageBody)
+		w.walkComment(e.InlineComment)
+	case *Enum:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, '}')
+		w.walkComment(e.InlineCommentBehindLeftCurly)
+		w.walkBody(e.EnumBody)
+		w.walkComment(e.InlineComment)
+	case *EnumField:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, 0)
+		for _, option := range e.EnumValueOptions {
+			w.walkOption(option.Name, option.Value)
+		}
+		w.walkComment(e.InlineComment)
+	case *Service:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, '}')
+		w.walkComment(e.InlineCommentBehindLeftCurly)
+		w.walkBody(e.ServiceBody)
+		w.walkComment(e.InlineComment)
+	case *RPC:
+		w.walkComments(e.Comments)
+		w.walkComments(e.EmbeddedComments)
+		if e.Options != nil || e.InlineCommentBehindLeftCurly != nil {
+			w.visit(&e.Meta, '}')
+		} else {
+			w.visit(&e.Meta, 0)
+		}
+		w.walkComment(e.InlineCommentBehindLeftCurly)
+		for _, option := range e.Options {
+			w.walkElement(option)
+		}
+		w.walkComment(e.InlineComment)
+	case *Field:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, 0)
+		w.walkFieldOptions(e.FieldOptions)
+		w.walkComment(e.InlineComment)
+	case *MapField:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, 0)
+		w.walkFieldOptions(e.FieldOptions)
+		w.walkComment(e.InlineComment)
+	case *GroupField:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, '}')
+		w.walkComment(e.InlineCommentBehindLeftCurly)
+		w.walkBody(e.MessageBody)
+		w.walkComment(e.InlineComment)
+	case *Oneof:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, '}')
+		w.walkComment(e.InlineCommentBehindLeftCurly)
+		for _, option := range e.Options {
+			w.walkElement(option)
+		}
+		for _, field := range e.OneofFields {
+			w.walkElement(field)
+		}
+		w.walkComment(e.InlineComment)
+	case *OneofField:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, 0)
+		w.walkFieldOptions(e.FieldOptions)
+		w.walkComment(e.InlineComment)
+	case *Reserved:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, 0)
+		w.walkComment(e.InlineComment)
+	case *Extensions:
+		w.walkComments(e.Comments)
+		if e.Declarations != nil {
+			w.visit(&e.Meta, ']')
+		} else {
+			w.visit(&e.Meta, 0)
+		}
+		w.walkComment(e.InlineCommentBehindLeftSquare)
+		for _, declaration := range e.Declarations {
+			w.walkElement(declaration)
+		}
+		w.walkComment(e.InlineComment)
+	case *Declaration:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, '}')
+		w.walkComment(e.InlineCommentBehindLeftCurly)
+		w.walkComment(e.InlineComment)
+	case *Extend:
+		w.walkComments(e.Comments)
+		w.visit(&e.Meta, '}')
+		w.walkComment(e.InlineCommentBehindLeftCurly)
+		w.walkBody(e.ExtendBody)
+		w.walkComment(e.InlineComment)
+	}
+}
+
+func (w *metaWalker) walkFieldOptions(options []*FieldOption) {
+	for _, option := range options {
+		w.walkOption(option.Name, option.Value)
+	}
+}
+
+// walkOption visits the structured name and value of an option, which are nil unless the parser records them.
+func (w *metaWalker) walkOption(name *OptionName, value *OptionValue) {
+	if name != nil {
+		w.visit(&name.Meta, 0)
+		for _, part := range name.Parts {
+			w.visit(&part.Meta, 0)
+		}
+	}
+	w.walkOptionValue(value)
+}
+
+func (w *metaWalker) walkOptionValue(value *OptionValue) {
+	if value == nil {
+		return
+	}
+	switch value.Kind {
+	case OptionValueKindMessage:
+		w.visit(&value.Meta, '}')
+	case OptionValueKindList:
+		w.visit(&value.Meta, ']')
+	default:
+		w.visit(&value.Meta, 0)
+	}
+	for _, field := range value.Fields {
+		w.visit(&field.Meta, 0)
+		w.walkOptionValue(field.Value)
+	}
+	for _, element := range value.Elements {
+		w.walkOptionValue(element)
+	}
+}
+
+func (w *metaWalker) walkComments(comments []*Comment) {
+	for _, comment := range comments {
+		w.walkComment(comment)
+	}
+}
+
+func (w *metaWalker) walkComment(comment *Comment) {
+	if comment == nil {
+		return
+	}
+	w.visit(&comment.Meta, 0)
+}
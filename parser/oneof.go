@@ -11,6 +11,11 @@ type OneofField struct {
 	FieldName    string
 	FieldNumber  string
 	FieldOptions []*FieldOption
+	// TypeSpan, FieldNameSpan and FieldNumberSpan are the ranges of Type, FieldName and FieldNumber.
+	// They are set with the spans option.
+	TypeSpan        meta.Span
+	FieldNameSpan   meta.Span
+	FieldNumberSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -43,6 +48,8 @@ func (f *OneofField) Accept(v Visitor) {
 type Oneof struct {
 	OneofFields []*OneofField
 	OneofName   string
+	// OneofNameSpan is the range of OneofName. It is set with the spans option.
+	OneofNameSpan meta.Span
 
 	Options []*Option
 
@@ -98,6 +105,7 @@ func (p *Parser) ParseOneof() (*Oneof, error) {
 		return nil, p.unexpected("oneofName")
 	}
 	oneofName := p.lex.Text
+	oneofNameSpan := p.span(p.lex.Pos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TLEFTCURLY {
@@ -119,23 +127,28 @@ func (p *Parser) ParseOneof() (*Oneof, error) {
 		p.lex.NextKeyword()
 		token := p.lex.Token
 		p.lex.UnNext()
+		if p.recoverUnclosedBody(token) {
+			break
+		}
 		if token == scanner.TOPTION {
 			// See https://github.com/yoheimuta/go-protoparser/issues/57
 			option, err := p.ParseOption()
-			if err != nil {
+			if err == nil {
+				option.Comments = comments
+				p.MaybeScanInlineComment(option)
+				options = append(options, option)
+			} else if !p.recoverStatement(err, true) {
 				return nil, err
 			}
-			option.Comments = comments
-			p.MaybeScanInlineComment(option)
-			options = append(options, option)
 		} else {
 			oneofField, err := p.parseOneofField()
-			if err != nil {
+			if err == nil {
+				oneofField.Comments = comments
+				p.MaybeScanInlineComment(oneofField)
+				oneofFields = append(oneofFields, oneofField)
+			} else if !p.recoverStatement(err, true) {
 				return nil, err
 			}
-			oneofField.Comments = comments
-			p.MaybeScanInlineComment(oneofField)
-			oneofFields = append(oneofFields, oneofField)
 		}
 
 		p.lex.Next()
@@ -158,6 +171,7 @@ func (p *Parser) ParseOneof() (*Oneof, error) {
 	return &Oneof{
 		OneofFields:                  oneofFields,
 		OneofName:                    oneofName,
+		OneofNameSpan:                oneofNameSpan,
 		Options:                      options,
 		InlineCommentBehindLeftCurly: inlineLeftCurly,
 		Meta: meta.Meta{
@@ -174,12 +188,14 @@ func (p *Parser) parseOneofField() (*OneofField, error) {
 	if err != nil {
 		return nil, p.unexpected("type")
 	}
+	typeSpan := p.span(startPos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TIDENT {
 		return nil, p.unexpected("fieldName")
 	}
 	fieldName := p.lex.Text
+	fieldNameSpan := p.span(p.lex.Pos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TEQUALS {
@@ -190,6 +206,7 @@ func (p *Parser) parseOneofField() (*OneofField, error) {
 	if err != nil {
 		return nil, p.unexpected("fieldNumber")
 	}
+	fieldNumberSpan := p.span(p.lex.Pos.Position)
 
 	fieldOptions, err := p.parseFieldOptionsOption()
 	if err != nil {
@@ -207,5 +224,9 @@ func (p *Parser) parseOneofField() (*OneofField, error) {
 		FieldNumber:  fieldNumber,
 		FieldOptions: fieldOptions,
 		Meta:         meta.Meta{Pos: startPos.Position, LastPos: p.lex.Pos.Position},
+
+		TypeSpan:        typeSpan,
+		FieldNameSpan:   fieldNameSpan,
+		FieldNumberSpan: fieldNumberSpan,
 	}, nil
 }
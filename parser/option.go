@@ -10,7 +10,17 @@ import (
 // Option can be used in proto files, messages, enums and services.
 type Option struct {
 	OptionName string
-	Constant   string
+	// Name is the structured OptionName.
+	// It is set only when the parser runs with the option name option.
+	Name     *OptionName
+	Constant string
+	// Value is the structured value of Constant.
+	// It is set only when the parser runs with the option value option.
+	Value *OptionValue
+	// OptionNameSpan and ConstantSpan are the ranges of OptionName and Constant.
+	// They are set with the spans option.
+	OptionNameSpan meta.Span
+	ConstantSpan   meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -51,20 +61,22 @@ func (p *Parser) ParseOption() (*Option, error) {
 	}
 	startPos := p.lex.Pos
 
-	optionName, err := p.parseOptionName()
+	optionName, name, err := p.parseOptionName()
 	if err != nil {
 		return nil, err
 	}
+	optionNameSpan := p.span(name.Meta.Pos)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TEQUALS {
 		return nil, p.unexpected("=")
 	}
 
-	constant, err := p.parseOptionConstant()
+	constant, value, err := p.parseOptionConstant()
 	if err != nil {
 		return nil, err
 	}
+	constantSpan := p.span(value.Meta.Pos)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TSEMICOLON {
@@ -72,8 +84,12 @@ func (p *Parser) ParseOption() (*Option, error) {
 	}
 
 	return &Option{
-		OptionName: optionName,
-		Constant:   constant,
+		OptionName:     optionName,
+		Name:           p.optionNameOf(name),
+		Constant:       constant,
+		Value:          p.optionValueOf(value),
+		OptionNameSpan: optionNameSpan,
+		ConstantSpan:   constantSpan,
 		Meta: meta.Meta{
 			Pos:     startPos.Position,
 			LastPos: p.lex.Pos.Position,
@@ -84,28 +100,36 @@ func (p *Parser) ParseOption() (*Option, error) {
 // cloudEndpointsOptionConstant = "{" ident ":" constant { ( ["," | ";" ] ident ":" constant | cloudEndpointsOptionConstant ) } ["," | ";"] "}"
 //
 // See https://cloud.google.com/endpoints/docs/grpc-service-config/reference/rpc/google.api
-func (p *Parser) parseCloudEndpointsOptionConstant() (string, error) {
+func (p *Parser) parseCloudEndpointsOptionConstant() (string, *OptionValue, error) {
 	var ret string
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TLEFTCURLY {
-		return "", p.unexpected("{")
+		return "", nil, p.unexpected("{")
 	}
 	ret += p.lex.Text
+	value := &OptionValue{
+		Kind: OptionValueKindMessage,
+		Meta: meta.Meta{Pos: p.lex.Pos.Position},
+	}
 
 	for {
 		p.lex.Next()
 		if p.lex.Token != scanner.TIDENT {
-			return "", p.unexpected("ident")
+			return "", nil, p.unexpected("ident")
 		}
 		ret += p.lex.Text
+		field := &OptionValueField{
+			Name: p.lex.Text,
+			Meta: meta.Meta{Pos: p.lex.Pos.Position},
+		}
 
 		needSemi := false
 		p.lex.Next()
 		switch p.lex.Token {
 		case scanner.TLEFTCURLY:
 			if !p.permissive {
-				return "", p.unexpected(":")
+				return "", nil, p.unexpected(":")
 			}
 			p.lex.UnNext()
 		case scanner.TCOLON:
@@ -115,16 +139,19 @@ func (p *Parser) parseCloudEndpointsOptionConstant() (string, error) {
 			}
 		default:
 			if p.permissive {
-				return "", p.unexpected("{ or :")
+				return "", nil, p.unexpected("{ or :")
 			}
-			return "", p.unexpected(":")
+			return "", nil, p.unexpected(":")
 		}
 
-		constant, err := p.parseOptionConstant()
+		constant, fieldValue, err := p.parseOptionConstant()
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 		ret += constant
+		field.Value = fieldValue
+		field.Meta.LastPos = fieldValue.Meta.LastPos
+		value.Fields = append(value.Fields, field)
 
 		p.lex.Next()
 		if p.lex.Token == scanner.TSEMICOLON && needSemi && p.permissive {
@@ -138,11 +165,13 @@ func (p *Parser) parseCloudEndpointsOptionConstant() (string, error) {
 			if p.lex.Peek() == scanner.TRIGHTCURLY && p.permissive {
 				p.lex.Next()
 				ret += p.lex.Text
-				return ret, nil
+				value.Meta.LastPos = p.lex.Pos.Position
+				return ret, value, nil
 			}
 		case p.lex.Token == scanner.TRIGHTCURLY:
 			ret += p.lex.Text
-			return ret, nil
+			value.Meta.LastPos = p.lex.Pos.Position
+			return ret, value, nil
 		default:
 			ret += "\n"
 			p.lex.UnNext()
@@ -151,39 +180,23 @@ func (p *Parser) parseCloudEndpointsOptionConstant() (string, error) {
 }
 
 // optionName = ( ident | "(" fullIdent ")" ) { "." ( ident | "(" fullIdent ")" ) }
-func (p *Parser) parseOptionName() (string, error) {
+// The structured name is always built. The callers keep it only when the parser runs with the option name option.
+func (p *Parser) parseOptionName() (string, *OptionName, error) {
 	var optionName string
+	name := &OptionName{}
 
 	p.lex.Next()
+	name.Meta.Pos = p.lex.Pos.Position
 	switch p.lex.Token {
-	case scanner.TIDENT:
-		optionName = p.lex.Text
-	case scanner.TLEFTPAREN:
-		optionName = p.lex.Text
-
-		// protoc accepts "(." fullIndent ")". See #63
-		if p.permissive {
-			p.lex.Next()
-			if p.lex.Token == scanner.TDOT {
-				optionName += "."
-			} else {
-				p.lex.UnNext()
-			}
-		}
-
-		fullIdent, _, err := p.lex.ReadFullIdent()
+	case scanner.TIDENT, scanner.TLEFTPAREN:
+		part, err := p.parseOptionNamePart()
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
-		optionName += fullIdent
-
-		p.lex.Next()
-		if p.lex.Token != scanner.TRIGHTPAREN {
-			return "", p.unexpected(")")
-		}
-		optionName += p.lex.Text
+		optionName += part.String()
+		name.Parts = append(name.Parts, part)
 	default:
-		return "", p.unexpected("ident or left paren")
+		return "", nil, p.unexpected("ident or left paren")
 	}
 
 	for {
@@ -196,95 +209,129 @@ func (p *Parser) parseOptionName() (string, error) {
 
 		p.lex.Next()
 		switch p.lex.Token {
-		case scanner.TIDENT:
-			optionName += p.lex.Text
-		case scanner.TLEFTPAREN:
-			optionName += p.lex.Text
-
-			// protoc accepts "(." fullIndent ")". See #63
-			if p.permissive {
-				p.lex.Next()
-				if p.lex.Token == scanner.TDOT {
-					optionName += "."
-				} else {
-					p.lex.UnNext()
-				}
-			}
-
-			fullIdent, _, err := p.lex.ReadFullIdent()
+		case scanner.TIDENT, scanner.TLEFTPAREN:
+			part, err := p.parseOptionNamePart()
 			if err != nil {
-				return "", err
+				return "", nil, err
 			}
-			optionName += fullIdent
-
-			p.lex.Next()
-			if p.lex.Token != scanner.TRIGHTPAREN {
-				return "", p.unexpected(")")
-			}
-			optionName += p.lex.Text
+			optionName += part.String()
+			name.Parts = append(name.Parts, part)
 		default:
-			return "", p.unexpected("ident or bracedFullIdent")
+			return "", nil, p.unexpected("ident or bracedFullIdent")
 		}
 	}
-	return optionName, nil
+	name.Meta.LastPos = name.Parts[len(name.Parts)-1].Meta.LastPos
+	return optionName, name, nil
 }
 
-func (p *Parser) parseOptionConstant() (constant string, err error) {
+// parseOptionNamePart parses either ident or "(" fullIdent ")".
+// The first token has already been read.
+func (p *Parser) parseOptionNamePart() (*OptionNamePart, error) {
+	startPos := p.lex.Pos.Position
+	if p.lex.Token == scanner.TIDENT {
+		return &OptionNamePart{
+			Name: p.lex.Text,
+			Meta: meta.Meta{Pos: startPos, LastPos: p.lastTokenEnd()},
+		}, nil
+	}
+
+	var extensionName string
+	// protoc accepts "(." fullIndent ")". See #63
+	if p.permissive {
+		p.lex.Next()
+		if p.lex.Token == scanner.TDOT {
+			extensionName = "."
+		} else {
+			p.lex.UnNext()
+		}
+	}
+
+	fullIdent, _, err := p.lex.ReadFullIdent()
+	if err != nil {
+		return nil, err
+	}
+	extensionName += fullIdent
+
+	p.lex.Next()
+	if p.lex.Token != scanner.TRIGHTPAREN {
+		return nil, p.unexpected(")")
+	}
+	return &OptionNamePart{
+		Name:        extensionName,
+		IsExtension: true,
+		Meta:        meta.Meta{Pos: startPos, LastPos: p.lex.Pos.Position},
+	}, nil
+}
+
+// optionConstant = constant | cloudEndpointsOptionConstant | "[" [ optionConstants ] "]"
+// The value is always built. The callers keep it only when the parser runs with the option value option.
+func (p *Parser) parseOptionConstant() (string, *OptionValue, error) {
 	switch p.lex.Peek() {
 	// Cloud Endpoints requires this exception.
 	case scanner.TLEFTCURLY:
 		if !p.permissive {
-			return "", p.unexpected("constant or permissive mode")
+			return "", nil, p.unexpected("constant or permissive mode")
 		}
 
 		// parses empty fields within an option
 		if p.lex.PeekN(2) == scanner.TRIGHTCURLY {
-			p.lex.NextN(2)
-			return "{}", nil
+			p.lex.Next()
+			value := &OptionValue{
+				Kind: OptionValueKindMessage,
+				Meta: meta.Meta{Pos: p.lex.Pos.Position},
+			}
+			p.lex.Next()
+			value.Meta.LastPos = p.lex.Pos.Position
+			return "{}", value, nil
 		}
 
-		constant, err = p.parseCloudEndpointsOptionConstant()
-		if err != nil {
-			return "", err
-		}
+		return p.parseCloudEndpointsOptionConstant()
 
 	case scanner.TLEFTSQUARE:
 		if !p.permissive {
-			return "", p.unexpected("constant or permissive mode")
+			return "", nil, p.unexpected("constant or permissive mode")
 		}
 		p.lex.Next()
+		value := &OptionValue{
+			Kind: OptionValueKindList,
+			Meta: meta.Meta{Pos: p.lex.Pos.Position},
+		}
 
 		// parses empty fields within an option
 		if p.lex.Peek() == scanner.TRIGHTSQUARE {
 			p.lex.Next()
-			return "[]", nil
+			value.Meta.LastPos = p.lex.Pos.Position
+			return "[]", value, nil
 		}
 
-		constant, err = p.parseOptionConstants()
+		constant, elements, err := p.parseOptionConstants()
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 		p.lex.Next()
-		constant = "[" + constant + "]"
+		value.Elements = elements
+		value.Meta.LastPos = p.lex.Pos.Position
+		return "[" + constant + "]", value, nil
 
 	default:
-		constant, _, err = p.lex.ReadConstant(p.permissive)
+		constant, startPos, err := p.lex.ReadConstant(p.permissive)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
+		return constant, newScalarOptionValue(constant, startPos.Position, p.lastTokenEnd()), nil
 	}
-	return constant, nil
 }
 
 // optionConstants = optionConstant { ","  optionConstant }
-func (p *Parser) parseOptionConstants() (constant string, err error) {
-	opt, err := p.parseOptionConstant()
+func (p *Parser) parseOptionConstants() (string, []*OptionValue, error) {
+	opt, value, err := p.parseOptionConstant()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	var opts []string
 	opts = append(opts, opt)
+	values := []*OptionValue{value}
 
 	for {
 		p.lex.Next()
@@ -293,11 +340,12 @@ func (p *Parser) parseOptionConstants() (constant string, err error) {
 			break
 		}
 
-		opt, err = p.parseOptionConstant()
+		opt, value, err = p.parseOptionConstant()
 		if err != nil {
-			return "", p.unexpected("optionConstant")
+			return "", nil, p.unexpected("optionConstant")
 		}
 		opts = append(opts, opt)
+		values = append(values, value)
 	}
-	return strings.Join(opts, ","), nil
+	return strings.Join(opts, ","), values, nil
 }
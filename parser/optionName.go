@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// OptionName is the structured name of an option, such as "(google.api.http).post".
+// It is set only when the parser runs with the option name option.
+type OptionName struct {
+	// Parts are the parts separated by dots.
+	Parts []*OptionNamePart
+
+	// Meta is the meta information.
+	Meta meta.Meta
+}
+
+// OptionNamePart is a part of an option name.
+type OptionNamePart struct {
+	// Name is either the field name or the full name of the extension without the parentheses,
+	// like "deprecated", "google.api.http" or ".foo.bar".
+	Name string
+	// IsExtension is true if the part is an extension name surrounded by parentheses.
+	IsExtension bool
+
+	// Meta is the meta information.
+	Meta meta.Meta
+}
+
+// String returns the part as written in the source, like "post" or "(google.api.http)".
+func (p *OptionNamePart) String() string {
+	if p.IsExtension {
+		return "(" + p.Name + ")"
+	}
+	return p.Name
+}
+
+// String returns the name as written in the source, like "(google.api.http).post".
+func (n *OptionName) String() string {
+	var parts []string
+	for _, part := range n.Parts {
+		parts = append(parts, part.String())
+	}
+	return strings.Join(parts, ".")
+}
+
+// optionNameOf returns the name if the parser runs with the option name option.
+func (p *Parser) optionNameOf(name *OptionName) *OptionName {
+	if !p.optionName {
+		return nil
+	}
+	return name
+}
@@ -0,0 +1,121 @@
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+func TestParser_ParseOption_optionName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantName *parser.OptionName
+	}{
+		{
+			name:  "parsing a field name",
+			input: `option java_package = "a";`,
+			wantName: &parser.OptionName{
+				Parts: []*parser.OptionNamePart{
+					{
+						Name: "java_package",
+						Meta: meta.Meta{Pos: pos(7, 1, 8), LastPos: pos(18, 1, 19)},
+					},
+				},
+				Meta: meta.Meta{Pos: pos(7, 1, 8), LastPos: pos(18, 1, 19)},
+			},
+		},
+		{
+			name:  "parsing an extension name followed by a field name",
+			input: `option (google.api.http).post = "/v1";`,
+			wantName: &parser.OptionName{
+				Parts: []*parser.OptionNamePart{
+					{
+						Name:        "google.api.http",
+						IsExtension: true,
+						Meta:        meta.Meta{Pos: pos(7, 1, 8), LastPos: pos(23, 1, 24)},
+					},
+					{
+						Name: "post",
+						Meta: meta.Meta{Pos: pos(25, 1, 26), LastPos: pos(28, 1, 29)},
+					},
+				},
+				Meta: meta.Meta{Pos: pos(7, 1, 8), LastPos: pos(28, 1, 29)},
+			},
+		},
+		{
+			name:  "parsing nested extension names",
+			input: `option (foo.bar).(.baz.qux).x = 1;`,
+			wantName: &parser.OptionName{
+				Parts: []*parser.OptionNamePart{
+					{
+						Name:        "foo.bar",
+						IsExtension: true,
+						Meta:        meta.Meta{Pos: pos(7, 1, 8), LastPos: pos(15, 1, 16)},
+					},
+					{
+						Name:        ".baz.qux",
+						IsExtension: true,
+						Meta:        meta.Meta{Pos: pos(17, 1, 18), LastPos: pos(26, 1, 27)},
+					},
+					{
+						Name: "x",
+						Meta: meta.Meta{Pos: pos(28, 1, 29), LastPos: pos(28, 1, 29)},
+					},
+				},
+				Meta: meta.Meta{Pos: pos(7, 1, 8), LastPos: pos(28, 1, 29)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p := parser.NewParser(
+				lexer.NewLexer(strings.NewReader(test.input)),
+				parser.WithPermissive(true),
+				parser.WithOptionName(true),
+			)
+			got, err := p.ParseOption()
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if !reflect.DeepEqual(got.Name, test.wantName) {
+				t.Errorf("got %v, but want %v", got.Name, test.wantName)
+			}
+			if got.Name.String() != got.OptionName {
+				t.Errorf("got %v, but want %v", got.Name.String(), got.OptionName)
+			}
+		})
+	}
+}
+
+func TestParser_ParseProto_optionName(t *testing.T) {
+	input := `syntax = "proto3";
+message A {
+  int32 a = 1 [(validate.rules).int32.gt = 0];
+}
+enum E {
+  X = 0 [(custom).(nested.ext) = true];
+}
+`
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(input)), parser.WithOptionName(true))
+	proto, err := p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	field := proto.ProtoBody[0].(*parser.Message).MessageBody[0].(*parser.Field)
+	if got := field.FieldOptions[0].Name.Parts; len(got) != 3 || !got[0].IsExtension || got[2].Name != "gt" {
+		t.Errorf("got %v, but want 3 parts starting with an extension", got)
+	}
+
+	enumField := proto.ProtoBody[1].(*parser.Enum).EnumBody[0].(*parser.EnumField)
+	if got := enumField.EnumValueOptions[0].Name.Parts; len(got) != 2 || got[1].Name != "nested.ext" || !got[1].IsExtension {
+		t.Errorf("got %v, but want 2 extension parts", got)
+	}
+}
@@ -0,0 +1,271 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// OptionValueKind is the kind of an OptionValue.
+type OptionValueKind uint
+
+// OptionValueKind values.
+const (
+	OptionValueKindInt OptionValueKind = iota
+	OptionValueKindFloat
+	OptionValueKindBool
+	OptionValueKindString
+	OptionValueKindIdentifier
+	OptionValueKindMessage
+	OptionValueKindList
+)
+
+func (k OptionValueKind) String() string {
+	switch k {
+	case OptionValueKindInt:
+		return "int"
+	case OptionValueKindFloat:
+		return "float"
+	case OptionValueKindBool:
+		return "bool"
+	case OptionValueKindString:
+		return "string"
+	case OptionValueKindIdentifier:
+		return "identifier"
+	case OptionValueKindMessage:
+		return "message"
+	case OptionValueKindList:
+		return "list"
+	}
+	return "unknown"
+}
+
+// OptionValue is the structured value of an option constant.
+// It is set only when the parser runs with the option value option.
+type OptionValue struct {
+	Kind OptionValueKind
+	// Text is the source text of a scalar value, such as "-1", "1.5", "true", `"a\n"` or "foo.BAR".
+	// Adjacent string literals are merged into one.
+	Text string
+	// String is the value of a string with the escape sequences decoded.
+	String string
+	// Fields are the fields of a message literal in the source order.
+	Fields []*OptionValueField
+	// Elements are the elements of a list.
+	Elements []*OptionValue
+
+	// Meta is the meta information.
+	Meta meta.Meta
+}
+
+// OptionValueField is a field of a message literal.
+type OptionValueField struct {
+	Name  string
+	Value *OptionValue
+
+	// Meta is the meta information.
+	Meta meta.Meta
+}
+
+// Int returns the value of an int.
+func (v *OptionValue) Int() (int64, error) {
+	if v.Kind != OptionValueKindInt {
+		return 0, fmt.Errorf("%s is not an int", v.Kind)
+	}
+	text := v.Text
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimLeft(text, "+-")
+	if isOctal(text) {
+		text = "0o" + text[1:]
+	}
+	if negative {
+		text = "-" + text
+	}
+	return strconv.ParseInt(text, 0, 64)
+}
+
+// Uint returns the value of a non-negative int.
+func (v *OptionValue) Uint() (uint64, error) {
+	if v.Kind != OptionValueKindInt {
+		return 0, fmt.Errorf("%s is not an int", v.Kind)
+	}
+	text := strings.TrimPrefix(v.Text, "+")
+	if isOctal(text) {
+		text = "0o" + text[1:]
+	}
+	return strconv.ParseUint(text, 0, 64)
+}
+
+// Float returns the value of a float, which includes inf and nan with an optional sign, or an int.
+func (v *OptionValue) Float() (float64, error) {
+	switch v.Kind {
+	case OptionValueKindFloat:
+		if strings.TrimLeft(v.Text, "+-") == "nan" {
+			// strconv.ParseFloat does not accept a signed nan.
+			return math.NaN(), nil
+		}
+		return strconv.ParseFloat(v.Text, 64)
+	case OptionValueKindInt:
+		if i, err := v.Int(); err == nil {
+			return float64(i), nil
+		}
+		u, err := v.Uint()
+		return float64(u), err
+	}
+	return 0, fmt.Errorf("%s is not a float", v.Kind)
+}
+
+// Bool returns the value of a bool.
+func (v *OptionValue) Bool() (bool, error) {
+	if v.Kind != OptionValueKindBool {
+		return false, fmt.Errorf("%s is not a bool", v.Kind)
+	}
+	return v.Text == "true", nil
+}
+
+// Field returns the first field of a message literal named name, or nil if there is none.
+func (v *OptionValue) Field(name string) *OptionValueField {
+	for _, field := range v.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// optionValueOf returns the value if the parser runs with the option value option.
+func (p *Parser) optionValueOf(value *OptionValue) *OptionValue {
+	if !p.optionValue {
+		return nil
+	}
+	return value
+}
+
+// isOctal reports whether the unsigned int literal is an octal one, such as "0755".
+func isOctal(text string) bool {
+	return 1 < len(text) && text[0] == '0' && '0' <= text[1] && text[1] <= '7'
+}
+
+// newScalarOptionValue creates the OptionValue of the constant read by ReadConstant.
+func newScalarOptionValue(text string, startPos meta.Position, lastPos meta.Position) *OptionValue {
+	value := &OptionValue{
+		Text: text,
+		Meta: meta.Meta{Pos: startPos, LastPos: lastPos},
+	}
+
+	unsigned := strings.TrimLeft(text, "+-")
+	switch {
+	case strings.HasPrefix(text, `"`), strings.HasPrefix(text, "'"):
+		value.Kind = OptionValueKindString
+		value.String = unquoteStrLit(text)
+	case text == "true", text == "false":
+		value.Kind = OptionValueKindBool
+	case unsigned == "inf", unsigned == "nan":
+		value.Kind = OptionValueKindFloat
+	case unsigned != "" && (unsigned[0] == '.' || ('0' <= unsigned[0] && unsigned[0] <= '9')):
+		value.Kind = OptionValueKindFloat
+		isHex := strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X")
+		if isHex || !strings.ContainsAny(unsigned, ".eE") {
+			value.Kind = OptionValueKindInt
+		}
+	default:
+		value.Kind = OptionValueKindIdentifier
+	}
+	return value
+}
+
+// unquoteStrLit decodes the string literal quoted with either single or double quotes.
+// An unknown escape sequence is decoded into the escaped character as the scanner accepts it.
+//
+//	strLit = ( "'" { charValue } "'" ) |  ( '"' { charValue } '"' )
+//	charValue = hexEscape | octEscape | charEscape | /[^\0\n\\]/
+//
+// See https://developers.google.com/protocol-buffers/docs/reference/proto3-spec#string_literals
+func unquoteStrLit(lit string) string {
+	s := lit
+	if 2 <= len(s) && s[0] == s[len(s)-1] {
+		s = s[1 : len(s)-1]
+	}
+
+	var b strings.Builder
+	for 0 < len(s) {
+		if s[0] != '\\' || len(s) < 2 {
+			_, size := utf8.DecodeRuneInString(s)
+			b.WriteString(s[:size])
+			s = s[size:]
+			continue
+		}
+
+		c := s[1]
+		switch c {
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case 'x', 'X':
+			if n := digitsLen(s[2:], 2, isHexDigit); 0 < n {
+				v, _ := strconv.ParseUint(s[2:2+n], 16, 8)
+				b.WriteByte(byte(v))
+				s = s[2+n:]
+				continue
+			}
+			b.WriteByte(c)
+		case 'u', 'U':
+			size := 4
+			if c == 'U' {
+				size = 8
+			}
+			if digitsLen(s[2:], size, isHexDigit) == size {
+				v, _ := strconv.ParseUint(s[2:2+size], 16, 32)
+				b.WriteRune(rune(v))
+				s = s[2+size:]
+				continue
+			}
+			b.WriteByte(c)
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			n := digitsLen(s[1:], 3, isOctDigit)
+			v, _ := strconv.ParseUint(s[1:1+n], 8, 16)
+			b.WriteByte(byte(v))
+			s = s[1+n:]
+			continue
+		default:
+			// Covers the escaped quotes and backslash as well.
+			_, size := utf8.DecodeRuneInString(s[1:])
+			b.WriteString(s[1 : 1+size])
+			s = s[1+size:]
+			continue
+		}
+		s = s[2:]
+	}
+	return b.String()
+}
+
+func digitsLen(s string, max int, isDigit func(byte) bool) int {
+	n := 0
+	for n < max && n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	return n
+}
+
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func isOctDigit(c byte) bool {
+	return '0' <= c && c <= '7'
+}
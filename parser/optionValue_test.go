@@ -0,0 +1,369 @@
+package parser_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+func pos(offset, line, column int) meta.Position {
+	return meta.Position{Offset: offset, Line: line, Column: column}
+}
+
+func TestParser_ParseOption_optionValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantValue *parser.OptionValue
+	}{
+		{
+			name:  "parsing an int",
+			input: `option a = -10;`,
+			wantValue: &parser.OptionValue{
+				Kind: parser.OptionValueKindInt,
+				Text: "-10",
+				Meta: meta.Meta{Pos: pos(11, 1, 12), LastPos: pos(13, 1, 14)},
+			},
+		},
+		{
+			name:  "parsing a float",
+			input: `option a = 1.5e3;`,
+			wantValue: &parser.OptionValue{
+				Kind: parser.OptionValueKindFloat,
+				Text: "1.5e3",
+				Meta: meta.Meta{Pos: pos(11, 1, 12), LastPos: pos(15, 1, 16)},
+			},
+		},
+		{
+			name:  "parsing a negative inf",
+			input: `option a = -inf;`,
+			wantValue: &parser.OptionValue{
+				Kind: parser.OptionValueKindFloat,
+				Text: "-inf",
+				Meta: meta.Meta{Pos: pos(11, 1, 12), LastPos: pos(14, 1, 15)},
+			},
+		},
+		{
+			name:  "parsing a nan",
+			input: `option a = nan;`,
+			wantValue: &parser.OptionValue{
+				Kind: parser.OptionValueKindFloat,
+				Text: "nan",
+				Meta: meta.Meta{Pos: pos(11, 1, 12), LastPos: pos(13, 1, 14)},
+			},
+		},
+		{
+			name:  "parsing a bool",
+			input: `option a = true;`,
+			wantValue: &parser.OptionValue{
+				Kind: parser.OptionValueKindBool,
+				Text: "true",
+				Meta: meta.Meta{Pos: pos(11, 1, 12), LastPos: pos(14, 1, 15)},
+			},
+		},
+		{
+			name:  "parsing a string with escape sequences",
+			input: `option a = "a\n\t\"\x41\101é";`,
+			wantValue: &parser.OptionValue{
+				Kind:   parser.OptionValueKindString,
+				Text:   `"a\n\t\"\x41\101é"`,
+				String: "a\n\t\"AAé",
+				Meta:   meta.Meta{Pos: pos(11, 1, 12), LastPos: pos(29, 1, 29)},
+			},
+		},
+		{
+			name:  "parsing an identifier",
+			input: `option a = foo.BAR;`,
+			wantValue: &parser.OptionValue{
+				Kind: parser.OptionValueKindIdentifier,
+				Text: "foo.BAR",
+				Meta: meta.Meta{Pos: pos(11, 1, 12), LastPos: pos(17, 1, 18)},
+			},
+		},
+		{
+			name: "parsing a message literal",
+			input: `option (google.api.http) = {
+  get: "/v1/foo"
+  additional_bindings { post: "/v1/bar"; body: "*" }
+};`,
+			wantValue: &parser.OptionValue{
+				Kind: parser.OptionValueKindMessage,
+				Fields: []*parser.OptionValueField{
+					{
+						Name: "get",
+						Value: &parser.OptionValue{
+							Kind:   parser.OptionValueKindString,
+							Text:   `"/v1/foo"`,
+							String: "/v1/foo",
+							Meta:   meta.Meta{Pos: pos(36, 2, 8), LastPos: pos(44, 2, 16)},
+						},
+						Meta: meta.Meta{Pos: pos(31, 2, 3), LastPos: pos(44, 2, 16)},
+					},
+					{
+						Name: "additional_bindings",
+						Value: &parser.OptionValue{
+							Kind: parser.OptionValueKindMessage,
+							Fields: []*parser.OptionValueField{
+								{
+									Name: "post",
+									Value: &parser.OptionValue{
+										Kind:   parser.OptionValueKindString,
+										Text:   `"/v1/bar"`,
+										String: "/v1/bar",
+										Meta:   meta.Meta{Pos: pos(76, 3, 31), LastPos: pos(84, 3, 39)},
+									},
+									Meta: meta.Meta{Pos: pos(70, 3, 25), LastPos: pos(84, 3, 39)},
+								},
+								{
+									Name: "body",
+									Value: &parser.OptionValue{
+										Kind:   parser.OptionValueKindString,
+										Text:   `"*"`,
+										String: "*",
+										Meta:   meta.Meta{Pos: pos(93, 3, 48), LastPos: pos(95, 3, 50)},
+									},
+									Meta: meta.Meta{Pos: pos(87, 3, 42), LastPos: pos(95, 3, 50)},
+								},
+							},
+							Meta: meta.Meta{Pos: pos(68, 3, 23), LastPos: pos(97, 3, 52)},
+						},
+						Meta: meta.Meta{Pos: pos(48, 3, 3), LastPos: pos(97, 3, 52)},
+					},
+				},
+				Meta: meta.Meta{Pos: pos(27, 1, 28), LastPos: pos(99, 4, 1)},
+			},
+		},
+		{
+			name:  "parsing a list",
+			input: `option a = { values: [1, "b", {}] };`,
+			wantValue: &parser.OptionValue{
+				Kind: parser.OptionValueKindMessage,
+				Fields: []*parser.OptionValueField{
+					{
+						Name: "values",
+						Value: &parser.OptionValue{
+							Kind: parser.OptionValueKindList,
+							Elements: []*parser.OptionValue{
+								{
+									Kind: parser.OptionValueKindInt,
+									Text: "1",
+									Meta: meta.Meta{Pos: pos(22, 1, 23), LastPos: pos(22, 1, 23)},
+								},
+								{
+									Kind:   parser.OptionValueKindString,
+									Text:   `"b"`,
+									String: "b",
+									Meta:   meta.Meta{Pos: pos(25, 1, 26), LastPos: pos(27, 1, 28)},
+								},
+								{
+									Kind: parser.OptionValueKindMessage,
+									Meta: meta.Meta{Pos: pos(30, 1, 31), LastPos: pos(31, 1, 32)},
+								},
+							},
+							Meta: meta.Meta{Pos: pos(21, 1, 22), LastPos: pos(32, 1, 33)},
+						},
+						Meta: meta.Meta{Pos: pos(13, 1, 14), LastPos: pos(32, 1, 33)},
+					},
+				},
+				Meta: meta.Meta{Pos: pos(11, 1, 12), LastPos: pos(34, 1, 35)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p := parser.NewParser(
+				lexer.NewLexer(strings.NewReader(test.input)),
+				parser.WithPermissive(true),
+				parser.WithOptionValue(true),
+			)
+			got, err := p.ParseOption()
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if !reflect.DeepEqual(got.Value, test.wantValue) {
+				t.Errorf("got %v, but want %v", got.Value, test.wantValue)
+			}
+		})
+	}
+}
+
+func TestParser_ParseProto_optionValue(t *testing.T) {
+	input := `syntax = "proto2";
+message A {
+  optional int32 a = 1 [default = 0x10, deprecated = true];
+}
+enum E {
+  X = 0 [(custom) = 'x'];
+}
+`
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(input)), parser.WithOptionValue(true))
+	proto, err := p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	field := proto.ProtoBody[0].(*parser.Message).MessageBody[0].(*parser.Field)
+	i, err := field.FieldOptions[0].Value.Int()
+	if err != nil || i != 16 {
+		t.Errorf("got %v, %v, but want 16", i, err)
+	}
+	b, err := field.FieldOptions[1].Value.Bool()
+	if err != nil || !b {
+		t.Errorf("got %v, %v, but want true", b, err)
+	}
+
+	enumField := proto.ProtoBody[1].(*parser.Enum).EnumBody[0].(*parser.EnumField)
+	if s := enumField.EnumValueOptions[0].Value.String; s != "x" {
+		t.Errorf("got %v, but want x", s)
+	}
+
+	p = parser.NewParser(lexer.NewLexer(strings.NewReader(input)))
+	proto, err = p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	field = proto.ProtoBody[0].(*parser.Message).MessageBody[0].(*parser.Field)
+	if field.FieldOptions[0].Value != nil {
+		t.Errorf("got %v, but want nil without the option", field.FieldOptions[0].Value)
+	}
+}
+
+func TestOptionValue_Int(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantInt  int64
+		wantUint uint64
+		wantErr  bool
+	}{
+		{
+			name:     "decimal",
+			input:    "42",
+			wantInt:  42,
+			wantUint: 42,
+		},
+		{
+			name:     "octal",
+			input:    "0755",
+			wantInt:  493,
+			wantUint: 493,
+		},
+		{
+			name:    "negative hex",
+			input:   "-0x10",
+			wantInt: -16,
+			wantErr: true,
+		},
+		{
+			name:     "max uint64",
+			input:    "18446744073709551615",
+			wantInt:  9223372036854775807,
+			wantUint: 18446744073709551615,
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p := parser.NewParser(
+				lexer.NewLexer(strings.NewReader("option a = "+test.input+";")),
+				parser.WithOptionValue(true),
+			)
+			option, err := p.ParseOption()
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			gotInt, intErr := option.Value.Int()
+			gotUint, uintErr := option.Value.Uint()
+			if gotInt != test.wantInt || gotUint != test.wantUint {
+				t.Errorf("got %v and %v, but want %v and %v", gotInt, gotUint, test.wantInt, test.wantUint)
+			}
+			if (intErr != nil || uintErr != nil) != test.wantErr {
+				t.Errorf("got %v and %v, but want an error %v", intErr, uintErr, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptionValue_Float(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantFloat float64
+		wantNaN   bool
+		wantErr   bool
+	}{
+		{
+			name:      "exponent",
+			input:     "1.5e3",
+			wantFloat: 1500,
+		},
+		{
+			name:      "int",
+			input:     "0x10",
+			wantFloat: 16,
+		},
+		{
+			name:      "inf",
+			input:     "inf",
+			wantFloat: math.Inf(1),
+		},
+		{
+			name:      "negative inf",
+			input:     "-inf",
+			wantFloat: math.Inf(-1),
+		},
+		{
+			name:    "nan",
+			input:   "nan",
+			wantNaN: true,
+		},
+		{
+			name:    "negative nan",
+			input:   "-nan",
+			wantNaN: true,
+		},
+		{
+			name:    "identifier",
+			input:   "infinite",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p := parser.NewParser(
+				lexer.NewLexer(strings.NewReader("option a = "+test.input+";")),
+				parser.WithOptionValue(true),
+			)
+			option, err := p.ParseOption()
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			got, err := option.Value.Float()
+			if (err != nil) != test.wantErr {
+				t.Errorf("got err %v, but want an error %v", err, test.wantErr)
+			}
+			switch {
+			case test.wantErr:
+			case test.wantNaN:
+				if !math.IsNaN(got) {
+					t.Errorf("got %v, but want NaN", got)
+				}
+			case got != test.wantFloat:
+				t.Errorf("got %v, but want %v", got, test.wantFloat)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 // Package can be used to prevent name clashes between protocol message types.
 type Package struct {
 	Name string
+	// NameSpan is the range of Name. It is set with the spans option.
+	NameSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -48,10 +50,11 @@ func (p *Parser) ParsePackage() (*Package, error) {
 	}
 	startPos := p.lex.Pos
 
-	ident, _, err := p.lex.ReadFullIdent()
+	ident, identPos, err := p.lex.ReadFullIdent()
 	if err != nil {
 		return nil, p.unexpected("fullIdent")
 	}
+	nameSpan := p.span(identPos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TSEMICOLON {
@@ -59,7 +62,8 @@ func (p *Parser) ParsePackage() (*Package, error) {
 	}
 
 	return &Package{
-		Name: ident,
+		Name:     ident,
+		NameSpan: nameSpan,
 		Meta: meta.Meta{
 			Pos:     startPos.Position,
 			LastPos: p.lex.Pos.Position,
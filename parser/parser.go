@@ -1,6 +1,9 @@
 package parser
 
-import "github.com/yoheimuta/go-protoparser/v4/lexer"
+import (
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
 
 // Parser is a parser.
 type Parser struct {
@@ -8,6 +11,14 @@ type Parser struct {
 
 	permissive            bool
 	bodyIncludingComments bool
+	trivia                bool
+	recovery              bool
+	optionValue           bool
+	optionName            bool
+	spans                 bool
+
+	// errors are the ones recorded in the recovery mode.
+	errors Errors
 }
 
 // ConfigOption is an option for Parser.
@@ -28,6 +39,50 @@ func WithBodyIncludingComments(bodyIncludingComments bool) ConfigOption {
 	}
 }
 
+// WithTrivia is an option to record the whitespace and the verbatim source text of each element into Meta.Trivia.
+// It lets the printer reproduce the original source byte for byte.
+// It implies WithBodyIncludingComments(true) so that every comment is kept as an element.
+// The trivia is recorded only by ParseProto.
+func WithTrivia(trivia bool) ConfigOption {
+	return func(p *Parser) {
+		p.trivia = trivia
+	}
+}
+
+// WithRecovery is an option to keep parsing after an error.
+// The parser skips the broken statement up to the next ";" or "}" and goes on with the following statements.
+// ParseProto returns the partially parsed Proto along with Errors which holds every error found.
+func WithRecovery(recovery bool) ConfigOption {
+	return func(p *Parser) {
+		p.recovery = recovery
+	}
+}
+
+// WithOptionValue is an option to set the structured value of each option constant into Value
+// of Option, FieldOption and EnumValueOption.
+func WithOptionValue(optionValue bool) ConfigOption {
+	return func(p *Parser) {
+		p.optionValue = optionValue
+	}
+}
+
+// WithOptionName is an option to set the structured name of each option into Name
+// of Option, FieldOption and EnumValueOption.
+func WithOptionName(optionName bool) ConfigOption {
+	return func(p *Parser) {
+		p.optionName = optionName
+	}
+}
+
+// WithSpans is an option to record the ranges of the sub-elements, like the names, the types and the numbers,
+// into the fields named with the suffix Span, and Meta.End of each element.
+// Meta.End is recorded only by ParseProto.
+func WithSpans(spans bool) ConfigOption {
+	return func(p *Parser) {
+		p.spans = spans
+	}
+}
+
 // NewParser creates a new Parser.
 func NewParser(lex *lexer.Lexer, opts ...ConfigOption) *Parser {
 	p := &Parser{
@@ -36,9 +91,21 @@ func NewParser(lex *lexer.Lexer, opts ...ConfigOption) *Parser {
 	for _, opt := range opts {
 		opt(p)
 	}
+	if p.trivia {
+		p.bodyIncludingComments = true
+	}
+	if p.trivia || p.spans {
+		// Only the trivia and the end of each element are recorded from the source text.
+		p.lex.KeepSource()
+	}
 	return p
 }
 
+// ColumnEncoding returns the unit counted by the column of the positions in the parsed elements.
+func (p *Parser) ColumnEncoding() meta.Encoding {
+	return p.lex.ColumnEncoding()
+}
+
 // IsEOF checks whether the lex's read buffer is empty.
 func (p *Parser) IsEOF() bool {
 	p.lex.Next()
@@ -1,11 +1,17 @@
 package parser
 
-import "github.com/yoheimuta/go-protoparser/v4/lexer/scanner"
+import (
+	"github.com/yoheimuta/go-protoparser/v4/lexer/scanner"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
 
 // ProtoMeta represents a meta information about the Proto.
 type ProtoMeta struct {
 	// Filename is a name of file, if any.
 	Filename string
+	// Trivia holds the whitespace placed at the end of the file in Trailing.
+	// It is set only when the parser runs with the trivia option.
+	Trivia *meta.Trivia
 }
 
 // Proto represents a protocol buffer definition.
@@ -32,17 +38,20 @@ func (p *Proto) Accept(v Visitor) {
 }
 
 // ParseProto parses the proto.
+// When the parser runs with the recovery option, it returns the partially parsed Proto along with Errors.
+// The trivia and Meta.End are not recorded in that case.
 //
 //	proto = [syntax] [edition] { import | package | option | topLevelDef | emptyStatement }
 //
 // See https://developers.google.com/protocol-buffers/docs/reference/proto3-spec#proto_file
 // See https://protobuf.dev/reference/protobuf/edition-2023-spec/#proto_file
 func (p *Parser) ParseProto() (*Proto, error) {
+	p.errors = nil
 	p.parseBOM()
 
 	comments := p.ParseComments()
 	syntax, err := p.ParseSyntax()
-	if err != nil {
+	if err != nil && !p.recoverStatement(err, false) {
 		return nil, err
 	}
 	if syntax != nil {
@@ -51,7 +60,7 @@ func (p *Parser) ParseProto() (*Proto, error) {
 	}
 
 	edition, err := p.ParseEdition()
-	if err != nil {
+	if err != nil && !p.recoverStatement(err, false) {
 		return nil, err
 	}
 	if edition != nil {
@@ -59,19 +68,32 @@ func (p *Parser) ParseProto() (*Proto, error) {
 		p.MaybeScanInlineComment(edition)
 	}
 
-	protoBody, err := p.parseProtoBody()
+	if syntax != nil || edition != nil {
+		comments = nil
+	}
+	protoBody, err := p.parseProtoBody(comments)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Proto{
+	proto := &Proto{
 		Syntax:    syntax,
 		Edition:   edition,
 		ProtoBody: protoBody,
 		Meta: &ProtoMeta{
 			Filename: p.lex.Pos.Filename,
 		},
-	}, nil
+	}
+	if 0 < len(p.errors) {
+		return proto, p.errors
+	}
+	if p.trivia {
+		newTriviaRecorder(p.lex.Source()).recordProto(proto)
+	}
+	if p.spans {
+		newSpanRecorder(p.lex.Source(), p.ColumnEncoding()).recordProto(proto)
+	}
+	return proto, nil
 }
 
 // See https://protobuf.com/docs/language-spec#source-code-representation
@@ -85,12 +107,13 @@ func (p *Parser) parseBOM() {
 
 // protoBody = { import | package | option | topLevelDef | emptyStatement }
 // topLevelDef = message | enum | service | extend
+// The given comments are the leading ones of the first element.
 // See https://developers.google.com/protocol-buffers/docs/reference/proto3-spec#proto_file
-func (p *Parser) parseProtoBody() ([]Visitee, error) {
+func (p *Parser) parseProtoBody(comments []*Comment) ([]Visitee, error) {
 	var protoBody []Visitee
 
 	for {
-		comments := p.ParseComments()
+		comments = append(comments, p.ParseComments()...)
 
 		if p.IsEOF() {
 			if p.bodyIncludingComments {
@@ -114,6 +137,9 @@ func (p *Parser) parseProtoBody() ([]Visitee, error) {
 		case scanner.TIMPORT:
 			importValue, err := p.ParseImport()
 			if err != nil {
+				if p.recoverStatement(err, false) {
+					continue
+				}
 				return nil, err
 			}
 			importValue.Comments = comments
@@ -121,6 +147,9 @@ func (p *Parser) parseProtoBody() ([]Visitee, error) {
 		case scanner.TPACKAGE:
 			packageValue, err := p.ParsePackage()
 			if err != nil {
+				if p.recoverStatement(err, false) {
+					continue
+				}
 				return nil, err
 			}
 			packageValue.Comments = comments
@@ -128,6 +157,9 @@ func (p *Parser) parseProtoBody() ([]Visitee, error) {
 		case scanner.TOPTION:
 			option, err := p.ParseOption()
 			if err != nil {
+				if p.recoverStatement(err, false) {
+					continue
+				}
 				return nil, err
 			}
 			option.Comments = comments
@@ -135,6 +167,9 @@ func (p *Parser) parseProtoBody() ([]Visitee, error) {
 		case scanner.TMESSAGE:
 			message, err := p.ParseMessage()
 			if err != nil {
+				if p.recoverStatement(err, false) {
+					continue
+				}
 				return nil, err
 			}
 			message.Comments = comments
@@ -142,6 +177,9 @@ func (p *Parser) parseProtoBody() ([]Visitee, error) {
 		case scanner.TENUM:
 			enum, err := p.ParseEnum()
 			if err != nil {
+				if p.recoverStatement(err, false) {
+					continue
+				}
 				return nil, err
 			}
 			enum.Comments = comments
@@ -149,6 +187,9 @@ func (p *Parser) parseProtoBody() ([]Visitee, error) {
 		case scanner.TSERVICE:
 			service, err := p.ParseService()
 			if err != nil {
+				if p.recoverStatement(err, false) {
+					continue
+				}
 				return nil, err
 			}
 			service.Comments = comments
@@ -156,6 +197,9 @@ func (p *Parser) parseProtoBody() ([]Visitee, error) {
 		case scanner.TEXTEND:
 			extend, err := p.ParseExtend()
 			if err != nil {
+				if p.recoverStatement(err, false) {
+					continue
+				}
 				return nil, err
 			}
 			extend.Comments = comments
@@ -163,12 +207,21 @@ func (p *Parser) parseProtoBody() ([]Visitee, error) {
 		default:
 			err := p.lex.ReadEmptyStatement()
 			if err != nil {
+				if p.recoverStatement(err, false) {
+					continue
+				}
 				return nil, err
 			}
-			protoBody = append(protoBody, &EmptyStatement{})
+			stmt = p.newEmptyStatement()
 		}
 
+		if _, ok := stmt.(*EmptyStatement); ok && p.bodyIncludingComments {
+			for _, comment := range comments {
+				protoBody = append(protoBody, Visitee(comment))
+			}
+		}
 		p.MaybeScanInlineComment(stmt)
 		protoBody = append(protoBody, stmt)
+		comments = nil
 	}
 }
@@ -2711,6 +2711,48 @@ message Foo {
 				Meta: &parser.ProtoMeta{},
 			},
 		},
+		{
+			name: "parsing an emptyStatement in the proto body",
+			input: `
+syntax = "proto3";
+;
+`,
+			wantProto: &parser.Proto{
+				Syntax: &parser.Syntax{
+					ProtobufVersion:      "proto3",
+					ProtobufVersionQuote: `"proto3"`,
+					Meta: meta.Meta{
+						Pos: meta.Position{
+							Offset: 1,
+							Line:   2,
+							Column: 1,
+						},
+						LastPos: meta.Position{
+							Offset: 18,
+							Line:   2,
+							Column: 18,
+						},
+					},
+				},
+				ProtoBody: []parser.Visitee{
+					&parser.EmptyStatement{
+						Meta: meta.Meta{
+							Pos: meta.Position{
+								Offset: 20,
+								Line:   3,
+								Column: 1,
+							},
+							LastPos: meta.Position{
+								Offset: 20,
+								Line:   3,
+								Column: 1,
+							},
+						},
+					},
+				},
+				Meta: &parser.ProtoMeta{},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer/scanner"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// Errors is the error type returned by ParseProto when the parser runs with the recovery option.
+// It holds every error found in the source, in the order they were found.
+type Errors []*meta.Error
+
+func (e Errors) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", err.Pos, err))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// recoverStatement records the error and skips the rest of the broken statement
+// if the parser runs with the recovery option.
+// It returns false if the error must be returned as is.
+// inBlock is true if the statement is placed in a body surrounded by curly brackets.
+func (p *Parser) recoverStatement(err error, inBlock bool) bool {
+	if !p.recovery {
+		return false
+	}
+	p.report(err)
+	p.skipStatement(inBlock)
+	return true
+}
+
+// recoverUnclosedBody reports the missing right curly bracket if the body reaches
+// the end of the input when the parser runs with the recovery option.
+// The given token is the one to be parsed next.
+func (p *Parser) recoverUnclosedBody(token scanner.Token) bool {
+	if !p.recovery || token != scanner.TEOF {
+		return false
+	}
+	p.lex.Next()
+	p.report(p.unexpected("}"))
+	return true
+}
+
+// report records the error.
+// An error placed at the same position as a recorded one is ignored.
+func (p *Parser) report(err error) {
+	e := p.metaError(err)
+	for _, reported := range p.errors {
+		if reported.Pos == e.Pos {
+			return
+		}
+	}
+	p.errors = append(p.errors, e)
+}
+
+// metaError converts the error into *meta.Error.
+func (p *Parser) metaError(err error) *meta.Error {
+	var e *meta.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &meta.Error{
+		Pos:   p.lex.Pos.Position,
+		Found: err.Error(),
+	}
+}
+
+// farthest returns the error placed at the farthest position.
+// It is used to pick the most relevant one from the errors of alternative statements.
+func farthest(errs ...error) error {
+	var found error
+	var foundPos int
+	for _, err := range errs {
+		var e *meta.Error
+		if !errors.As(err, &e) {
+			continue
+		}
+		if found == nil || foundPos < e.Pos.Offset {
+			found = err
+			foundPos = e.Pos.Offset
+		}
+	}
+	if found == nil {
+		return errs[0]
+	}
+	return found
+}
+
+// skipStatement skips the tokens until the end of the broken statement.
+//
+// The statement ends with a semicolon or with a block surrounded by curly brackets.
+// A right curly bracket which closes the enclosing body is left unread if inBlock is true.
+func (p *Parser) skipStatement(inBlock bool) {
+	depth := 0
+	switch p.lex.Token {
+	case scanner.TSEMICOLON:
+		return
+	case scanner.TRIGHTCURLY:
+		if inBlock {
+			p.lex.UnNext()
+		}
+		return
+	case scanner.TLEFTCURLY:
+		depth++
+	}
+
+	for {
+		p.lex.Next()
+		switch p.lex.Token {
+		case scanner.TEOF:
+			return
+		case scanner.TSEMICOLON:
+			if depth == 0 {
+				return
+			}
+		case scanner.TLEFTCURLY:
+			depth++
+		case scanner.TRIGHTCURLY:
+			if depth == 0 {
+				if inBlock {
+					p.lex.UnNext()
+				}
+				return
+			}
+			depth--
+			if depth == 0 {
+				p.lex.ConsumeToken(scanner.TSEMICOLON)
+				return
+			}
+		}
+	}
+}
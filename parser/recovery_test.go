@@ -0,0 +1,189 @@
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func TestParser_ParseProto_recovery(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantErrorsPos []string
+		wantNames     []string
+	}{
+		{
+			name: "parsing an excerpt without errors",
+			input: `syntax = "proto3";
+message A {
+  int32 a = 1;
+}
+`,
+			wantNames: []string{"A"},
+		},
+		{
+			name: "parsing an excerpt with errors in bodies",
+			input: `syntax = "proto3";
+message A {
+  int32 a = 1
+  string b = 2;
+  int32 = 3;
+  oneof o {
+    int32 d = x;
+    int32 e = 5;
+  }
+}
+enum E {
+  X = 0;
+  Y 1;
+}
+service S {
+  rpc R(A) returns (A) { option = 1; }
+  rpc Q(A) returns (A);
+}
+`,
+			wantErrorsPos: []string{
+				"<input>:4:3",
+				"<input>:5:9",
+				"<input>:7:15",
+				"<input>:13:5",
+				"<input>:16:33",
+			},
+			wantNames: []string{"A", "E", "S"},
+		},
+		{
+			name: "parsing an excerpt with errors at the top level",
+			input: `syntax = proto3;
+}
+message { int32 a = 1; }
+message B {}
+import "other.proto"
+message C {}
+message D {}
+`,
+			wantErrorsPos: []string{
+				"<input>:1:10",
+				"<input>:2:1",
+				"<input>:3:9",
+				"<input>:6:1",
+			},
+			wantNames: []string{"B", "D"},
+		},
+		{
+			name: "parsing an excerpt which ends in the middle of nested bodies",
+			input: `message A {
+  message B {
+    int32 b = 1;
+`,
+			wantErrorsPos: []string{
+				"<input>:4:1",
+			},
+			wantNames: []string{"A"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p := parser.NewParser(
+				lexer.NewLexer(strings.NewReader(test.input)),
+				parser.WithRecovery(true),
+			)
+			got, err := p.ParseProto()
+
+			var gotErrorsPos []string
+			if err != nil {
+				errs, ok := err.(parser.Errors)
+				if !ok {
+					t.Fatalf("got err %T, but want parser.Errors", err)
+				}
+				for _, e := range errs {
+					gotErrorsPos = append(gotErrorsPos, e.Pos.String())
+				}
+			}
+			if !reflect.DeepEqual(gotErrorsPos, test.wantErrorsPos) {
+				t.Errorf("got %v, but want %v", gotErrorsPos, test.wantErrorsPos)
+			}
+
+			if got == nil {
+				t.Fatalf("got nil, but want the partially parsed proto")
+			}
+			var gotNames []string
+			for _, body := range got.ProtoBody {
+				switch b := body.(type) {
+				case *parser.Message:
+					gotNames = append(gotNames, b.MessageName)
+				case *parser.Enum:
+					gotNames = append(gotNames, b.EnumName)
+				case *parser.Service:
+					gotNames = append(gotNames, b.ServiceName)
+				}
+			}
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("got %v, but want %v", gotNames, test.wantNames)
+			}
+		})
+	}
+}
+
+func TestParser_ParseProto_recoveryKeepsPartialBodies(t *testing.T) {
+	input := `message A {
+  int32 a = 1;
+  int32 = 2;
+  message B {
+    int32 b = 1
+  }
+  string c = 3;
+}
+`
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(input)), parser.WithRecovery(true))
+	got, err := p.ParseProto()
+	errs, ok := err.(parser.Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("got err %v, but want 2 errors", err)
+	}
+	wantMessage := "<input>:3:9: "
+	if !strings.HasPrefix(err.Error(), wantMessage) {
+		t.Errorf("got %q, but want the prefix %q", err.Error(), wantMessage)
+	}
+	if len(strings.Split(err.Error(), "\n")) != 2 {
+		t.Errorf("got %q, but want one line per error", err.Error())
+	}
+
+	message := got.ProtoBody[0].(*parser.Message)
+	if len(message.MessageBody) != 3 {
+		t.Fatalf("got %d, but want 3", len(message.MessageBody))
+	}
+	if name := message.MessageBody[0].(*parser.Field).FieldName; name != "a" {
+		t.Errorf("got %v, but want a", name)
+	}
+	if name := message.MessageBody[1].(*parser.Message).MessageName; name != "B" {
+		t.Errorf("got %v, but want B", name)
+	}
+	if name := message.MessageBody[2].(*parser.Field).FieldName; name != "c" {
+		t.Errorf("got %v, but want c", name)
+	}
+}
+
+func TestParser_ParseProto_withoutRecovery(t *testing.T) {
+	input := `message A {
+  int32 = 1;
+  int32 = 2;
+}
+`
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(input)))
+	got, err := p.ParseProto()
+	if err == nil {
+		t.Fatalf("got nil, but want an error")
+	}
+	if _, ok := err.(parser.Errors); ok {
+		t.Errorf("got parser.Errors, but want the first error only")
+	}
+	if got != nil {
+		t.Errorf("got %v, but want nil", got)
+	}
+}
@@ -16,10 +16,18 @@ func (e *parseReservedErr) Error() string {
 	return fmt.Sprintf("%v:%v", e.parseRangesErr, e.parseFieldNamesErr)
 }
 
+// Unwrap returns the error of the alternative which was parsed farthest.
+func (e *parseReservedErr) Unwrap() error {
+	return farthest(e.parseRangesErr, e.parseFieldNamesErr)
+}
+
 // Range is a range of field numbers. End is an optional value.
 type Range struct {
 	Begin string
 	End   string
+	// BeginSpan and EndSpan are the ranges of Begin and End. They are set with the spans option.
+	BeginSpan meta.Span
+	EndSpan   meta.Span
 }
 
 // Reserved declares a range of field numbers or field names that cannot be used in this message.
@@ -27,6 +35,8 @@ type Range struct {
 type Reserved struct {
 	Ranges     []*Range
 	FieldNames []string
+	// FieldNameSpans are the ranges of FieldNames. They are set with the spans option.
+	FieldNameSpans []meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -69,24 +79,24 @@ func (p *Parser) ParseReserved() (*Reserved, error) {
 	}
 	startPos := p.lex.Pos
 
-	parse := func() ([]*Range, []string, error) {
+	parse := func() ([]*Range, []string, []meta.Span, error) {
 		ranges, err := p.parseRanges()
 		if err == nil {
-			return ranges, nil, nil
+			return ranges, nil, nil, nil
 		}
 
-		fieldNames, ferr := p.parseFieldNames()
+		fieldNames, fieldNameSpans, ferr := p.parseFieldNames()
 		if ferr == nil {
-			return nil, fieldNames, nil
+			return nil, fieldNames, fieldNameSpans, nil
 		}
 
-		return nil, nil, &parseReservedErr{
+		return nil, nil, nil, &parseReservedErr{
 			parseRangesErr:     err,
 			parseFieldNamesErr: ferr,
 		}
 	}
 
-	ranges, fieldNames, err := parse()
+	ranges, fieldNames, fieldNameSpans, err := parse()
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +110,8 @@ func (p *Parser) ParseReserved() (*Reserved, error) {
 		Ranges:     ranges,
 		FieldNames: fieldNames,
 		Meta:       meta.Meta{Pos: startPos.Position, LastPos: p.lex.Pos.Position},
+
+		FieldNameSpans: fieldNameSpans,
 	}, nil
 }
 
@@ -138,12 +150,14 @@ func (p *Parser) parseRange() (*Range, error) {
 		return nil, p.unexpected("intLit")
 	}
 	begin := p.lex.Text
+	beginSpan := p.span(p.lex.Pos.Position)
 
 	p.lex.Next()
 	if p.lex.Text != "to" {
 		p.lex.UnNext()
 		return &Range{
-			Begin: begin,
+			Begin:     begin,
+			BeginSpan: beginSpan,
 		}, nil
 	}
 
@@ -152,8 +166,10 @@ func (p *Parser) parseRange() (*Range, error) {
 	case p.lex.Token == scanner.TINTLIT,
 		p.lex.Text == "max":
 		return &Range{
-			Begin: begin,
-			End:   p.lex.Text,
+			Begin:     begin,
+			End:       p.lex.Text,
+			BeginSpan: beginSpan,
+			EndSpan:   p.span(p.lex.Pos.Position),
 		}, nil
 	default:
 		break
@@ -166,14 +182,21 @@ func (p *Parser) parseRange() (*Range, error) {
 // Note: While the spec requires commas between field names, this parser also supports
 // field names separated by whitespace without commas, which is not mentioned in the spec
 // but is supported by protoc and other parsers.
-func (p *Parser) parseFieldNames() ([]string, error) {
+func (p *Parser) parseFieldNames() ([]string, []meta.Span, error) {
 	var fieldNames []string
+	var spans []meta.Span
+	add := func(fieldName string) {
+		fieldNames = append(fieldNames, fieldName)
+		if p.spans {
+			spans = append(spans, p.span(p.lex.Pos.Position))
+		}
+	}
 
 	fieldName, err := p.parseFieldName()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	fieldNames = append(fieldNames, fieldName)
+	add(fieldName)
 
 	for {
 		// Check if next token is a comma
@@ -182,9 +205,9 @@ func (p *Parser) parseFieldNames() ([]string, error) {
 			// If it's a comma, parse the next field name
 			fieldName, err = p.parseFieldName()
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
-			fieldNames = append(fieldNames, fieldName)
+			add(fieldName)
 		} else {
 			// If it's not a comma, put it back and try to parse another field name
 			p.lex.UnNext()
@@ -197,10 +220,10 @@ func (p *Parser) parseFieldNames() ([]string, error) {
 			}
 
 			// Successfully parsed another field name
-			fieldNames = append(fieldNames, nextFieldName)
+			add(nextFieldName)
 		}
 	}
-	return fieldNames, nil
+	return fieldNames, spans, nil
 }
 
 // fieldName = quote + fieldName + quote
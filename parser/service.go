@@ -9,6 +9,8 @@ import (
 type RPCRequest struct {
 	IsStream    bool
 	MessageType string
+	// MessageTypeSpan is the range of MessageType. It is set with the spans option.
+	MessageTypeSpan meta.Span
 
 	// Meta is the meta information.
 	Meta meta.Meta
@@ -18,6 +20,8 @@ type RPCRequest struct {
 type RPCResponse struct {
 	IsStream    bool
 	MessageType string
+	// MessageTypeSpan is the range of MessageType. It is set with the spans option.
+	MessageTypeSpan meta.Span
 
 	// Meta is the meta information.
 	Meta meta.Meta
@@ -29,6 +33,8 @@ type RPC struct {
 	RPCRequest  *RPCRequest
 	RPCResponse *RPCResponse
 	Options     []*Option
+	// RPCNameSpan is the range of RPCName. It is set with the spans option.
+	RPCNameSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -64,6 +70,8 @@ func (r *RPC) Accept(v Visitor) {
 // Service consists of RPCs.
 type Service struct {
 	ServiceName string
+	// ServiceNameSpan is the range of ServiceName. It is set with the spans option.
+	ServiceNameSpan meta.Span
 	// ServiceBody can have options and rpcs.
 	ServiceBody []Visitee
 
@@ -119,6 +127,7 @@ func (p *Parser) ParseService() (*Service, error) {
 		return nil, p.unexpected("serviceName")
 	}
 	serviceName := p.lex.Text
+	serviceNameSpan := p.span(p.lex.Pos.Position)
 
 	serviceBody, inlineLeftCurly, lastPos, err := p.parseServiceBody()
 	if err != nil {
@@ -127,6 +136,7 @@ func (p *Parser) ParseService() (*Service, error) {
 
 	return &Service{
 		ServiceName:                  serviceName,
+		ServiceNameSpan:              serviceNameSpan,
 		ServiceBody:                  serviceBody,
 		InlineCommentBehindLeftCurly: inlineLeftCurly,
 		Meta: meta.Meta{
@@ -158,6 +168,9 @@ func (p *Parser) parseServiceBody() (
 		p.lex.NextKeyword()
 		token := p.lex.Token
 		p.lex.UnNext()
+		if p.recoverUnclosedBody(token) {
+			return stmts, inlineLeftCurly, p.lex.Pos, nil
+		}
 
 		var stmt interface {
 			HasInlineCommentSetter
@@ -185,6 +198,9 @@ func (p *Parser) parseServiceBody() (
 		case scanner.TOPTION:
 			option, err := p.ParseOption()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			option.Comments = comments
@@ -192,6 +208,9 @@ func (p *Parser) parseServiceBody() (
 		case scanner.TRPC:
 			rpc, err := p.parseRPC()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
 			rpc.Comments = comments
@@ -199,11 +218,19 @@ func (p *Parser) parseServiceBody() (
 		default:
 			err := p.lex.ReadEmptyStatement()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					continue
+				}
 				return nil, nil, scanner.Position{}, err
 			}
-			stmt = &EmptyStatement{}
+			stmt = p.newEmptyStatement()
 		}
 
+		if _, ok := stmt.(*EmptyStatement); ok && p.bodyIncludingComments {
+			for _, comment := range comments {
+				stmts = append(stmts, Visitee(comment))
+			}
+		}
 		p.MaybeScanInlineComment(stmt)
 		stmts = append(stmts, stmt)
 	}
@@ -224,6 +251,7 @@ func (p *Parser) parseRPC() (*RPC, error) {
 		return nil, p.unexpected("serviceName")
 	}
 	rpcName := p.lex.Text
+	rpcNameSpan := p.span(p.lex.Pos.Position)
 
 	rpcRequest, err := p.parseRPCRequest()
 	if err != nil {
@@ -268,6 +296,7 @@ func (p *Parser) parseRPC() (*RPC, error) {
 
 	return &RPC{
 		RPCName:                      rpcName,
+		RPCNameSpan:                  rpcNameSpan,
 		RPCRequest:                   rpcRequest,
 		RPCResponse:                  rpcResponse,
 		Options:                      opts,
@@ -296,10 +325,11 @@ func (p *Parser) parseRPCRequest() (*RPCRequest, error) {
 		p.lex.UnNext()
 	}
 
-	messageType, _, err := p.lex.ReadMessageType()
+	messageType, messageTypePos, err := p.lex.ReadMessageType()
 	if err != nil {
 		return nil, err
 	}
+	messageTypeSpan := p.span(messageTypePos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TRIGHTPAREN {
@@ -309,7 +339,13 @@ func (p *Parser) parseRPCRequest() (*RPCRequest, error) {
 	return &RPCRequest{
 		IsStream:    isStream,
 		MessageType: messageType,
-		Meta:        meta.Meta{Pos: startPos.Position, LastPos: p.lex.Pos.Position},
+		Meta: meta.Meta{
+			Pos:     startPos.Position,
+			LastPos: p.lex.Pos.Position,
+			End:     p.span(startPos.Position).End,
+		},
+
+		MessageTypeSpan: messageTypeSpan,
 	}, nil
 }
 
@@ -329,10 +365,11 @@ func (p *Parser) parseRPCResponse() (*RPCResponse, error) {
 		p.lex.UnNext()
 	}
 
-	messageType, _, err := p.lex.ReadMessageType()
+	messageType, messageTypePos, err := p.lex.ReadMessageType()
 	if err != nil {
 		return nil, err
 	}
+	messageTypeSpan := p.span(messageTypePos.Position)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TRIGHTPAREN {
@@ -342,7 +379,13 @@ func (p *Parser) parseRPCResponse() (*RPCResponse, error) {
 	return &RPCResponse{
 		IsStream:    isStream,
 		MessageType: messageType,
-		Meta:        meta.Meta{Pos: startPos.Position, LastPos: p.lex.Pos.Position},
+		Meta: meta.Meta{
+			Pos:     startPos.Position,
+			LastPos: p.lex.Pos.Position,
+			End:     p.span(startPos.Position).End,
+		},
+
+		MessageTypeSpan: messageTypeSpan,
 	}, nil
 }
 
@@ -361,11 +404,17 @@ func (p *Parser) parseRPCOptions() ([]*Option, *Comment, error) {
 		p.lex.NextKeyword()
 		token := p.lex.Token
 		p.lex.UnNext()
+		if p.recoverUnclosedBody(token) {
+			return options, inlineLeftCurly, nil
+		}
 
 		switch token {
 		case scanner.TOPTION:
 			option, err := p.ParseOption()
 			if err != nil {
+				if p.recoverStatement(err, true) {
+					break
+				}
 				return nil, nil, err
 			}
 			options = append(options, option)
@@ -374,7 +423,7 @@ func (p *Parser) parseRPCOptions() ([]*Option, *Comment, error) {
 			break
 		default:
 			err := p.lex.ReadEmptyStatement()
-			if err != nil {
+			if err != nil && !p.recoverStatement(err, true) {
 				return nil, nil, err
 			}
 		}
@@ -1136,7 +1136,20 @@ service MyService {
 							},
 						},
 					},
-					&parser.EmptyStatement{},
+					&parser.EmptyStatement{
+						Meta: meta.Meta{
+							Pos: meta.Position{
+								Offset: 67,
+								Line:   3,
+								Column: 47,
+							},
+							LastPos: meta.Position{
+								Offset: 67,
+								Line:   3,
+								Column: 47,
+							},
+						},
+					},
 				},
 				Meta: meta.Meta{
 					Pos: meta.Position{
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"unicode/utf8"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer/scanner"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// tokenEnd returns the position just after the last read token.
+func (p *Parser) tokenEnd() meta.Position {
+	pos := p.lex.Pos.Position
+	if p.lex.Token == scanner.TILLEGAL {
+		// The token was put back. The position is already the one just after the previous token.
+		return pos
+	}
+	pos.Offset += len(p.lex.Text)
+	for _, r := range p.lex.Text {
+		pos.Column += p.lex.Pos.Encoding().Len(r)
+	}
+	return pos
+}
+
+// lastTokenEnd returns the position of the last character of the last read token.
+func (p *Parser) lastTokenEnd() meta.Position {
+	pos := p.tokenEnd()
+	if p.lex.Token == scanner.TILLEGAL {
+		// The previous token is unknown. Its last character is assumed to take a byte and a column.
+		pos.Offset--
+		pos.Column--
+		return pos
+	}
+	if r, size := utf8.DecodeLastRuneInString(p.lex.Text); 0 < size {
+		pos.Offset -= size
+		pos.Column -= p.lex.Pos.Encoding().Len(r)
+	}
+	return pos
+}
+
+// span returns the range from start to the end of the last read token.
+// It returns the zero value unless the parser runs with the spans option.
+func (p *Parser) span(start meta.Position) meta.Span {
+	if !p.spans {
+		return meta.Span{}
+	}
+	return meta.Span{Pos: start, End: p.tokenEnd()}
+}
+
+// spanRecorder records Meta.End of each element from the source.
+type spanRecorder struct {
+	src      string
+	encoding meta.Encoding
+}
+
+func newSpanRecorder(src string, encoding meta.Encoding) *spanRecorder {
+	return &spanRecorder{
+		src:      src,
+		encoding: encoding,
+	}
+}
+
+func (r *spanRecorder) recordProto(proto *Proto) {
+	(&metaWalker{visit: r.record}).walkProto(proto)
+}
+
+// record sets the position just after the character at LastPos to m.End.
+func (r *spanRecorder) record(m *meta.Meta, _ byte) {
+	end := m.LastPos
+	if end.Offset < len(r.src) {
+		ch, size := utf8.DecodeRuneInString(r.src[end.Offset:])
+		end.Offset += size
+		end.Column += r.encoding.Len(ch)
+	}
+	m.End = end
+}
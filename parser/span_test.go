@@ -0,0 +1,241 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+func TestParser_ParseProto_spans(t *testing.T) {
+	input := `syntax = "proto2";
+package foo.bar;
+import public "other.proto";
+option (my_option).a = true;
+// 🎉 M is a message.
+message M {
+  optional .foo.Bar f = 1 [deprecated = true];
+  map<string, Project> projects = 3;
+  optional group Result = 4 {}
+  oneof o { string s = 5; }
+  reserved 2, 15 to max;
+  reserved "foo", "bar";
+  extensions 100 to 199 [declaration = { number: 100, full_name: ".foo.ext", type: ".foo.Ext" }];
+}
+enum E { A = -1 [(x) = "y"]; }
+extend M { optional int32 ext = 100; }
+service S {
+  rpc Get(stream Req) returns (foo.Res) {}
+}
+`
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(input)), parser.WithSpans(true))
+	proto, err := p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	pkg := proto.ProtoBody[0].(*parser.Package)
+	imp := proto.ProtoBody[1].(*parser.Import)
+	option := proto.ProtoBody[2].(*parser.Option)
+	message := proto.ProtoBody[3].(*parser.Message)
+	field := message.MessageBody[0].(*parser.Field)
+	mapField := message.MessageBody[1].(*parser.MapField)
+	group := message.MessageBody[2].(*parser.GroupField)
+	oneof := message.MessageBody[3].(*parser.Oneof)
+	ranges := message.MessageBody[4].(*parser.Reserved)
+	names := message.MessageBody[5].(*parser.Reserved)
+	extensions := message.MessageBody[6].(*parser.Extensions)
+	enum := proto.ProtoBody[4].(*parser.Enum)
+	enumField := enum.EnumBody[0].(*parser.EnumField)
+	extend := proto.ProtoBody[5].(*parser.Extend)
+	service := proto.ProtoBody[6].(*parser.Service)
+	rpc := service.ServiceBody[0].(*parser.RPC)
+
+	tests := []struct {
+		name     string
+		span     meta.Span
+		wantText string
+	}{
+		{name: "syntax version", span: proto.Syntax.ProtobufVersionSpan, wantText: `"proto2"`},
+		{name: "syntax", span: proto.Syntax.Meta.Span(), wantText: `syntax = "proto2";`},
+		{name: "package name", span: pkg.NameSpan, wantText: "foo.bar"},
+		{name: "import location", span: imp.LocationSpan, wantText: `"other.proto"`},
+		{name: "option name", span: option.OptionNameSpan, wantText: "(my_option).a"},
+		{name: "option constant", span: option.ConstantSpan, wantText: "true"},
+		{name: "comment", span: message.Comments[0].Meta.Span(), wantText: "// 🎉 M is a message."},
+		{name: "message name", span: message.MessageNameSpan, wantText: "M"},
+		{name: "field type", span: field.TypeSpan, wantText: ".foo.Bar"},
+		{name: "field name", span: field.FieldNameSpan, wantText: "f"},
+		{name: "field number", span: field.FieldNumberSpan, wantText: "1"},
+		{name: "field option name", span: field.FieldOptions[0].OptionNameSpan, wantText: "deprecated"},
+		{name: "field option constant", span: field.FieldOptions[0].ConstantSpan, wantText: "true"},
+		{name: "field", span: field.Meta.Span(), wantText: "optional .foo.Bar f = 1 [deprecated = true];"},
+		{name: "map key type", span: mapField.KeyTypeSpan, wantText: "string"},
+		{name: "map type", span: mapField.TypeSpan, wantText: "Project"},
+		{name: "map name", span: mapField.MapNameSpan, wantText: "projects"},
+		{name: "map number", span: mapField.FieldNumberSpan, wantText: "3"},
+		{name: "group name", span: group.GroupNameSpan, wantText: "Result"},
+		{name: "group number", span: group.FieldNumberSpan, wantText: "4"},
+		{name: "oneof name", span: oneof.OneofNameSpan, wantText: "o"},
+		{name: "oneof field type", span: oneof.OneofFields[0].TypeSpan, wantText: "string"},
+		{name: "oneof field name", span: oneof.OneofFields[0].FieldNameSpan, wantText: "s"},
+		{name: "oneof field number", span: oneof.OneofFields[0].FieldNumberSpan, wantText: "5"},
+		{name: "oneof", span: oneof.Meta.Span(), wantText: "oneof o { string s = 5; }"},
+		{name: "reserved range begin", span: ranges.Ranges[0].BeginSpan, wantText: "2"},
+		{name: "reserved range end", span: ranges.Ranges[1].EndSpan, wantText: "max"},
+		{name: "reserved field name", span: names.FieldNameSpans[1], wantText: `"bar"`},
+		{name: "extensions range end", span: extensions.Ranges[0].EndSpan, wantText: "199"},
+		{name: "declaration number", span: extensions.Declarations[0].NumberSpan, wantText: "100"},
+		{name: "declaration full name", span: extensions.Declarations[0].FullNameSpan, wantText: `".foo.ext"`},
+		{name: "declaration type", span: extensions.Declarations[0].TypeSpan, wantText: `".foo.Ext"`},
+		{name: "enum name", span: enum.EnumNameSpan, wantText: "E"},
+		{name: "enum field ident", span: enumField.IdentSpan, wantText: "A"},
+		{name: "enum field number", span: enumField.NumberSpan, wantText: "-1"},
+		{name: "enum value option name", span: enumField.EnumValueOptions[0].OptionNameSpan, wantText: "(x)"},
+		{name: "enum value option constant", span: enumField.EnumValueOptions[0].ConstantSpan, wantText: `"y"`},
+		{name: "extend message type", span: extend.MessageTypeSpan, wantText: "M"},
+		{name: "service name", span: service.ServiceNameSpan, wantText: "S"},
+		{name: "rpc name", span: rpc.RPCNameSpan, wantText: "Get"},
+		{name: "rpc request type", span: rpc.RPCRequest.MessageTypeSpan, wantText: "Req"},
+		{name: "rpc request", span: rpc.RPCRequest.Meta.Span(), wantText: "(stream Req)"},
+		{name: "rpc response type", span: rpc.RPCResponse.MessageTypeSpan, wantText: "foo.Res"},
+		{name: "rpc", span: rpc.Meta.Span(), wantText: "rpc Get(stream Req) returns (foo.Res) {}"},
+		{name: "service", span: service.Meta.Span(), wantText: "service S {\n  rpc Get(stream Req) returns (foo.Res) {}\n}"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if !test.span.IsValid() {
+				t.Fatalf("got an invalid span %v", test.span)
+			}
+			got := input[test.span.Pos.Offset:test.span.End.Offset]
+			if got != test.wantText {
+				t.Errorf("got %q, but want %q", got, test.wantText)
+			}
+			if test.span.Pos.Line == test.span.End.Line {
+				gotLen := test.span.End.Column - test.span.Pos.Column
+				if wantLen := len([]rune(test.wantText)); gotLen != wantLen {
+					t.Errorf("got the length %d, but want %d", gotLen, wantLen)
+				}
+			}
+		})
+	}
+}
+
+func TestParser_ParseProto_spansColumnEncoding(t *testing.T) {
+	input := `message M {
+  string s = 1 [json_name = "🎉"];
+}
+`
+	for _, test := range []struct {
+		name     string
+		encoding meta.Encoding
+		wantEnd  meta.Position
+	}{
+		{
+			name:     "counting the runes",
+			encoding: meta.EncodingRune,
+			wantEnd:  meta.Position{Offset: 46, Line: 2, Column: 32},
+		},
+		{
+			name:     "counting the UTF-16 code units",
+			encoding: meta.EncodingUTF16,
+			wantEnd:  meta.Position{Offset: 46, Line: 2, Column: 33},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p := parser.NewParser(
+				lexer.NewLexer(strings.NewReader(input), lexer.WithColumnEncoding(test.encoding)),
+				parser.WithSpans(true),
+			)
+			proto, err := p.ParseProto()
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			message := proto.ProtoBody[0].(*parser.Message)
+			field := message.MessageBody[0].(*parser.Field)
+			got := field.FieldOptions[0].ConstantSpan.End
+			if got != test.wantEnd {
+				t.Errorf("got %v, but want %v", got, test.wantEnd)
+			}
+		})
+	}
+}
+
+func TestParser_ParseProto_withoutSpans(t *testing.T) {
+	input := `message M { int32 f = 1; }`
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(input)))
+	proto, err := p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	message := proto.ProtoBody[0].(*parser.Message)
+	field := message.MessageBody[0].(*parser.Field)
+	if message.Meta.End.Line != 0 || field.FieldNameSpan.IsValid() {
+		t.Errorf("got %v and %v, but want the zero values", message.Meta.End, field.FieldNameSpan)
+	}
+}
+
+func TestParser_ParseProto_spansOptionNameAndValue(t *testing.T) {
+	input := `option (my_option).a = { b: { c: [1, { d: "e" }] } };
+message M {
+  string s = 1 [(f.g) = { h: 2 }];
+}
+enum E { A = 0 [(x).y = true]; }
+`
+	p := parser.NewParser(
+		lexer.NewLexer(strings.NewReader(input)),
+		parser.WithPermissive(true),
+		parser.WithSpans(true),
+		parser.WithOptionName(true),
+		parser.WithOptionValue(true),
+	)
+	proto, err := p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	option := proto.ProtoBody[0].(*parser.Option)
+	b := option.Value.Fields[0]
+	c := b.Value.Fields[0]
+	d := c.Value.Elements[1].Fields[0]
+	fieldOption := proto.ProtoBody[1].(*parser.Message).MessageBody[0].(*parser.Field).FieldOptions[0]
+	enumValueOption := proto.ProtoBody[2].(*parser.Enum).EnumBody[0].(*parser.EnumField).EnumValueOptions[0]
+
+	tests := []struct {
+		name     string
+		span     meta.Span
+		wantText string
+	}{
+		{name: "option name", span: option.Name.Meta.Span(), wantText: "(my_option).a"},
+		{name: "option extension name part", span: option.Name.Parts[0].Meta.Span(), wantText: "(my_option)"},
+		{name: "option name part", span: option.Name.Parts[1].Meta.Span(), wantText: "a"},
+		{name: "option value", span: option.Value.Meta.Span(), wantText: `{ b: { c: [1, { d: "e" }] } }`},
+		{name: "message field", span: b.Meta.Span(), wantText: `b: { c: [1, { d: "e" }] }`},
+		{name: "list", span: c.Value.Meta.Span(), wantText: `[1, { d: "e" }]`},
+		{name: "list element", span: c.Value.Elements[0].Meta.Span(), wantText: "1"},
+		{name: "nested message field", span: d.Meta.Span(), wantText: `d: "e"`},
+		{name: "nested value", span: d.Value.Meta.Span(), wantText: `"e"`},
+		{name: "field option extension name part", span: fieldOption.Name.Parts[0].Meta.Span(), wantText: "(f.g)"},
+		{name: "field option value", span: fieldOption.Value.Meta.Span(), wantText: "{ h: 2 }"},
+		{name: "enum value option name part", span: enumValueOption.Name.Parts[1].Meta.Span(), wantText: "y"},
+		{name: "enum value option value", span: enumValueOption.Value.Meta.Span(), wantText: "true"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if !test.span.IsValid() {
+				t.Fatalf("got an invalid span %v", test.span)
+			}
+			got := input[test.span.Pos.Offset:test.span.End.Offset]
+			if got != test.wantText {
+				t.Errorf("got %q, but want %q", got, test.wantText)
+			}
+		})
+	}
+}
@@ -11,6 +11,8 @@ type Syntax struct {
 
 	// ProtobufVersionQuote includes quotes
 	ProtobufVersionQuote string
+	// ProtobufVersionSpan is the range of ProtobufVersionQuote. It is set with the spans option.
+	ProtobufVersionSpan meta.Span
 
 	// Comments are the optional ones placed at the beginning.
 	Comments []*Comment
@@ -75,6 +77,7 @@ func (p *Parser) ParseSyntax() (*Syntax, error) {
 		return nil, p.unexpected("quote")
 	}
 	lq := p.lex.Text
+	quotePos := p.lex.Pos.Position
 
 	p.lex.Next()
 	if p.lex.Text != "proto3" && p.lex.Text != "proto2" {
@@ -87,6 +90,7 @@ func (p *Parser) ParseSyntax() (*Syntax, error) {
 		return nil, p.unexpected("quote")
 	}
 	tq := p.lex.Text
+	versionSpan := p.span(quotePos)
 
 	p.lex.Next()
 	if p.lex.Token != scanner.TSEMICOLON {
@@ -96,6 +100,7 @@ func (p *Parser) ParseSyntax() (*Syntax, error) {
 	return &Syntax{
 		ProtobufVersion:      version,
 		ProtobufVersionQuote: lq + version + tq,
+		ProtobufVersionSpan:  versionSpan,
 		Meta: meta.Meta{
 			Pos:     startPos.Position,
 			LastPos: p.lex.Pos.Position,
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+const bom = "\uFEFF"
+
+// triviaRecorder records meta.Trivia of each element from the source.
+type triviaRecorder struct {
+	src string
+}
+
+func newTriviaRecorder(src string) *triviaRecorder {
+	return &triviaRecorder{
+		src: src,
+	}
+}
+
+func (r *triviaRecorder) recordProto(proto *Proto) {
+	(&metaWalker{visit: r.record}).walkProto(proto)
+
+	proto.Meta.Trivia = &meta.Trivia{
+		Trailing: r.src[r.skipSpaceBackward(len(r.src)):],
+	}
+}
+
+// record sets the trivia to m. closing is the token closing the element's body, or 0 if there is no body.
+func (r *triviaRecorder) record(m *meta.Meta, closing byte) {
+	start := m.Pos.Offset
+	if len(r.src) <= m.LastPos.Offset || m.LastPos.Offset < start {
+		return
+	}
+	_, size := utf8.DecodeRuneInString(r.src[m.LastPos.Offset:])
+	end := m.LastPos.Offset + size
+
+	leadingStart := r.skipSpaceBackward(start)
+	if leadingStart == len(bom) && strings.HasPrefix(r.src, bom) {
+		leadingStart = 0
+	}
+
+	trivia := &meta.Trivia{
+		Leading: r.src[leadingStart:start],
+		Text:    r.src[start:end],
+	}
+	if closing != 0 {
+		if i := strings.LastIndexByte(trivia.Text, closing); 0 <= i {
+			trivia.Trailing = r.src[r.skipSpaceBackward(start+i) : start+i]
+		}
+	}
+	m.Trivia = trivia
+}
+
+// skipSpaceBackward returns the offset of the whitespace sequence ending at the given offset.
+func (r *triviaRecorder) skipSpaceBackward(offset int) int {
+	for 0 < offset && isSpace(r.src[offset-1]) {
+		offset--
+	}
+	return offset
+}
+
+func isSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+func TestParser_ParseProto_trivia(t *testing.T) {
+	input := `syntax = "proto3";
+
+// A is a message.
+message  A {
+  int32 a = 1;  // a
+
+}
+`
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(input)), parser.WithTrivia(true))
+	proto, err := p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	message := proto.ProtoBody[0].(*parser.Message)
+	field := message.MessageBody[0].(*parser.Field)
+	tests := []struct {
+		name       string
+		gotTrivia  *meta.Trivia
+		wantTrivia *meta.Trivia
+	}{
+		{
+			name:      "recording the syntax",
+			gotTrivia: proto.Syntax.Meta.Trivia,
+			wantTrivia: &meta.Trivia{
+				Text: `syntax = "proto3";`,
+			},
+		},
+		{
+			name:      "recording the message comment",
+			gotTrivia: message.Comments[0].Meta.Trivia,
+			wantTrivia: &meta.Trivia{
+				Leading: "\n\n",
+				Text:    "// A is a message.",
+			},
+		},
+		{
+			name:      "recording the message",
+			gotTrivia: message.Meta.Trivia,
+			wantTrivia: &meta.Trivia{
+				Leading:  "\n",
+				Text:     "message  A {\n  int32 a = 1;  // a\n\n}",
+				Trailing: "\n\n",
+			},
+		},
+		{
+			name:      "recording the field",
+			gotTrivia: field.Meta.Trivia,
+			wantTrivia: &meta.Trivia{
+				Leading: "\n  ",
+				Text:    "int32 a = 1;",
+			},
+		},
+		{
+			name:      "recording the inline comment",
+			gotTrivia: field.InlineComment.Meta.Trivia,
+			wantTrivia: &meta.Trivia{
+				Leading: "  ",
+				Text:    "// a",
+			},
+		},
+		{
+			name:      "recording the end of the file",
+			gotTrivia: proto.Meta.Trivia,
+			wantTrivia: &meta.Trivia{
+				Trailing: "\n",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if !reflect.DeepEqual(test.gotTrivia, test.wantTrivia) {
+				t.Errorf("got %v, but want %v", test.gotTrivia, test.wantTrivia)
+			}
+		})
+	}
+}
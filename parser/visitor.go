@@ -24,3 +24,70 @@ type Visitor interface {
 	VisitService(*Service) (next bool)
 	VisitSyntax(*Syntax) (next bool)
 }
+
+// BaseVisitor is a Visitor which visits every element and does nothing.
+// Embed it to implement only the methods you need.
+type BaseVisitor struct{}
+
+// VisitComment does nothing.
+func (BaseVisitor) VisitComment(*Comment) {}
+
+// VisitDeclaration returns true.
+func (BaseVisitor) VisitDeclaration(*Declaration) bool { return true }
+
+// VisitEdition returns true.
+func (BaseVisitor) VisitEdition(*Edition) bool { return true }
+
+// VisitEmptyStatement returns true.
+func (BaseVisitor) VisitEmptyStatement(*EmptyStatement) bool { return true }
+
+// VisitEnum returns true.
+func (BaseVisitor) VisitEnum(*Enum) bool { return true }
+
+// VisitEnumField returns true.
+func (BaseVisitor) VisitEnumField(*EnumField) bool { return true }
+
+// VisitExtend returns true.
+func (BaseVisitor) VisitExtend(*Extend) bool { return true }
+
+// VisitExtensions returns true.
+func (BaseVisitor) VisitExtensions(*Extensions) bool { return true }
+
+// VisitField returns true.
+func (BaseVisitor) VisitField(*Field) bool { return true }
+
+// VisitGroupField returns true.
+func (BaseVisitor) VisitGroupField(*GroupField) bool { return true }
+
+// VisitImport returns true.
+func (BaseVisitor) VisitImport(*Import) bool { return true }
+
+// VisitMapField returns true.
+func (BaseVisitor) VisitMapField(*MapField) bool { return true }
+
+// VisitMessage returns true.
+func (BaseVisitor) VisitMessage(*Message) bool { return true }
+
+// VisitOneof returns true.
+func (BaseVisitor) VisitOneof(*Oneof) bool { return true }
+
+// VisitOneofField returns true.
+func (BaseVisitor) VisitOneofField(*OneofField) bool { return true }
+
+// VisitOption returns true.
+func (BaseVisitor) VisitOption(*Option) bool { return true }
+
+// VisitPackage returns true.
+func (BaseVisitor) VisitPackage(*Package) bool { return true }
+
+// VisitReserved returns true.
+func (BaseVisitor) VisitReserved(*Reserved) bool { return true }
+
+// VisitRPC returns true.
+func (BaseVisitor) VisitRPC(*RPC) bool { return true }
+
+// VisitService returns true.
+func (BaseVisitor) VisitService(*Service) bool { return true }
+
+// VisitSyntax returns true.
+func (BaseVisitor) VisitSyntax(*Syntax) bool { return true }
@@ -0,0 +1,252 @@
+package parser
+
+import "fmt"
+
+// Node is a node of the parsed tree visited by Walk and Inspect.
+// It is one of *Proto, the elements implementing Visitee, *FieldOption, *EnumValueOption, *Range,
+// *RPCRequest, *RPCResponse, *OptionName, *OptionNamePart, *OptionValue and *OptionValueField.
+type Node interface{}
+
+// NodeVisitor is the visitor of Walk.
+// Walk invokes Visit for each node it encounters. If the result visitor w is not nil,
+// Walk visits each of the children of node with w, followed by a call of w.Visit(nil).
+type NodeVisitor interface {
+	Visit(node Node) (w NodeVisitor)
+}
+
+// Walk traverses the tree in depth-first order, like go/ast.Walk.
+// It starts by calling v.Visit(node), and goes down into every child, including the comments,
+// the nested message bodies, the oneof fields, the field options, the RPC requests, responses and options,
+// the reserved ranges and the extension declarations, in the source order.
+// The structured option names and values are visited when the parser set them.
+func Walk(v NodeVisitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Proto:
+		if n.Syntax != nil {
+			Walk(v, n.Syntax)
+		}
+		if n.Edition != nil {
+			Walk(v, n.Edition)
+		}
+		walkBody(v, n.ProtoBody)
+
+	case *Comment:
+		// nothing to do
+
+	case *Syntax:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineComment)
+
+	case *Edition:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineComment)
+
+	case *Import:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineComment)
+
+	case *Package:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineComment)
+
+	case *Option:
+		walkComments(v, n.Comments)
+		walkOption(v, n.Name, n.Value)
+		walkComment(v, n.InlineComment)
+
+	case *EmptyStatement:
+		walkComment(v, n.InlineComment)
+
+	case *Message:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineCommentBehindLeftCurly)
+		walkBody(v, n.MessageBody)
+		walkComment(v, n.InlineComment)
+
+	case *Field:
+		walkComments(v, n.Comments)
+		for _, option := range n.FieldOptions {
+			Walk(v, option)
+		}
+		walkComment(v, n.InlineComment)
+
+	case *MapField:
+		walkComments(v, n.Comments)
+		for _, option := range n.FieldOptions {
+			Walk(v, option)
+		}
+		walkComment(v, n.InlineComment)
+
+	case *GroupField:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineCommentBehindLeftCurly)
+		walkBody(v, n.MessageBody)
+		walkComment(v, n.InlineComment)
+
+	case *FieldOption:
+		walkOption(v, n.Name, n.Value)
+
+	case *Oneof:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineCommentBehindLeftCurly)
+		for _, option := range n.Options {
+			Walk(v, option)
+		}
+		for _, field := range n.OneofFields {
+			Walk(v, field)
+		}
+		walkComment(v, n.InlineComment)
+
+	case *OneofField:
+		walkComments(v, n.Comments)
+		for _, option := range n.FieldOptions {
+			Walk(v, option)
+		}
+		walkComment(v, n.InlineComment)
+
+	case *Enum:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineCommentBehindLeftCurly)
+		walkBody(v, n.EnumBody)
+		walkComment(v, n.InlineComment)
+
+	case *EnumField:
+		walkComments(v, n.Comments)
+		for _, option := range n.EnumValueOptions {
+			Walk(v, option)
+		}
+		walkComment(v, n.InlineComment)
+
+	case *EnumValueOption:
+		walkOption(v, n.Name, n.Value)
+
+	case *Reserved:
+		walkComments(v, n.Comments)
+		for _, r := range n.Ranges {
+			Walk(v, r)
+		}
+		walkComment(v, n.InlineComment)
+
+	case *Extensions:
+		walkComments(v, n.Comments)
+		for _, r := range n.Ranges {
+			Walk(v, r)
+		}
+		walkComment(v, n.InlineCommentBehindLeftSquare)
+		for _, declaration := range n.Declarations {
+			Walk(v, declaration)
+		}
+		walkComment(v, n.InlineComment)
+
+	case *Range:
+		// nothing to do
+
+	case *Declaration:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineCommentBehindLeftCurly)
+		walkComment(v, n.InlineComment)
+
+	case *Extend:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineCommentBehindLeftCurly)
+		walkBody(v, n.ExtendBody)
+		walkComment(v, n.InlineComment)
+
+	case *Service:
+		walkComments(v, n.Comments)
+		walkComment(v, n.InlineCommentBehindLeftCurly)
+		walkBody(v, n.ServiceBody)
+		walkComment(v, n.InlineComment)
+
+	case *RPC:
+		walkComments(v, n.Comments)
+		if n.RPCRequest != nil {
+			Walk(v, n.RPCRequest)
+		}
+		if n.RPCResponse != nil {
+			Walk(v, n.RPCResponse)
+		}
+		walkComments(v, n.EmbeddedComments)
+		walkComment(v, n.InlineCommentBehindLeftCurly)
+		for _, option := range n.Options {
+			Walk(v, option)
+		}
+		walkComment(v, n.InlineComment)
+
+	case *RPCRequest, *RPCResponse:
+		// nothing to do
+
+	case *OptionName:
+		for _, part := range n.Parts {
+			Walk(v, part)
+		}
+
+	case *OptionNamePart:
+		// nothing to do
+
+	case *OptionValue:
+		for _, field := range n.Fields {
+			Walk(v, field)
+		}
+		for _, element := range n.Elements {
+			Walk(v, element)
+		}
+
+	case *OptionValueField:
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	default:
+		panic(fmt.Sprintf("parser.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkBody(v NodeVisitor, body []Visitee) {
+	for _, b := range body {
+		Walk(v, b)
+	}
+}
+
+func walkComments(v NodeVisitor, comments []*Comment) {
+	for _, comment := range comments {
+		Walk(v, comment)
+	}
+}
+
+func walkComment(v NodeVisitor, comment *Comment) {
+	if comment != nil {
+		Walk(v, comment)
+	}
+}
+
+func walkOption(v NodeVisitor, name *OptionName, value *OptionValue) {
+	if name != nil {
+		Walk(v, name)
+	}
+	if value != nil {
+		Walk(v, value)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) NodeVisitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree in depth-first order, like go/ast.Inspect.
+// It starts by calling f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the children of node, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,202 @@
+package parser_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+const walkTestInput = `syntax = "proto2";
+// M is a message.
+message M {
+  optional int32 f = 1 [default = 1];
+  oneof o {
+    option (x) = { a: 1 };
+    string s = 2;
+  }
+  extensions 100 to 199 [declaration = { number: 100 }];
+}
+service S {
+  rpc Get(Req) returns (Res) {
+    option deprecated = true;
+  }
+}
+`
+
+func parseWalkTestInput(t *testing.T) *parser.Proto {
+	t.Helper()
+	p := parser.NewParser(
+		lexer.NewLexer(strings.NewReader(walkTestInput)),
+		parser.WithOptionName(true),
+		parser.WithOptionValue(true),
+		parser.WithPermissive(true),
+	)
+	proto, err := p.ParseProto()
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	return proto
+}
+
+type walkTestVisitor struct {
+	depth   int
+	buffers *[]string
+}
+
+func (v walkTestVisitor) Visit(node parser.Node) parser.NodeVisitor {
+	if node == nil {
+		*v.buffers = append(*v.buffers, strings.Repeat(" ", v.depth-1)+"end")
+		return nil
+	}
+	*v.buffers = append(*v.buffers, strings.Repeat(" ", v.depth)+strings.TrimPrefix(fmt.Sprintf("%T", node), "*parser."))
+	return walkTestVisitor{depth: v.depth + 1, buffers: v.buffers}
+}
+
+func TestWalk(t *testing.T) {
+	proto := parseWalkTestInput(t)
+
+	var got []string
+	parser.Walk(walkTestVisitor{buffers: &got}, proto)
+
+	want := []string{
+		"Proto",
+		" Syntax",
+		" end",
+		" Message",
+		"  Comment",
+		"  end",
+		"  Field",
+		"   FieldOption",
+		"    OptionName",
+		"     OptionNamePart",
+		"     end",
+		"    end",
+		"    OptionValue",
+		"    end",
+		"   end",
+		"  end",
+		"  Oneof",
+		"   Option",
+		"    OptionName",
+		"     OptionNamePart",
+		"     end",
+		"    end",
+		"    OptionValue",
+		"     OptionValueField",
+		"      OptionValue",
+		"      end",
+		"     end",
+		"    end",
+		"   end",
+		"   OneofField",
+		"   end",
+		"  end",
+		"  Extensions",
+		"   Range",
+		"   end",
+		"   Declaration",
+		"   end",
+		"  end",
+		" end",
+		" Service",
+		"  RPC",
+		"   RPCRequest",
+		"   end",
+		"   RPCResponse",
+		"   end",
+		"   Option",
+		"    OptionName",
+		"     OptionNamePart",
+		"     end",
+		"    end",
+		"    OptionValue",
+		"    end",
+		"   end",
+		"  end",
+		" end",
+		"end",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, but want %v", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestInspect(t *testing.T) {
+	proto := parseWalkTestInput(t)
+
+	tests := []struct {
+		name      string
+		inspect   func(node parser.Node) bool
+		wantNames []string
+	}{
+		{
+			name: "visiting every field and option",
+			inspect: func(node parser.Node) bool {
+				return true
+			},
+			wantNames: []string{"f", "default", "(x)", "s", "deprecated"},
+		},
+		{
+			name: "skipping the children of the service",
+			inspect: func(node parser.Node) bool {
+				_, ok := node.(*parser.Service)
+				return !ok
+			},
+			wantNames: []string{"f", "default", "(x)", "s"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var gotNames []string
+			parser.Inspect(proto, func(node parser.Node) bool {
+				switch n := node.(type) {
+				case *parser.Field:
+					gotNames = append(gotNames, n.FieldName)
+				case *parser.OneofField:
+					gotNames = append(gotNames, n.FieldName)
+				case *parser.Option:
+					gotNames = append(gotNames, n.OptionName)
+				case *parser.FieldOption:
+					gotNames = append(gotNames, n.OptionName)
+				}
+				return node == nil || test.inspect(node)
+			})
+			if !reflect.DeepEqual(gotNames, test.wantNames) {
+				t.Errorf("got %v, but want %v", gotNames, test.wantNames)
+			}
+		})
+	}
+}
+
+type fieldNameVisitor struct {
+	parser.BaseVisitor
+	names []string
+}
+
+func (v *fieldNameVisitor) VisitField(f *parser.Field) bool {
+	v.names = append(v.names, f.FieldName)
+	return true
+}
+
+func (v *fieldNameVisitor) VisitOneofField(f *parser.OneofField) bool {
+	v.names = append(v.names, f.FieldName)
+	return true
+}
+
+func TestBaseVisitor(t *testing.T) {
+	proto := parseWalkTestInput(t)
+
+	v := &fieldNameVisitor{}
+	proto.Accept(v)
+
+	want := []string{"f", "s"}
+	if !reflect.DeepEqual(v.names, want) {
+		t.Errorf("got %v, but want %v", v.names, want)
+	}
+}
@@ -0,0 +1,15 @@
+package printer
+
+import (
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func (s *state) enum(e *parser.Enum) {
+	s.write("enum ", e.EnumName, " ")
+	s.block(e.InlineCommentBehindLeftCurly, e.EnumBody, e.Meta.Trivia)
+}
+
+func (s *state) enumField(f *parser.EnumField) {
+	a, _ := assignmentOf(f)
+	s.assignment(a)
+}
@@ -0,0 +1,255 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// parts returns the leading comments, the inline comment and the meta of the node.
+// m is nil if the node is not supported.
+func parts(node parser.Visitee) (comments []*parser.Comment, inlineComment *parser.Comment, m *meta.Meta) {
+	switch n := node.(type) {
+	case *parser.Comment:
+		return nil, nil, &n.Meta
+	case *parser.Syntax:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Edition:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Import:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Package:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Option:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.EmptyStatement:
+		return nil, n.InlineComment, &n.Meta
+	case *parser.Message:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Enum:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.EnumField:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Service:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.RPC:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Field:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.MapField:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.GroupField:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Oneof:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.OneofField:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Reserved:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Extensions:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Declaration:
+		return n.Comments, n.InlineComment, &n.Meta
+	case *parser.Extend:
+		return n.Comments, n.InlineComment, &n.Meta
+	}
+	return nil, nil, nil
+}
+
+// span is the range of source lines occupied by a node, including its comments.
+// Both are 0 if the node does not come from the source.
+type span struct {
+	first int
+	last  int
+}
+
+func spanOf(node parser.Visitee) span {
+	comments, inlineComment, m := parts(node)
+	if m == nil || m.Pos.Line == 0 {
+		return span{}
+	}
+	sp := span{
+		first: m.Pos.Line,
+		last:  m.LastPos.Line,
+	}
+	if 0 < len(comments) && 0 < comments[0].Meta.Pos.Line {
+		sp.first = comments[0].Meta.Pos.Line
+	}
+	if inlineComment != nil && sp.last < inlineComment.Meta.LastPos.Line {
+		sp.last = inlineComment.Meta.LastPos.Line
+	}
+	return sp
+}
+
+// hasBlankLine reports whether the source has a blank line right before the i-th node.
+// A line is considered blank if none of the nodes occupies it,
+// so that it works even after the nodes are reordered.
+func hasBlankLine(spans []span, i int) bool {
+	if spans[i].first == 0 {
+		return false
+	}
+	prevLast := 0
+	for _, sp := range spans[:i] {
+		if prevLast < sp.last {
+			prevLast = sp.last
+		}
+	}
+	if prevLast == 0 {
+		return false
+	}
+
+	for line := prevLast + 1; line < spans[i].first; line++ {
+		occupied := false
+		for _, sp := range spans {
+			if sp.first <= line && line <= sp.last {
+				occupied = true
+				break
+			}
+		}
+		if !occupied {
+			return true
+		}
+	}
+	return false
+}
+
+// assignment is an element in the form of `head = number [options];`.
+type assignment struct {
+	head      string
+	number    string
+	names     []string
+	constants []constant
+}
+
+func assignmentOf(node parser.Visitee) (assignment, bool) {
+	switch n := node.(type) {
+	case *parser.Field:
+		label := ""
+		switch {
+		case n.IsRepeated:
+			label = "repeated "
+		case n.IsRequired:
+			label = "required "
+		case n.IsOptional:
+			label = "optional "
+		}
+		a := assignment{head: label + n.Type + " " + n.FieldName, number: n.FieldNumber}
+		a.names, a.constants = fieldOptions(n.FieldOptions, sourceOf(n.Meta))
+		return a, true
+	case *parser.MapField:
+		a := assignment{head: "map<" + n.KeyType + ", " + n.Type + "> " + n.MapName, number: n.FieldNumber}
+		a.names, a.constants = fieldOptions(n.FieldOptions, sourceOf(n.Meta))
+		return a, true
+	case *parser.OneofField:
+		a := assignment{head: n.Type + " " + n.FieldName, number: n.FieldNumber}
+		a.names, a.constants = fieldOptions(n.FieldOptions, sourceOf(n.Meta))
+		return a, true
+	case *parser.EnumField:
+		a := assignment{head: n.Ident, number: n.Number}
+		for _, opt := range n.EnumValueOptions {
+			a.names = append(a.names, opt.OptionName)
+			a.constants = append(a.constants, constant{
+				text:  opt.Constant,
+				value: opt.Value,
+				span:  opt.ConstantSpan,
+				src:   sourceOf(n.Meta),
+			})
+		}
+		return a, true
+	}
+	return assignment{}, false
+}
+
+func fieldOptions(fieldOptions []*parser.FieldOption, src source) (names []string, constants []constant) {
+	for _, opt := range fieldOptions {
+		names = append(names, opt.OptionName)
+		constants = append(constants, constant{
+			text:  opt.Constant,
+			value: opt.Value,
+			span:  opt.ConstantSpan,
+			src:   src,
+		})
+	}
+	return names, constants
+}
+
+// columns is the widths used to align consecutive assignments.
+type columns struct {
+	head    int
+	number  int
+	comment int
+}
+
+// assignment prints the assignment, padded according to the columns.
+func (s *state) assignment(a assignment) {
+	s.write(a.head)
+	s.writePadding(s.columns.head - width(a.head))
+	s.write(" = ", a.number)
+	if 0 < len(a.names) {
+		s.writePadding(s.columns.number - width(a.number))
+	}
+	s.options(a.names, a.constants)
+	s.write(";")
+}
+
+// alignedRun sets the columns to align the assignments starting at the i-th node, and returns the end of them.
+// The run ends at a blank line, an element other than assignments, or an assignment to be wrapped.
+func (s *state) alignedRun(nodes []parser.Visitee, spans []span, i int) int {
+	var run []assignment
+	var inlineComments []*parser.Comment
+	end := i
+	for ; end < len(nodes); end++ {
+		node := nodes[end]
+		a, ok := assignmentOf(node)
+		if !ok || s.fromSource(node) || s.multiline(a.constants) || s.wrapped(a) {
+			break
+		}
+		if i < end && s.blankLines && hasBlankLine(spans, end) {
+			break
+		}
+		_, inlineComment, _ := parts(node)
+		run = append(run, a)
+		inlineComments = append(inlineComments, inlineComment)
+	}
+	if len(run) < 2 {
+		return i
+	}
+
+	var c columns
+	for _, a := range run {
+		c.head = maxInt(c.head, width(a.head))
+		if 0 < len(a.names) {
+			c.number = maxInt(c.number, width(a.number))
+		}
+	}
+	for j, a := range run {
+		if inlineComments[j] == nil {
+			continue
+		}
+		var b strings.Builder
+		aligned := &state{Printer: s.Printer, w: &b, comparing: s.comparing, columns: c}
+		aligned.assignment(a)
+		c.comment = maxInt(c.comment, s.depth*width(s.indent)+aligned.column+1)
+	}
+	s.columns = c
+	return end
+}
+
+// wrapped reports whether the options of the assignment are wrapped onto separate lines.
+func (s *state) wrapped(a assignment) bool {
+	if s.maxLineLength <= 0 || len(a.names) == 0 || s.multiline(a.constants) {
+		return false
+	}
+	var b strings.Builder
+	unaligned := &state{Printer: s.Printer, w: &b, comparing: s.comparing, column: s.depth * width(s.indent)}
+	unaligned.assignment(a)
+	return strings.Contains(b.String(), "\n")
+}
+
+func maxInt(a, b int) int {
+	if a < b {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,164 @@
+package printer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+func (s *state) message(m *parser.Message) {
+	s.write("message ", m.MessageName, " ")
+	s.block(m.InlineCommentBehindLeftCurly, m.MessageBody, m.Meta.Trivia)
+}
+
+func (s *state) field(f *parser.Field) {
+	a, _ := assignmentOf(f)
+	s.assignment(a)
+}
+
+func (s *state) mapField(m *parser.MapField) {
+	a, _ := assignmentOf(m)
+	s.assignment(a)
+}
+
+func (s *state) groupField(g *parser.GroupField) {
+	switch {
+	case g.IsRepeated:
+		s.write("repeated ")
+	case g.IsRequired:
+		s.write("required ")
+	case g.IsOptional:
+		s.write("optional ")
+	}
+	s.write("group ", g.GroupName, " = ", g.FieldNumber, " ")
+	s.block(g.InlineCommentBehindLeftCurly, g.MessageBody, g.Meta.Trivia)
+}
+
+func (s *state) oneof(o *parser.Oneof) {
+	type element struct {
+		node   parser.Visitee
+		offset int
+		trivia *meta.Trivia
+	}
+	var elements []element
+	for _, option := range o.Options {
+		elements = append(elements, element{option, option.Meta.Pos.Offset, option.Meta.Trivia})
+	}
+	for _, field := range o.OneofFields {
+		elements = append(elements, element{field, field.Meta.Pos.Offset, field.Meta.Trivia})
+	}
+
+	// Restores the original order of options and fields if all of them are from the source.
+	fromSource := true
+	for _, e := range elements {
+		fromSource = fromSource && s.usable(e.trivia)
+	}
+	if fromSource {
+		sort.SliceStable(elements, func(i, j int) bool {
+			return elements[i].offset < elements[j].offset
+		})
+	}
+
+	var body []parser.Visitee
+	for _, e := range elements {
+		body = append(body, e.node)
+	}
+
+	s.write("oneof ", o.OneofName, " ")
+	s.block(o.InlineCommentBehindLeftCurly, body, o.Meta.Trivia)
+}
+
+func (s *state) oneofField(f *parser.OneofField) {
+	a, _ := assignmentOf(f)
+	s.assignment(a)
+}
+
+func (s *state) reserved(r *parser.Reserved) {
+	s.write("reserved ")
+	if 0 < len(r.Ranges) {
+		s.ranges(r.Ranges)
+	} else {
+		s.write(strings.Join(r.FieldNames, ", "))
+	}
+	s.write(";")
+}
+
+func (s *state) ranges(ranges []*parser.Range) {
+	var rs []string
+	for _, r := range ranges {
+		if r.End == "" {
+			rs = append(rs, r.Begin)
+			continue
+		}
+		rs = append(rs, r.Begin+" to "+r.End)
+	}
+	s.write(strings.Join(rs, ", "))
+}
+
+func (s *state) extensions(e *parser.Extensions) {
+	s.write("extensions ")
+	s.ranges(e.Ranges)
+	if 0 < len(e.Declarations) {
+		s.write(" [")
+		s.inlineComment(e.InlineCommentBehindLeftSquare)
+
+		s.depth++
+		for i, d := range e.Declarations {
+			for _, comment := range d.Comments {
+				s.comment(comment)
+			}
+			s.startLine(d.Meta.Trivia)
+			s.body(d, d.Meta.Trivia)
+			if i < len(e.Declarations)-1 {
+				s.write(",")
+			}
+			s.inlineComment(d.InlineComment)
+		}
+		s.depth--
+
+		s.endBlock(e.Meta.Trivia, "]")
+	}
+	s.write(";")
+}
+
+func (s *state) declaration(d *parser.Declaration) {
+	var entries []string
+	if d.Number != "" {
+		entries = append(entries, "number: "+d.Number)
+	}
+	if d.FullName != "" {
+		entries = append(entries, "full_name: "+d.FullName)
+	}
+	if d.Type != "" {
+		entries = append(entries, "type: "+d.Type)
+	}
+	if d.Repeated {
+		entries = append(entries, "repeated: true")
+	}
+	if d.Reserved {
+		entries = append(entries, "reserved: true")
+	}
+
+	s.write("declaration = {")
+	s.inlineComment(d.InlineCommentBehindLeftCurly)
+
+	s.depth++
+	for i, entry := range entries {
+		s.ensureLine()
+		s.writeIndent()
+		s.write(entry)
+		if i < len(entries)-1 {
+			s.write(",")
+		}
+	}
+	s.depth--
+
+	s.endBlock(d.Meta.Trivia, "}")
+}
+
+func (s *state) extend(e *parser.Extend) {
+	s.write("extend ", e.MessageType, " ")
+	s.block(e.InlineCommentBehindLeftCurly, e.ExtendBody, e.Meta.Trivia)
+}
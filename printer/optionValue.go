@@ -0,0 +1,200 @@
+package printer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// source is the verbatim text of the element holding an option, starting at the offset.
+// It is empty if the element was not parsed with the trivia option.
+type source struct {
+	text   string
+	offset int
+}
+
+func sourceOf(m meta.Meta) source {
+	if m.Trivia == nil {
+		return source{}
+	}
+	return source{text: m.Trivia.Text, offset: m.Pos.Offset}
+}
+
+// slice returns the text from pos to last, the position of the last character, if the source covers it.
+func (src source) slice(pos, last meta.Position) (string, bool) {
+	end := last.Offset - src.offset
+	if end < 0 || len(src.text) <= end {
+		return "", false
+	}
+	_, size := utf8.DecodeRuneInString(src.text[end:])
+	last.Offset += size
+	return src.between(pos, last)
+}
+
+// between returns the text from pos to just before end, if the source covers it.
+func (src source) between(pos, end meta.Position) (string, bool) {
+	i := pos.Offset - src.offset
+	j := end.Offset - src.offset
+	if src.text == "" || pos.Line == 0 || i < 0 || j <= i || len(src.text) < j {
+		return "", false
+	}
+	return src.text[i:j], true
+}
+
+// constant is the value of an option.
+type constant struct {
+	// text is the flattened constant, which joins the fields of a message literal with a newline.
+	text string
+	// value is the structured value, if parsed with the option value option.
+	value *parser.OptionValue
+	// span is the range of the constant, if parsed with the spans option.
+	span meta.Span
+	src  source
+}
+
+// constant prints the value of an option.
+// Message literals are printed with one field per line, indented one level deeper than the current line.
+// Without the structured value, it prints the source text if available, otherwise the flattened constant.
+func (s *state) constant(c constant) {
+	if c.value != nil {
+		s.optionValue(c.value, c.src)
+		return
+	}
+	if !s.comparing && c.span.IsValid() {
+		if text, ok := c.src.between(c.span.Pos, c.span.End); ok {
+			s.write(text)
+			return
+		}
+	}
+	s.write(strings.ReplaceAll(c.text, "\n", " "))
+}
+
+func (s *state) optionValue(value *parser.OptionValue, src source) {
+	switch value.Kind {
+	case parser.OptionValueKindMessage:
+		if len(value.Fields) == 0 {
+			s.write("{}")
+			return
+		}
+		s.write("{")
+		s.depth++
+		for _, field := range value.Fields {
+			s.write("\n")
+			s.writeIndent()
+			s.write(field.Name, ": ")
+			s.optionValue(field.Value, src)
+		}
+		s.depth--
+		s.write("\n")
+		s.writeIndent()
+		s.write("}")
+	case parser.OptionValueKindList:
+		multiline := false
+		for _, element := range value.Elements {
+			multiline = multiline || (element.Kind == parser.OptionValueKindMessage && 0 < len(element.Fields))
+		}
+		s.write("[")
+		if multiline {
+			s.depth++
+		}
+		for i, element := range value.Elements {
+			if 0 < i {
+				s.write(",")
+				if !multiline {
+					s.write(" ")
+				}
+			}
+			if multiline {
+				s.write("\n")
+				s.writeIndent()
+			}
+			s.optionValue(element, src)
+		}
+		if multiline {
+			s.depth--
+			s.write("\n")
+			s.writeIndent()
+		}
+		s.write("]")
+	case parser.OptionValueKindString:
+		s.stringValue(value, src)
+	default:
+		s.write(value.Text)
+	}
+}
+
+// stringValue prints the string keeping the adjacent string literals of the source apart.
+// The literals placed on separate lines are printed on separate lines indented one level deeper.
+func (s *state) stringValue(value *parser.OptionValue, src source) {
+	text, ok := src.slice(value.Meta.Pos, value.Meta.LastPos)
+	if s.comparing || !ok {
+		s.write(value.Text)
+		return
+	}
+	lits, newlines, ok := splitStrLits(text)
+	if !ok {
+		s.write(value.Text)
+		return
+	}
+	for i, lit := range lits {
+		switch {
+		case i == 0:
+		case newlines[i]:
+			s.write("\n")
+			s.depth++
+			s.writeIndent()
+			s.depth--
+		default:
+			s.write(" ")
+		}
+		s.write(lit)
+	}
+}
+
+// splitStrLits splits the adjacent string literals separated by whitespace.
+// newlines reports whether each literal is placed on a line after the previous one.
+// ok is false if the text has anything other than string literals and whitespace.
+func splitStrLits(text string) (lits []string, newlines []bool, ok bool) {
+	newline := false
+	for i := 0; i < len(text); {
+		switch c := text[i]; c {
+		case '\n':
+			newline = true
+			i++
+		case ' ', '\t', '\v', '\f', '\r':
+			i++
+		case '"', '\'':
+			j := i + 1
+			for ; j < len(text) && text[j] != c; j++ {
+				if text[j] == '\\' {
+					j++
+				}
+			}
+			if len(text) <= j {
+				return nil, nil, false
+			}
+			lits = append(lits, text[i:j+1])
+			newlines = append(newlines, newline)
+			newline = false
+			i = j + 1
+		default:
+			return nil, nil, false
+		}
+	}
+	return lits, newlines, 0 < len(lits)
+}
+
+// multiline reports whether any of the constants is printed on multiple lines.
+func (s *state) multiline(constants []constant) bool {
+	for _, c := range constants {
+		var b strings.Builder
+		t := &state{Printer: s.Printer, w: &b, comparing: s.comparing}
+		t.constant(c)
+		if strings.Contains(b.String(), "\n") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,429 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+const defaultIndent = "  "
+
+// Printer prints Protocol Buffer elements back into the .proto source.
+type Printer struct {
+	indent        string
+	align         bool
+	maxLineLength int
+	blankLines    bool
+	ignoreTrivia  bool
+}
+
+// Option is an option for NewPrinter.
+type Option func(*Printer)
+
+// WithIndent is an option to set the string used for one level of indentation.
+// The default is two spaces.
+func WithIndent(indent string) Option {
+	return func(p *Printer) {
+		p.indent = indent
+	}
+}
+
+// WithAlignment is an option to align the field numbers, the options and the inline comments
+// of consecutive fields and enum values.
+func WithAlignment(align bool) Option {
+	return func(p *Printer) {
+		p.align = align
+	}
+}
+
+// WithMaxLineLength is an option to wrap the options of fields and enum values
+// onto separate lines when the line would be longer than the given length.
+// The default is 0, which means no limit.
+func WithMaxLineLength(length int) Option {
+	return func(p *Printer) {
+		p.maxLineLength = length
+	}
+}
+
+// WithBlankLines is an option to keep a single blank line where the source has one or more blank lines between elements.
+func WithBlankLines(blankLines bool) Option {
+	return func(p *Printer) {
+		p.blankLines = blankLines
+	}
+}
+
+// WithIgnoreTrivia is an option to print every element in the canonical layout
+// even if the node was parsed with the trivia option.
+func WithIgnoreTrivia(ignoreTrivia bool) Option {
+	return func(p *Printer) {
+		p.ignoreTrivia = ignoreTrivia
+	}
+}
+
+// NewPrinter creates a new Printer.
+func NewPrinter(opts ...Option) *Printer {
+	p := &Printer{
+		indent: defaultIndent,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Fprint prints the node to w.
+// The node is either a *parser.Proto or any element of it, such as *parser.Message or *parser.Service.
+//
+// If the node was parsed with the trivia option, each element whose verbatim source text still
+// represents it is printed as is, along with its original whitespace.
+// Other elements are printed in the canonical layout.
+func (p *Printer) Fprint(w io.Writer, node parser.Visitee) error {
+	s := &state{
+		Printer:     p,
+		w:           w,
+		canonical:   p.ignoreTrivia,
+		atLineStart: true,
+	}
+	if proto, ok := node.(*parser.Proto); ok {
+		s.proto(proto)
+	} else {
+		s.element(node)
+		s.ensureLine()
+	}
+	return s.err
+}
+
+// Fprint prints the node to w with the given options.
+func Fprint(w io.Writer, node parser.Visitee, opts ...Option) error {
+	return NewPrinter(opts...).Fprint(w, node)
+}
+
+// state holds the progress of a single Fprint call.
+type state struct {
+	*Printer
+
+	w     io.Writer
+	depth int
+	err   error
+
+	// canonical is true if the trivia must be ignored.
+	canonical bool
+	// comparing is true if the node is printed only to be compared with another one.
+	// The option values are printed without their source text.
+	comparing bool
+	// written is true if anything has been written.
+	written bool
+	// atLineStart is true if the last written text ends with a newline.
+	atLineStart bool
+	// inLineComment is true if the last written text is a C++-style comment.
+	inLineComment bool
+	// inProto is true if the printed node is a *parser.Proto.
+	inProto bool
+	// column is the number of characters written since the last newline.
+	column int
+	// columns is the layout of the aligned elements being printed.
+	columns columns
+}
+
+func (s *state) write(strs ...string) {
+	for _, str := range strs {
+		if s.err != nil {
+			return
+		}
+		if str == "" {
+			continue
+		}
+		if s.inLineComment && str[0] != '\n' {
+			// A C++-style comment lasts until the end of the line.
+			str = "\n" + str
+		}
+		if _, err := io.WriteString(s.w, str); err != nil {
+			s.err = err
+			return
+		}
+		s.written = true
+		s.atLineStart = strings.HasSuffix(str, "\n")
+		if i := strings.LastIndexByte(str, '\n'); 0 <= i {
+			s.column = width(str[i+1:])
+		} else {
+			s.column += width(str)
+		}
+		s.inLineComment = false
+	}
+}
+
+func (s *state) writeComment(comment *parser.Comment) {
+	s.write(comment.Raw)
+	if !comment.IsCStyle() {
+		s.inLineComment = true
+	}
+}
+
+func (s *state) writeIndent() {
+	s.write(strings.Repeat(s.indent, s.depth))
+}
+
+func (s *state) writePadding(n int) {
+	if 0 < n {
+		s.write(strings.Repeat(" ", n))
+	}
+}
+
+func width(str string) int {
+	return utf8.RuneCountInString(str)
+}
+
+// ensureLine starts a new line unless the last written text ends with a newline.
+func (s *state) ensureLine() {
+	if !s.atLineStart {
+		s.write("\n")
+	}
+}
+
+func (s *state) fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// usable reports whether the trivia can be used to print the original text.
+func (s *state) usable(trivia *meta.Trivia) bool {
+	return trivia != nil && !s.canonical
+}
+
+// startLine prepares to print an element.
+// It writes the leading whitespace of the trivia if usable, or starts a new indented line.
+func (s *state) startLine(trivia *meta.Trivia) {
+	if s.usable(trivia) {
+		if s.written || s.inProto {
+			s.write(trivia.Leading)
+		}
+		return
+	}
+	s.ensureLine()
+	s.writeIndent()
+}
+
+// endBlock prints the closing token of the body.
+func (s *state) endBlock(trivia *meta.Trivia, closing string) {
+	if s.usable(trivia) {
+		s.write(trivia.Trailing)
+	} else {
+		s.ensureLine()
+		s.writeIndent()
+	}
+	s.write(closing)
+}
+
+// element prints the node, including its leading comments and its inline comment.
+func (s *state) element(node parser.Visitee) {
+	if comment, ok := node.(*parser.Comment); ok {
+		s.comment(comment)
+		return
+	}
+	comments, inlineComment, m := parts(node)
+	if m == nil {
+		s.fail(fmt.Errorf("unsupported node type %T", node))
+		return
+	}
+	s.statement(node, comments, inlineComment, m.Trivia)
+}
+
+// elements prints the nodes placed in the same body.
+func (s *state) elements(nodes []parser.Visitee) {
+	spans := make([]span, len(nodes))
+	for i, node := range nodes {
+		spans[i] = spanOf(node)
+	}
+
+	outer := s.columns
+	defer func() {
+		s.columns = outer
+	}()
+
+	var runEnd int
+	for i, node := range nodes {
+		if s.blankLines && 0 < i && !s.fromSource(node) && hasBlankLine(spans, i) {
+			s.ensureLine()
+			s.write("\n")
+		}
+		if runEnd <= i {
+			s.columns = columns{}
+			if s.align {
+				runEnd = s.alignedRun(nodes, spans, i)
+			}
+		}
+		s.element(node)
+	}
+}
+
+// fromSource reports whether the node is printed with its original whitespace.
+func (s *state) fromSource(node parser.Visitee) bool {
+	if comment, ok := node.(*parser.Comment); ok {
+		return s.usable(comment.Meta.Trivia)
+	}
+	_, _, m := parts(node)
+	return m != nil && s.usable(m.Trivia)
+}
+
+// statement prints the leading comments, the node itself and the inline comment.
+func (s *state) statement(
+	node parser.Visitee,
+	comments []*parser.Comment,
+	inlineComment *parser.Comment,
+	trivia *meta.Trivia,
+) {
+	for _, comment := range comments {
+		s.comment(comment)
+	}
+	s.startLine(trivia)
+	s.body(node, trivia)
+	s.inlineComment(inlineComment)
+}
+
+// body prints the node without its leading comments and its inline comment.
+func (s *state) body(node parser.Visitee, trivia *meta.Trivia) {
+	if s.usable(trivia) && s.unchanged(node, trivia.Text) {
+		s.write(trivia.Text)
+		return
+	}
+
+	switch n := node.(type) {
+	case *parser.Syntax:
+		s.syntax(n)
+	case *parser.Edition:
+		s.edition(n)
+	case *parser.Import:
+		s.importStatement(n)
+	case *parser.Package:
+		s.write("package ", n.Name, ";")
+	case *parser.Option:
+		s.option(n)
+	case *parser.EmptyStatement:
+		s.write(";")
+	case *parser.Message:
+		s.message(n)
+	case *parser.Enum:
+		s.enum(n)
+	case *parser.EnumField:
+		s.enumField(n)
+	case *parser.Service:
+		s.service(n)
+	case *parser.RPC:
+		s.rpc(n)
+	case *parser.Field:
+		s.field(n)
+	case *parser.MapField:
+		s.mapField(n)
+	case *parser.GroupField:
+		s.groupField(n)
+	case *parser.Oneof:
+		s.oneof(n)
+	case *parser.OneofField:
+		s.oneofField(n)
+	case *parser.Reserved:
+		s.reserved(n)
+	case *parser.Extensions:
+		s.extensions(n)
+	case *parser.Declaration:
+		s.declaration(n)
+	case *parser.Extend:
+		s.extend(n)
+	}
+}
+
+func (s *state) comment(comment *parser.Comment) {
+	s.startLine(comment.Meta.Trivia)
+	s.writeComment(comment)
+}
+
+// inlineComment prints the comment on the current line, if any.
+func (s *state) inlineComment(comment *parser.Comment) {
+	if comment == nil {
+		return
+	}
+	switch {
+	case s.usable(comment.Meta.Trivia):
+		s.write(comment.Meta.Trivia.Leading)
+	case s.column < s.columns.comment:
+		s.writePadding(s.columns.comment - s.column)
+	default:
+		s.write(" ")
+	}
+	s.writeComment(comment)
+}
+
+// block prints the body surrounded by curly brackets.
+func (s *state) block(
+	inlineLeftCurly *parser.Comment,
+	body []parser.Visitee,
+	trivia *meta.Trivia,
+) {
+	s.write("{")
+	if len(body) == 0 && inlineLeftCurly == nil && !s.usable(trivia) {
+		s.write("}")
+		return
+	}
+	s.inlineComment(inlineLeftCurly)
+
+	s.depth++
+	s.elements(body)
+	s.depth--
+
+	s.endBlock(trivia, "}")
+}
+
+// options prints the options of fields and enum values.
+// They are wrapped onto separate lines if the line would be longer than the max line length,
+// unless any of them is printed on multiple lines.
+func (s *state) options(names []string, constants []constant) {
+	if len(names) == 0 {
+		return
+	}
+	if s.maxLineLength <= 0 || s.multiline(constants) || s.column+width(" ["+s.optionList(names, constants)+"];") <= s.maxLineLength {
+		s.write(" [")
+		for i, name := range names {
+			if 0 < i {
+				s.write(", ")
+			}
+			s.write(name, " = ")
+			s.constant(constants[i])
+		}
+		s.write("]")
+		return
+	}
+
+	s.write(" [")
+	s.depth++
+	for i, name := range names {
+		s.write("\n")
+		s.writeIndent()
+		s.write(name, " = ")
+		s.constant(constants[i])
+		if i < len(names)-1 {
+			s.write(",")
+		}
+	}
+	s.depth--
+	s.write("\n")
+	s.writeIndent()
+	s.write("]")
+}
+
+// optionList returns the options printed on a single line.
+func (s *state) optionList(names []string, constants []constant) string {
+	var b strings.Builder
+	t := &state{Printer: s.Printer, w: &b, comparing: s.comparing}
+	for i, name := range names {
+		if 0 < i {
+			t.write(", ")
+		}
+		t.write(name, " = ")
+		t.constant(constants[i])
+	}
+	return b.String()
+}
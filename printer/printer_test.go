@@ -0,0 +1,696 @@
+package printer_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/printer"
+)
+
+func TestPrinter_Fprint(t *testing.T) {
+	tests := []struct {
+		name                       string
+		input                      string
+		inputBodyIncludingComments bool
+		inputOptions               []printer.Option
+		wantOutput                 string
+	}{
+		{
+			name: "printing an excerpt from the official reference",
+			input: `
+syntax = "proto3";
+import public "other.proto";
+option java_package = "com.example.foo";
+enum EnumAllowingAlias {
+  option allow_alias = true;
+  UNKNOWN = 0;
+  STARTED = 1;
+  RUNNING = 2 [(custom_option) = "hello world"];
+}
+message outer {
+  option (my_option).a = true;
+  message inner {
+    int64 ival = 1;
+  }
+  repeated inner inner_message = 2;
+  EnumAllowingAlias enum_field =3;
+  map<int32, string> my_map = 4;
+}
+`,
+			wantOutput: `syntax = "proto3";
+import public "other.proto";
+option java_package = "com.example.foo";
+enum EnumAllowingAlias {
+  option allow_alias = true;
+  UNKNOWN = 0;
+  STARTED = 1;
+  RUNNING = 2 [(custom_option) = "hello world"];
+}
+message outer {
+  option (my_option).a = true;
+  message inner {
+    int64 ival = 1;
+  }
+  repeated inner inner_message = 2;
+  EnumAllowingAlias enum_field = 3;
+  map<int32, string> my_map = 4;
+}
+`,
+		},
+		{
+			name: "printing comments",
+			input: `
+// syntax
+syntax = "proto3"; // inline syntax
+/* package */
+package foo.bar;
+// message
+message A { // behind left curly
+  // field
+  string name = 1; // inline field
+  // dangling
+}
+service S { // behind left curly
+  // rpc
+  rpc Get (A) /* embedded */ returns (A) { // behind rpc left curly
+    option deprecated = true;
+  } // inline rpc
+}
+`,
+			inputBodyIncludingComments: true,
+			wantOutput: `// syntax
+syntax = "proto3"; // inline syntax
+/* package */
+package foo.bar;
+// message
+message A { // behind left curly
+  // field
+  string name = 1; // inline field
+  // dangling
+}
+service S { // behind left curly
+  // rpc
+  rpc Get (A) returns (A) /* embedded */ { // behind rpc left curly
+    option deprecated = true;
+  } // inline rpc
+}
+`,
+		},
+		{
+			name: "printing proto2 elements",
+			input: `
+syntax = "proto2";
+import weak "other.proto";
+message A {
+  required string a = 1 [default = "x", (foo).bar = true];
+  optional group Result = 2 {
+    repeated int32 b = 3;
+  }
+  oneof o {
+    option (oneof_opt) = 1;
+    int32 c = 4;
+  }
+  reserved 5, 10 to 20, 100 to max;
+  reserved "d", "e";
+  extensions 1000 to 1999;
+}
+extend A {
+  optional int32 f = 1000;
+}
+enum E {
+  reserved 3;
+  V = -1 [deprecated = true];
+  ;
+}
+`,
+			wantOutput: `syntax = "proto2";
+import weak "other.proto";
+message A {
+  required string a = 1 [default = "x", (foo).bar = true];
+  optional group Result = 2 {
+    repeated int32 b = 3;
+  }
+  oneof o {
+    option (oneof_opt) = 1;
+    int32 c = 4;
+  }
+  reserved 5, 10 to 20, 100 to max;
+  reserved "d", "e";
+  extensions 1000 to 1999;
+}
+extend A {
+  optional int32 f = 1000;
+}
+enum E {
+  reserved 3;
+  V = -1 [deprecated = true];
+  ;
+}
+`,
+		},
+		{
+			name: "printing editions elements",
+			input: `
+edition = "2023";
+message Foo {
+  extensions 4 to 1000 [
+    // first
+    declaration = {
+      number: 4,
+      full_name: ".my.package.event_annotations",
+      type: ".logs.proto.ValidationAnnotations",
+      repeated: true }, // inline first
+    declaration = {
+      number: 999,
+      reserved: true }];
+  reserved foo, bar;
+  message Empty {}
+}
+service Empty {}
+`,
+			wantOutput: `edition = "2023";
+message Foo {
+  extensions 4 to 1000 [
+    // first
+    declaration = {
+      number: 4,
+      full_name: ".my.package.event_annotations",
+      type: ".logs.proto.ValidationAnnotations",
+      repeated: true
+    }, // inline first
+    declaration = {
+      number: 999,
+      reserved: true
+    }
+  ];
+  reserved foo, bar;
+  message Empty {}
+}
+service Empty {}
+`,
+		},
+		{
+			name: "printing with the indent option",
+			input: `
+message A {
+  message B {
+    int32 a = 1;
+  }
+}
+`,
+			inputOptions: []printer.Option{
+				printer.WithIndent("\t"),
+			},
+			wantOutput: "message A {\n\tmessage B {\n\t\tint32 a = 1;\n\t}\n}\n",
+		},
+		{
+			name: "printing with the alignment option",
+			input: `
+enum E {
+  UNKNOWN = 0;
+  STARTED = 1 [deprecated = true]; // started
+  RUNNING_LONGER = 20 [deprecated = true];
+  option allow_alias = true;
+  A = 3;
+  B_LONGER = 4;
+}
+`,
+			inputOptions: []printer.Option{
+				printer.WithAlignment(true),
+			},
+			wantOutput: `enum E {
+  UNKNOWN        = 0;
+  STARTED        = 1  [deprecated = true]; // started
+  RUNNING_LONGER = 20 [deprecated = true];
+  option allow_alias = true;
+  A        = 3;
+  B_LONGER = 4;
+}
+`,
+		},
+		{
+			name: "printing with the max line length option",
+			input: `
+message A {
+  int32 a = 1 [deprecated = true];
+  int32 b = 2 [deprecated = true, (foo.bar) = "baz"];
+}
+`,
+			inputOptions: []printer.Option{
+				printer.WithMaxLineLength(40),
+			},
+			wantOutput: `message A {
+  int32 a = 1 [deprecated = true];
+  int32 b = 2 [
+    deprecated = true,
+    (foo.bar) = "baz"
+  ];
+}
+`,
+		},
+		{
+			name: "printing with the blank lines option",
+			input: `
+syntax = "proto3";
+
+
+message A {
+
+  int32 a = 1;
+  // b
+
+
+  int32 b = 2;
+
+}
+`,
+			inputOptions: []printer.Option{
+				printer.WithBlankLines(true),
+			},
+			wantOutput: `syntax = "proto3";
+
+message A {
+  int32 a = 1;
+  // b
+  int32 b = 2;
+}
+`,
+		},
+		{
+			name: "printing streaming rpcs",
+			input: `
+service S {
+  rpc A (stream Req) returns (stream .foo.Res);
+  rpc B (Req) // embedded
+    returns (Res);
+}
+`,
+			wantOutput: `service S {
+  rpc A (stream Req) returns (stream .foo.Res);
+  rpc B (Req) returns (Res) // embedded
+    ;
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			proto, err := protoparser.Parse(
+				strings.NewReader(test.input),
+				protoparser.WithBodyIncludingComments(test.inputBodyIncludingComments),
+			)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			var got bytes.Buffer
+			err = printer.Fprint(&got, proto, test.inputOptions...)
+			if err != nil {
+				t.Errorf("got err %v, but want nil", err)
+				return
+			}
+			if got.String() != test.wantOutput {
+				t.Errorf("got %s, but want %s", got.String(), test.wantOutput)
+			}
+		})
+	}
+}
+
+func TestPrinter_Fprint_element(t *testing.T) {
+	proto, err := protoparser.Parse(strings.NewReader(`
+syntax = "proto3";
+message A {
+  // B is nested.
+  message B {
+    int32 a = 1;
+  }
+}
+`))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	nested := proto.ProtoBody[0].(*parser.Message).MessageBody[0]
+
+	var got bytes.Buffer
+	err = printer.Fprint(&got, nested)
+	if err != nil {
+		t.Errorf("got err %v, but want nil", err)
+		return
+	}
+	want := `// B is nested.
+message B {
+  int32 a = 1;
+}
+`
+	if got.String() != want {
+		t.Errorf("got %s, but want %s", got.String(), want)
+	}
+}
+
+func TestPrinter_Fprint_roundTrip(t *testing.T) {
+	files, err := filepath.Glob("../_testdata/*.proto")
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			printProto := func(input string) string {
+				proto, err := protoparser.Parse(
+					strings.NewReader(input),
+					protoparser.WithBodyIncludingComments(true),
+				)
+				if err != nil {
+					t.Fatalf("got err %v, but want nil", err)
+				}
+				var b bytes.Buffer
+				if err := printer.Fprint(&b, proto); err != nil {
+					t.Fatalf("got err %v, but want nil", err)
+				}
+				return b.String()
+			}
+
+			first := printProto(string(content))
+			second := printProto(first)
+			if first != second {
+				t.Errorf("got %s, but want %s", second, first)
+			}
+		})
+	}
+}
+
+func TestPrinter_Fprint_trivia(t *testing.T) {
+	files, err := filepath.Glob("../_testdata/*.proto")
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	inputs := map[string]string{
+		"spacing":                         "syntax='proto3' ;\n\n\n// leading\nmessage  A{int32 a=1;;  string b = 2 ; // inline\n\n\n  // dangling\n}  \n\n;",
+		"no syntax":                       "// top\npackage  foo ;",
+		"comments before emptyStatements": "syntax = \"proto3\";\n// a\n;\nenum E {\n  // b\n  ;\n}\n",
+		"multiline strings and lists": `syntax = "proto3";
+option (a) = "x"
+  "y";
+message A {
+  option (b) = { list: [ 1 , 2 ] nested { c: 'd' } };
+  int32 a = 1 [ (c) = "e"
+                      "f" ];
+}
+`,
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("got err %v, but want nil", err)
+		}
+		inputs[filepath.Base(file)] = string(content)
+	}
+
+	optionSets := map[string][]protoparser.Option{
+		"trivia": {
+			protoparser.WithTrivia(true),
+		},
+		"trivia with option values and spans": {
+			protoparser.WithTrivia(true),
+			protoparser.WithOptionValue(true),
+			protoparser.WithSpans(true),
+		},
+	}
+	for name, input := range inputs {
+		for optionSetName, opts := range optionSets {
+			input := input
+			opts := opts
+			t.Run(name+" with "+optionSetName, func(t *testing.T) {
+				proto, err := protoparser.Parse(strings.NewReader(input), opts...)
+				if err != nil {
+					t.Fatalf("got err %v, but want nil", err)
+				}
+
+				var got bytes.Buffer
+				if err := printer.Fprint(&got, proto); err != nil {
+					t.Errorf("got err %v, but want nil", err)
+					return
+				}
+				if got.String() != input {
+					t.Errorf("got %q, but want %q", got.String(), input)
+				}
+			})
+		}
+	}
+}
+
+func TestPrinter_Fprint_ignoreTrivia(t *testing.T) {
+	input := "message  A{int32 a=1;}"
+	proto, err := protoparser.Parse(
+		strings.NewReader(input),
+		protoparser.WithTrivia(true),
+	)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+
+	var got bytes.Buffer
+	err = printer.Fprint(&got, proto, printer.WithIgnoreTrivia(true))
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	want := "message A {\n  int32 a = 1;\n}\n"
+	if got.String() != want {
+		t.Errorf("got %q, but want %q", got.String(), want)
+	}
+}
+
+func TestPrinter_Fprint_triviaWithModification(t *testing.T) {
+	input := `syntax = "proto3";
+
+// A is a message.
+message A {
+  int32   a = 1; // a
+  // b
+  string  b = 2;
+
+  message Nested   { int32 c = 1; }
+}
+
+service S {
+  rpc   Get (A) returns (A);
+}
+`
+	tests := []struct {
+		name       string
+		modify     func(proto *parser.Proto)
+		wantOutput string
+	}{
+		{
+			name:       "printing without any modification",
+			modify:     func(*parser.Proto) {},
+			wantOutput: input,
+		},
+		{
+			name: "renaming a field",
+			modify: func(proto *parser.Proto) {
+				message := proto.ProtoBody[0].(*parser.Message)
+				message.MessageBody[1].(*parser.Field).FieldName = "renamed"
+			},
+			wantOutput: `syntax = "proto3";
+
+// A is a message.
+message A {
+  int32   a = 1; // a
+  // b
+  string renamed = 2;
+
+  message Nested   { int32 c = 1; }
+}
+
+service S {
+  rpc   Get (A) returns (A);
+}
+`,
+		},
+		{
+			name: "appending a field",
+			modify: func(proto *parser.Proto) {
+				message := proto.ProtoBody[0].(*parser.Message)
+				message.MessageBody = append(message.MessageBody, &parser.Field{
+					Type:        "bool",
+					FieldName:   "added",
+					FieldNumber: "3",
+				})
+			},
+			wantOutput: `syntax = "proto3";
+
+// A is a message.
+message A {
+  int32   a = 1; // a
+  // b
+  string  b = 2;
+
+  message Nested   { int32 c = 1; }
+  bool added = 3;
+}
+
+service S {
+  rpc   Get (A) returns (A);
+}
+`,
+		},
+		{
+			name: "removing the last field and the rpc",
+			modify: func(proto *parser.Proto) {
+				message := proto.ProtoBody[0].(*parser.Message)
+				message.MessageBody = message.MessageBody[:2]
+				service := proto.ProtoBody[1].(*parser.Service)
+				service.ServiceBody = nil
+			},
+			wantOutput: `syntax = "proto3";
+
+// A is a message.
+message A {
+  int32   a = 1; // a
+  // b
+  string  b = 2;
+}
+
+service S {
+}
+`,
+		},
+		{
+			name: "changing a comment",
+			modify: func(proto *parser.Proto) {
+				message := proto.ProtoBody[0].(*parser.Message)
+				message.Comments[0].Raw = "// A is changed."
+			},
+			wantOutput: `syntax = "proto3";
+
+// A is changed.
+message A {
+  int32   a = 1; // a
+  // b
+  string  b = 2;
+
+  message Nested   { int32 c = 1; }
+}
+
+service S {
+  rpc   Get (A) returns (A);
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			proto, err := protoparser.Parse(
+				strings.NewReader(input),
+				protoparser.WithTrivia(true),
+			)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			test.modify(proto)
+
+			var got bytes.Buffer
+			if err := printer.Fprint(&got, proto); err != nil {
+				t.Errorf("got err %v, but want nil", err)
+				return
+			}
+			if got.String() != test.wantOutput {
+				t.Errorf("got %s, but want %s", got.String(), test.wantOutput)
+			}
+		})
+	}
+}
+
+func TestPrinter_Fprint_optionConstant(t *testing.T) {
+	input := `message A {
+  int32 a = 1 [(o) = { b: 1 c: "x"  "y" }];
+}
+`
+	tests := []struct {
+		name         string
+		inputOptions []protoparser.Option
+		wantOutput   string
+	}{
+		{
+			name: "printing the flattened constant",
+			wantOutput: `message A {
+  int32 renamed = 1 [(o) = {b:1 c:"xy"}];
+}
+`,
+		},
+		{
+			name: "printing the source text of the constant",
+			inputOptions: []protoparser.Option{
+				protoparser.WithTrivia(true),
+				protoparser.WithSpans(true),
+			},
+			wantOutput: `message A {
+  int32 renamed = 1 [(o) = { b: 1 c: "x"  "y" }];
+}
+`,
+		},
+		{
+			name: "printing the structured value",
+			inputOptions: []protoparser.Option{
+				protoparser.WithOptionValue(true),
+			},
+			wantOutput: `message A {
+  int32 renamed = 1 [(o) = {
+    b: 1
+    c: "xy"
+  }];
+}
+`,
+		},
+		{
+			name: "printing the structured value with the source text of the strings",
+			inputOptions: []protoparser.Option{
+				protoparser.WithTrivia(true),
+				protoparser.WithOptionValue(true),
+			},
+			wantOutput: `message A {
+  int32 renamed = 1 [(o) = {
+    b: 1
+    c: "x" "y"
+  }];
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			proto, err := protoparser.Parse(strings.NewReader(input), test.inputOptions...)
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			proto.ProtoBody[0].(*parser.Message).MessageBody[0].(*parser.Field).FieldName = "renamed"
+
+			var got bytes.Buffer
+			if err := printer.Fprint(&got, proto); err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			if got.String() != test.wantOutput {
+				t.Errorf("got %s, but want %s", got.String(), test.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package printer
+
+import (
+	"strconv"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func (s *state) proto(p *parser.Proto) {
+	s.inProto = true
+
+	var nodes []parser.Visitee
+	if p.Syntax != nil {
+		nodes = append(nodes, p.Syntax)
+	}
+	if p.Edition != nil {
+		nodes = append(nodes, p.Edition)
+	}
+	s.elements(append(nodes, p.ProtoBody...))
+
+	if p.Meta != nil && s.usable(p.Meta.Trivia) {
+		s.write(p.Meta.Trivia.Trailing)
+		return
+	}
+	s.ensureLine()
+}
+
+func (s *state) syntax(syntax *parser.Syntax) {
+	version := syntax.ProtobufVersionQuote
+	if version == "" {
+		version = strconv.Quote(syntax.ProtobufVersion)
+	}
+	s.write("syntax = ", version, ";")
+}
+
+func (s *state) edition(edition *parser.Edition) {
+	value := edition.EditionQuote
+	if value == "" {
+		value = strconv.Quote(edition.Edition)
+	}
+	s.write("edition = ", value, ";")
+}
+
+func (s *state) importStatement(i *parser.Import) {
+	s.write("import ")
+	switch i.Modifier {
+	case parser.ImportModifierPublic:
+		s.write("public ")
+	case parser.ImportModifierWeak:
+		s.write("weak ")
+	}
+	s.write(i.Location, ";")
+}
+
+func (s *state) option(option *parser.Option) {
+	s.write("option ", option.OptionName, " = ")
+	s.constant(constant{
+		text:  option.Constant,
+		value: option.Value,
+		span:  option.ConstantSpan,
+		src:   sourceOf(option.Meta),
+	})
+	s.write(";")
+}
@@ -0,0 +1,55 @@
+package printer
+
+import (
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+func (s *state) service(svc *parser.Service) {
+	s.write("service ", svc.ServiceName, " ")
+	s.block(svc.InlineCommentBehindLeftCurly, svc.ServiceBody, svc.Meta.Trivia)
+}
+
+func (s *state) rpc(r *parser.RPC) {
+	s.write("rpc ", r.RPCName, " (")
+	if r.RPCRequest != nil {
+		if r.RPCRequest.IsStream {
+			s.write("stream ")
+		}
+		s.write(r.RPCRequest.MessageType)
+	}
+	s.write(") returns (")
+	if r.RPCResponse != nil {
+		if r.RPCResponse.IsStream {
+			s.write("stream ")
+		}
+		s.write(r.RPCResponse.MessageType)
+	}
+	s.write(")")
+
+	sep := " "
+	for _, comment := range r.EmbeddedComments {
+		s.write(sep)
+		s.writeComment(comment)
+		sep = " "
+		if !comment.IsCStyle() {
+			// A C++-style comment lasts until the end of the line.
+			s.ensureLine()
+			s.depth++
+			s.writeIndent()
+			s.depth--
+			sep = ""
+		}
+	}
+
+	if len(r.Options) == 0 && r.InlineCommentBehindLeftCurly == nil && !s.usable(r.Meta.Trivia) {
+		s.write(";")
+		return
+	}
+
+	var body []parser.Visitee
+	for _, option := range r.Options {
+		body = append(body, option)
+	}
+	s.write(sep)
+	s.block(r.InlineCommentBehindLeftCurly, body, r.Meta.Trivia)
+}
@@ -0,0 +1,134 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/lexer"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// unchanged reports whether the verbatim source text still represents the node.
+// It parses the text again and compares the canonical layouts of both.
+func (s *state) unchanged(node parser.Visitee, text string) bool {
+	original, err := reparse(node, text)
+	if err != nil {
+		return false
+	}
+	return s.canonicalBody(node) == s.canonicalBody(original)
+}
+
+func (s *state) canonicalBody(node parser.Visitee) string {
+	var b strings.Builder
+	c := &state{
+		Printer:     s.Printer,
+		w:           &b,
+		canonical:   true,
+		comparing:   true,
+		atLineStart: true,
+	}
+	c.body(node, nil)
+	return b.String()
+}
+
+// reparse parses the text as the same type of the node.
+// The option values are parsed if the node has any, so that both are printed from the same kind of constants.
+func reparse(node parser.Visitee, text string) (parser.Visitee, error) {
+	optionValue := hasOptionValue(node)
+	newParser := func(input string) *parser.Parser {
+		return parser.NewParser(
+			lexer.NewLexer(strings.NewReader(input)),
+			parser.WithPermissive(true),
+			parser.WithBodyIncludingComments(true),
+			parser.WithOptionValue(optionValue),
+		)
+	}
+	p := newParser(text)
+
+	var parsed parser.Visitee
+	var err error
+	switch node.(type) {
+	case *parser.Syntax:
+		parsed, err = p.ParseSyntax()
+	case *parser.Edition:
+		parsed, err = p.ParseEdition()
+	case *parser.Import:
+		parsed, err = p.ParseImport()
+	case *parser.Package:
+		parsed, err = p.ParsePackage()
+	case *parser.Option:
+		parsed, err = p.ParseOption()
+	case *parser.EmptyStatement:
+		if text != ";" {
+			return nil, fmt.Errorf("invalid emptyStatement %q", text)
+		}
+		parsed = &parser.EmptyStatement{}
+	case *parser.Message:
+		parsed, err = p.ParseMessage()
+	case *parser.Enum:
+		parsed, err = p.ParseEnum()
+	case *parser.EnumField:
+		var enum *parser.Enum
+		p = newParser("enum E {\n" + text + "\n}")
+		enum, err = p.ParseEnum()
+		if err == nil && len(enum.EnumBody) == 1 {
+			parsed = enum.EnumBody[0]
+		}
+	case *parser.Service:
+		parsed, err = p.ParseService()
+	case *parser.RPC:
+		var service *parser.Service
+		p = newParser("service S {\n" + text + "\n}")
+		service, err = p.ParseService()
+		if err == nil && len(service.ServiceBody) == 1 {
+			parsed = service.ServiceBody[0]
+		}
+	case *parser.Field:
+		parsed, err = p.ParseField()
+	case *parser.MapField:
+		parsed, err = p.ParseMapField()
+	case *parser.GroupField:
+		parsed, err = p.ParseGroupField()
+	case *parser.Oneof:
+		parsed, err = p.ParseOneof()
+	case *parser.OneofField:
+		var oneof *parser.Oneof
+		p = newParser("oneof o {\n" + text + "\n}")
+		oneof, err = p.ParseOneof()
+		if err == nil && len(oneof.OneofFields) == 1 {
+			parsed = oneof.OneofFields[0]
+		}
+	case *parser.Reserved:
+		parsed, err = p.ParseReserved()
+	case *parser.Extensions:
+		parsed, err = p.ParseExtensions()
+	case *parser.Declaration:
+		parsed, err = p.ParseDeclaration()
+	case *parser.Extend:
+		parsed, err = p.ParseExtend()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if parsed == nil || !p.IsEOF() {
+		return nil, fmt.Errorf("failed to parse %q as %T", text, node)
+	}
+	return parsed, nil
+}
+
+// hasOptionValue reports whether any option in the node has the structured value.
+func hasOptionValue(node parser.Visitee) bool {
+	found := false
+	parser.Inspect(node, func(n parser.Node) bool {
+		switch n := n.(type) {
+		case *parser.Option:
+			found = found || n.Value != nil
+		case *parser.FieldOption:
+			found = found || n.Value != nil
+		case *parser.EnumValueOption:
+			found = found || n.Value != nil
+		}
+		return !found
+	})
+	return found
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/yoheimuta/go-protoparser/v4/interpret/unordered"
 	"github.com/yoheimuta/go-protoparser/v4/lexer"
 	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
 )
 
 // ParseConfig is a config for parser.
@@ -13,7 +14,13 @@ type ParseConfig struct {
 	debug                 bool
 	permissive            bool
 	bodyIncludingComments bool
+	trivia                bool
+	recovery              bool
+	optionValue           bool
+	optionName            bool
+	spans                 bool
 	filename              string
+	columnEncoding        meta.Encoding
 }
 
 // Option is an option for ParseConfig.
@@ -41,6 +48,47 @@ func WithBodyIncludingComments(bodyIncludingComments bool) Option {
 	}
 }
 
+// WithTrivia is an option to record the whitespace and the verbatim source text of each element.
+// The printer package uses them to reproduce the original source byte for byte.
+// It implies WithBodyIncludingComments(true).
+func WithTrivia(trivia bool) Option {
+	return func(c *ParseConfig) {
+		c.trivia = trivia
+	}
+}
+
+// WithRecovery is an option to keep parsing after an error.
+// Parse returns the partially parsed Proto along with parser.Errors which holds every error found.
+func WithRecovery(recovery bool) Option {
+	return func(c *ParseConfig) {
+		c.recovery = recovery
+	}
+}
+
+// WithOptionValue is an option to set the structured value of each option constant.
+// See parser.OptionValue.
+func WithOptionValue(optionValue bool) Option {
+	return func(c *ParseConfig) {
+		c.optionValue = optionValue
+	}
+}
+
+// WithOptionName is an option to set the structured name of each option.
+// See parser.OptionName.
+func WithOptionName(optionName bool) Option {
+	return func(c *ParseConfig) {
+		c.optionName = optionName
+	}
+}
+
+// WithSpans is an option to record the ranges of the sub-elements, like the names, the types and the numbers,
+// and the end of each element. See parser.WithSpans.
+func WithSpans(spans bool) Option {
+	return func(c *ParseConfig) {
+		c.spans = spans
+	}
+}
+
 // WithFilename is an option to set filename to the Position.
 func WithFilename(filename string) Option {
 	return func(c *ParseConfig) {
@@ -48,23 +96,46 @@ func WithFilename(filename string) Option {
 	}
 }
 
+// WithColumnEncoding is an option to set the unit counted by the column of the positions.
+// The default is meta.EncodingRune. Use meta.EncodingUTF16 to get the columns editors expect.
+// With another encoding, use diagnostic.RangeOfEncoding and diagnostic.FromErrorEncoding with the same one
+// to convert the positions into diagnostics.
+func WithColumnEncoding(encoding meta.Encoding) Option {
+	return func(c *ParseConfig) {
+		c.columnEncoding = encoding
+	}
+}
+
 // Parse parses a Protocol Buffer file.
 func Parse(input io.Reader, options ...Option) (*parser.Proto, error) {
+	return parse(input, newParseConfig(options))
+}
+
+func newParseConfig(options []Option) *ParseConfig {
 	config := &ParseConfig{
 		permissive: true,
 	}
 	for _, opt := range options {
 		opt(config)
 	}
+	return config
+}
 
+func parse(input io.Reader, config *ParseConfig) (*parser.Proto, error) {
 	p := parser.NewParser(
 		lexer.NewLexer(
 			input,
 			lexer.WithDebug(config.debug),
 			lexer.WithFilename(config.filename),
+			lexer.WithColumnEncoding(config.columnEncoding),
 		),
 		parser.WithPermissive(config.permissive),
 		parser.WithBodyIncludingComments(config.bodyIncludingComments),
+		parser.WithTrivia(config.trivia),
+		parser.WithRecovery(config.recovery),
+		parser.WithOptionValue(config.optionValue),
+		parser.WithOptionName(config.optionName),
+		parser.WithSpans(config.spans),
 	)
 	return p.ParseProto()
 }
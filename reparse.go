@@ -0,0 +1,315 @@
+package protoparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// Edit is a change of the source text which replaces the bytes from Start to End with NewText.
+// Use meta.LineIndex to convert the lines and the columns editors send to the offsets.
+type Edit struct {
+	// Start is the byte offset of the first replaced byte.
+	Start int
+	// End is the byte offset just after the last replaced byte. It is equal to Start for an insertion.
+	End int
+	// NewText is the text replacing the range.
+	NewText string
+}
+
+// Reparse applies the edit to src, which proto was parsed from, and returns the Proto of the new source along with the new source.
+//
+// It parses again only the top-level declarations the edit touches and reuses the other ones of proto.
+// The ones before the edit are shared with proto, and the ones after the edit are copied to shift their positions,
+// so that proto is kept unchanged.
+// It parses the whole new source instead when the edit touches the syntax or the edition,
+// or the touched declarations do not parse by themselves, like when the edit removes a closing brace.
+//
+// proto must be parsed from src without errors, and the options must be the same as the ones it was parsed with.
+// If proto is nil, Reparse parses the whole new source.
+func Reparse(proto *parser.Proto, src string, edit Edit, options ...Option) (*parser.Proto, string, error) {
+	if edit.Start < 0 || edit.End < edit.Start || len(src) < edit.End {
+		return nil, src, fmt.Errorf("invalid edit range [%d, %d) of the source of %d bytes", edit.Start, edit.End, len(src))
+	}
+	newSrc := src[:edit.Start] + edit.NewText + src[edit.End:]
+
+	config := newParseConfig(options)
+	if proto != nil {
+		if reparsed, ok := (&reparser{config: config, src: src, newSrc: newSrc, edit: edit}).reparse(proto); ok {
+			return reparsed, newSrc, nil
+		}
+	}
+	reparsed, err := parse(strings.NewReader(newSrc), config)
+	return reparsed, newSrc, err
+}
+
+// reparser reparses the top-level declarations touched by an edit.
+//
+// The source is divided into chunks at the end of each top-level declaration except comments.
+// A chunk holds the whitespace and the comments preceding its declaration, and the last one holds the ones at the end of the file.
+// The reparsed region is a run of chunks such that only whitespace follows either of its ends on the same line,
+// so that no inline comment moves across them, and no semicolon follows them after whitespace,
+// so that no semicolon is absorbed by the block before them.
+type reparser struct {
+	config *ParseConfig
+	src    string
+	newSrc string
+	edit   Edit
+}
+
+// chunk is the range of the body of the Proto ending with a declaration.
+type chunk struct {
+	// last is the index of the declaration in the body. It is -1 for the header.
+	last int
+	// end is the position just after the declaration, including its inline comment.
+	end meta.Position
+}
+
+func (r *reparser) reparse(proto *parser.Proto) (*parser.Proto, bool) {
+	// chunks[0] is the header consisting of the syntax and the edition, which may be empty.
+	chunks, ok := r.chunks(proto)
+	if !ok {
+		return nil, false
+	}
+	header := chunks[0].end.Offset
+	if 0 < header && r.edit.Start <= header {
+		return nil, false
+	}
+
+	// endOffset returns the end of the i-th chunk. The one after the last declaration ends at the end of the file.
+	endOffset := func(i int) int {
+		if i == len(chunks) {
+			return len(r.src)
+		}
+		return chunks[i].end.Offset
+	}
+	// The region is from the end of chunks[first-1] to the end of chunks[last].
+	// It includes the chunk ending at the edit so that the edit can add an inline comment to the declaration,
+	// and the chunk after the edit so that the edit can add a leading comment to the declaration.
+	first := 1
+	for endOffset(first) < r.edit.Start {
+		first++
+	}
+	last := first
+	for last < len(chunks) && endOffset(last) <= r.edit.End {
+		last++
+	}
+	for 1 < first && r.followed(r.newSrc, chunks[first-1].end.Offset) {
+		first--
+	}
+	if first == 1 && 0 < header && r.followedOnLine(r.newSrc, header) {
+		return nil, false
+	}
+	delta := len(r.edit.NewText) - (r.edit.End - r.edit.Start)
+	for last < len(chunks) && r.followed(r.newSrc, chunks[last].end.Offset+delta) {
+		last++
+	}
+
+	start := chunks[first-1].end
+	end := len(r.newSrc)
+	if last < len(chunks) {
+		end = chunks[last].end.Offset + delta
+	}
+	fragment, err := parse(strings.NewReader(r.newSrc[start.Offset:end]), r.config)
+	if err != nil || fragment.Syntax != nil || fragment.Edition != nil {
+		return nil, false
+	}
+	if last < len(chunks) {
+		// The fragment must end with a declaration. Otherwise, the trailing comments belong to the next one.
+		fragmentChunks, ok := (&reparser{config: r.config, src: r.newSrc[start.Offset:end]}).chunks(fragment)
+		if !ok || len(fragmentChunks) < 2 {
+			return nil, false
+		}
+		lastChunk := fragmentChunks[len(fragmentChunks)-1]
+		if lastChunk.last != len(fragment.ProtoBody)-1 || lastChunk.end.Offset != end-start.Offset {
+			return nil, false
+		}
+	}
+
+	shiftPositions(fragment.ProtoBody, func(pos *meta.Position) {
+		if pos.Line == 1 {
+			pos.Column += start.Column - 1
+		}
+		pos.Line += start.Line - 1
+		pos.Offset += start.Offset
+	})
+	var suffix []parser.Visitee
+	if last < len(chunks) {
+		suffix = proto.ProtoBody[chunks[last].last+1:]
+		lineDelta := strings.Count(r.edit.NewText, "\n") - strings.Count(r.src[r.edit.Start:r.edit.End], "\n")
+		if delta != 0 || lineDelta != 0 {
+			suffix = parser.Clone(suffix).([]parser.Visitee)
+			shiftPositions(suffix, func(pos *meta.Position) {
+				pos.Line += lineDelta
+				pos.Offset += delta
+			})
+		}
+	}
+
+	var body []parser.Visitee
+	body = append(body, proto.ProtoBody[:chunks[first-1].last+1]...)
+	body = append(body, fragment.ProtoBody...)
+	body = append(body, suffix...)
+	reparsed := &parser.Proto{
+		Syntax:    proto.Syntax,
+		Edition:   proto.Edition,
+		ProtoBody: body,
+		Meta: &parser.ProtoMeta{
+			Filename: r.config.filename,
+		},
+	}
+	if r.config.trivia {
+		reparsed.Meta.Trivia = &meta.Trivia{
+			Trailing: r.newSrc[len(strings.TrimRight(r.newSrc, " \t\n\v\f\r")):],
+		}
+	}
+	return reparsed, true
+}
+
+// chunks returns the header followed by the chunks ending with each declaration of the body.
+func (r *reparser) chunks(proto *parser.Proto) ([]chunk, bool) {
+	header := chunk{
+		last: -1,
+		end:  meta.Position{Filename: r.config.filename, Offset: 0, Line: 1, Column: 1},
+	}
+	if proto.Syntax != nil {
+		end, ok := r.endOf(proto.Syntax.Meta, proto.Syntax.InlineComment)
+		if !ok {
+			return nil, false
+		}
+		header.end = end
+	}
+	if proto.Edition != nil {
+		end, ok := r.endOf(proto.Edition.Meta, proto.Edition.InlineComment)
+		if !ok {
+			return nil, false
+		}
+		if header.end.Offset < end.Offset {
+			header.end = end
+		}
+	}
+
+	chunks := []chunk{header}
+	for i, v := range proto.ProtoBody {
+		var m meta.Meta
+		var inlineComment *parser.Comment
+		switch v := v.(type) {
+		case *parser.Comment:
+			continue
+		case *parser.Import:
+			m, inlineComment = v.Meta, v.InlineComment
+		case *parser.Package:
+			m, inlineComment = v.Meta, v.InlineComment
+		case *parser.Option:
+			m, inlineComment = v.Meta, v.InlineComment
+		case *parser.Message:
+			m, inlineComment = v.Meta, v.InlineComment
+		case *parser.Enum:
+			m, inlineComment = v.Meta, v.InlineComment
+		case *parser.Service:
+			m, inlineComment = v.Meta, v.InlineComment
+		case *parser.Extend:
+			m, inlineComment = v.Meta, v.InlineComment
+		case *parser.EmptyStatement:
+			m, inlineComment = v.Meta, v.InlineComment
+		default:
+			return nil, false
+		}
+		end, ok := r.endOf(m, inlineComment)
+		if !ok || end.Offset <= chunks[len(chunks)-1].end.Offset {
+			return nil, false
+		}
+		chunks = append(chunks, chunk{last: i, end: end})
+	}
+	return chunks, true
+}
+
+// endOf returns the position just after the element, or its inline comment if any.
+func (r *reparser) endOf(m meta.Meta, inlineComment *parser.Comment) (meta.Position, bool) {
+	end := m.LastPos
+	if inlineComment != nil {
+		end = inlineComment.Meta.LastPos
+	}
+	if end.Offset < 0 || len(r.src) <= end.Offset {
+		return meta.Position{}, false
+	}
+	ch, size := utf8.DecodeRuneInString(r.src[end.Offset:])
+	end.Offset += size
+	end.Column += r.config.columnEncoding.Len(ch)
+	return end, true
+}
+
+// followed reports whether the chunk ending at the offset can not be a boundary of the region.
+// The parser absorbs a semicolon following a block into it, even on another line.
+func (r *reparser) followed(src string, offset int) bool {
+	return r.followedOnLine(src, offset) || strings.HasPrefix(strings.TrimLeft(src[offset:], " \t\n\v\f\r"), ";")
+}
+
+// followedOnLine reports whether any text other than whitespace follows the offset on the same line.
+func (r *reparser) followedOnLine(src string, offset int) bool {
+	for _, ch := range src[offset:] {
+		switch ch {
+		case '\n':
+			return false
+		case ' ', '\t', '\v', '\f', '\r':
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// shiftPositions calls shift with every position set in the elements, visiting each of them once.
+func shiftPositions(elements []parser.Visitee, shift func(pos *meta.Position)) {
+	type visitKey struct {
+		typ reflect.Type
+		ptr uintptr
+	}
+	positionType := reflect.TypeOf(meta.Position{})
+	visited := make(map[visitKey]bool)
+
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() {
+				return
+			}
+			key := visitKey{typ: v.Type(), ptr: v.Pointer()}
+			if visited[key] {
+				return
+			}
+			visited[key] = true
+			walk(v.Elem())
+		case reflect.Interface:
+			if !v.IsNil() {
+				walk(v.Elem())
+			}
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Struct:
+			if !v.CanAddr() {
+				return
+			}
+			if v.Type() == positionType {
+				// The zero value, like the one of an unrecorded span, is not a position.
+				if pos := v.Addr().Interface().(*meta.Position); pos.Line != 0 {
+					shift(pos)
+				}
+				return
+			}
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).IsExported() {
+					walk(v.Field(i))
+				}
+			}
+		}
+	}
+	walk(reflect.ValueOf(elements))
+}
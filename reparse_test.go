@@ -0,0 +1,262 @@
+package protoparser_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+const reparseSource = `syntax = "proto3";
+
+package foo;
+
+// A is a message.
+message A {
+  string a = 1; // a
+}
+
+enum E {
+  E_UNSPECIFIED = 0;
+}
+
+message B {
+  A a = 1;
+}
+// trailing
+`
+
+func TestReparse(t *testing.T) {
+	tests := []struct {
+		name       string
+		inputEdit  func(src string) protoparser.Edit
+		wantShared []string
+		wantErr    bool
+	}{
+		{
+			name: "adding a field to a message",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "  string a = 1;")
+				return protoparser.Edit{Start: i, End: i, NewText: "  int32 b = 2;\n"}
+			},
+			wantShared: []string{"package"},
+		},
+		{
+			name: "renaming an enum value across its name",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "UNSPECIFIED")
+				return protoparser.Edit{Start: i, End: i + len("UNSPECIFIED"), NewText: "UNKNOWN"}
+			},
+			wantShared: []string{"package", "A"},
+		},
+		{
+			name: "adding an inline comment behind a message",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "}\n\nenum") + 1
+				return protoparser.Edit{Start: i, End: i, NewText: " // end of A"}
+			},
+			wantShared: []string{"package"},
+		},
+		{
+			name: "adding a leading comment to a message",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "message B")
+				return protoparser.Edit{Start: i, End: i, NewText: "// B is a message.\n"}
+			},
+			wantShared: []string{"package", "A"},
+		},
+		{
+			name: "deleting a message",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "enum E")
+				j := strings.Index(src, "message B")
+				return protoparser.Edit{Start: i, End: j}
+			},
+			wantShared: []string{"package", "A"},
+		},
+		{
+			name: "appending a message at the end",
+			inputEdit: func(src string) protoparser.Edit {
+				return protoparser.Edit{Start: len(src), End: len(src), NewText: "message C {}\n"}
+			},
+			wantShared: []string{"package", "A", "E", "B"},
+		},
+		{
+			name: "putting a declaration on the same line as the next one",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "\n\nmessage B")
+				return protoparser.Edit{Start: i, End: i + 2, NewText: " "}
+			},
+			wantShared: []string{"package", "A"},
+		},
+		{
+			name: "renaming an enum value keeping the length",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "UNSPECIFIED")
+				return protoparser.Edit{Start: i, End: i + len("UNSPECIFIED"), NewText: "UNKNOWN_ONE"}
+			},
+			wantShared: []string{"package", "A", "B"},
+		},
+		{
+			name: "adding a semicolon absorbed by the message on the previous line",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "enum E")
+				return protoparser.Edit{Start: i, End: i, NewText: ";\n"}
+			},
+			wantShared: []string{"package"},
+		},
+		{
+			name: "removing a closing brace",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "}\n\nenum")
+				return protoparser.Edit{Start: i, End: i + 1}
+			},
+			wantErr: true,
+		},
+		{
+			name: "changing the syntax",
+			inputEdit: func(src string) protoparser.Edit {
+				i := strings.Index(src, "proto3")
+				return protoparser.Edit{Start: i, End: i + len("proto3"), NewText: "proto2"}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			opts := []protoparser.Option{
+				protoparser.WithFilename("foo.proto"),
+				protoparser.WithTrivia(true),
+				protoparser.WithSpans(true),
+			}
+			proto, err := protoparser.Parse(strings.NewReader(reparseSource), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			original := parser.Clone(proto).(*parser.Proto)
+			before := declarations(proto)
+
+			edit := test.inputEdit(reparseSource)
+			got, gotSrc, err := protoparser.Reparse(proto, reparseSource, edit, opts...)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got nil, but want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got err %v", err)
+			}
+			want, err := protoparser.Parse(strings.NewReader(gotSrc), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parser.Equal(got, want) {
+				t.Errorf("got a Proto different from the one parsed from the whole source")
+			}
+			if !parser.Equal(proto, original) {
+				t.Errorf("got the original Proto changed, but want it kept")
+			}
+
+			after := declarations(got)
+			for _, name := range test.wantShared {
+				if before[name] == nil || after[name] != before[name] {
+					t.Errorf("got %s parsed again or copied, but want it shared", name)
+				}
+			}
+		})
+	}
+}
+
+// declarations returns the top-level declarations of the proto keyed by their names.
+func declarations(proto *parser.Proto) map[string]parser.Visitee {
+	decls := make(map[string]parser.Visitee)
+	for _, v := range proto.ProtoBody {
+		switch v := v.(type) {
+		case *parser.Package:
+			decls["package"] = v
+		case *parser.Message:
+			decls[v.MessageName] = v
+		case *parser.Enum:
+			decls[v.EnumName] = v
+		}
+	}
+	return decls
+}
+
+func TestReparse_invalidEdit(t *testing.T) {
+	_, _, err := protoparser.Reparse(nil, "message A {}", protoparser.Edit{Start: 3, End: 20})
+	if err == nil {
+		t.Errorf("got nil, but want an error")
+	}
+}
+
+func TestReparse_sameAsParse(t *testing.T) {
+	filenames, err := filepath.Glob(filepath.Join("_testdata", "*.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	optionSets := map[string][]protoparser.Option{
+		"trivia and spans": {
+			protoparser.WithTrivia(true),
+			protoparser.WithSpans(true),
+			protoparser.WithOptionName(true),
+			protoparser.WithOptionValue(true),
+		},
+		"utf16 and comments": {
+			protoparser.WithColumnEncoding(meta.EncodingUTF16),
+			protoparser.WithBodyIncludingComments(true),
+		},
+	}
+	insertions := []string{"", " ", "\n", "// c\n", "/* c */", "message X {}", ";", "}"}
+
+	for _, filename := range filenames {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := string(content)
+		if 2048 < len(src) {
+			// Parsing the whole of a large file for every edit takes too long.
+			continue
+		}
+		for name, opts := range optionSets {
+			opts = append(opts, protoparser.WithFilename(filename))
+			if _, err := protoparser.Parse(strings.NewReader(src), opts...); err != nil {
+				continue
+			}
+			var starts []int
+			for start := 0; start < len(src); start += len(src)/12 + 1 {
+				starts = append(starts, start)
+			}
+			for _, start := range append(starts, len(src)) {
+				for i, insertion := range insertions {
+					end := start
+					if i%2 == 0 && start < len(src) {
+						end = start + 1
+					}
+					edit := protoparser.Edit{Start: start, End: end, NewText: insertion}
+
+					proto, err := protoparser.Parse(strings.NewReader(src), opts...)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got, gotSrc, gotErr := protoparser.Reparse(proto, src, edit, opts...)
+					want, wantErr := protoparser.Parse(strings.NewReader(gotSrc), opts...)
+					if (gotErr == nil) != (wantErr == nil) {
+						t.Errorf("%s %s %+v: got err %v, but want %v", filename, name, edit, gotErr, wantErr)
+						continue
+					}
+					if gotErr == nil && !parser.Equal(got, want) {
+						t.Errorf("%s %s %+v: got a Proto different from the one parsed from the whole source", filename, name, edit)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+var (
+	// ErrFileNotFound is the cause of an ImportError when the file is not found in any import path.
+	ErrFileNotFound = errors.New("file not found")
+	// ErrImportCycle is the cause of an ImportError when the imports form a cycle.
+	ErrImportCycle = errors.New("import cycle")
+)
+
+// ImportError is the error returned when an imported file cannot be resolved.
+type ImportError struct {
+	// Pos is the position of the import statement.
+	// It is the zero value if the file is the one given to Resolve.
+	Pos meta.Position
+	// Path is the import path of the file.
+	Path string
+	// Cycle is the chain of the import paths that starts and ends with the same one, if Err is ErrImportCycle.
+	Cycle []string
+	// Err is the cause, such as ErrFileNotFound, ErrImportCycle or a parse error.
+	Err error
+}
+
+func newImportError(imp *parser.Import, path string, cycle []string, err error) *ImportError {
+	e := &ImportError{
+		Path:  path,
+		Cycle: cycle,
+		Err:   err,
+	}
+	if imp != nil {
+		e.Pos = imp.Meta.Pos
+	}
+	return e
+}
+
+func (e *ImportError) Error() string {
+	var msg string
+	if errors.Is(e.Err, ErrImportCycle) {
+		msg = fmt.Sprintf("%v: %s", e.Err, strings.Join(e.Cycle, " -> "))
+	} else {
+		msg = fmt.Sprintf("failed to import %q, err %v", e.Path, e.Err)
+	}
+	if e.Pos.Line == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", e.Pos, msg)
+}
+
+// Unwrap returns the cause.
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the list of the import errors.
+type Errors []*ImportError
+
+func (e Errors) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the import errors.
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
@@ -0,0 +1,229 @@
+// Package resolver resolves the imports of Protocol Buffer files.
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// FileAccessor opens the file at the filename.
+// It must return an error satisfying errors.Is(err, os.ErrNotExist) if the file does not exist.
+type FileAccessor func(filename string) (io.ReadCloser, error)
+
+// Resolver parses files and the files they import transitively.
+type Resolver struct {
+	importPaths  []string
+	accessor     FileAccessor
+	parseOptions []protoparser.Option
+}
+
+// Option is an option for NewResolver.
+type Option func(*Resolver)
+
+// WithImportPaths is an option to set the directories in which imported files are searched, in order.
+// The default is the current directory.
+func WithImportPaths(importPaths ...string) Option {
+	return func(r *Resolver) {
+		r.importPaths = importPaths
+	}
+}
+
+// WithAccessor is an option to set the function to open files.
+// The default opens files in the file system.
+func WithAccessor(accessor FileAccessor) Option {
+	return func(r *Resolver) {
+		r.accessor = accessor
+	}
+}
+
+// WithFiles is an option to open the files from their contents keyed by the slash-separated filenames,
+// instead of the file system. The filenames are the import paths joined to the directories given by WithImportPaths.
+func WithFiles(files map[string]string) Option {
+	return WithAccessor(func(filename string) (io.ReadCloser, error) {
+		content, ok := files[filepath.ToSlash(filename)]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return io.NopCloser(strings.NewReader(content)), nil
+	})
+}
+
+// WithParseOptions is an option to set the options passed to protoparser.Parse.
+// The filename is always set to where the file is found.
+func WithParseOptions(parseOptions ...protoparser.Option) Option {
+	return func(r *Resolver) {
+		r.parseOptions = parseOptions
+	}
+}
+
+// NewResolver creates a new Resolver.
+func NewResolver(opts ...Option) *Resolver {
+	r := &Resolver{
+		importPaths: []string{"."},
+		accessor: func(filename string) (io.ReadCloser, error) {
+			return os.Open(filename)
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// File is a parsed file.
+type File struct {
+	// Path is the import path of the file.
+	Path string
+	// Filename is the name of the file found in the import paths.
+	Filename string
+	// Proto is the parsed file.
+	Proto *parser.Proto
+	// Imports are the files imported by this file, in the order of the import statements.
+	Imports []*File
+}
+
+// FileSet is a set of resolved files.
+type FileSet struct {
+	// Files are the files keyed by their import paths.
+	Files map[string]*File
+
+	sorted []*File
+}
+
+// Sorted returns the files in the dependency order, in which every file comes after the files it imports.
+func (s *FileSet) Sorted() []*File {
+	return s.sorted
+}
+
+// Resolve parses the files at the import paths and all files they import transitively.
+// It returns the files that are resolved, along with Errors holding an *ImportError for each import
+// of a file that is missing or fails to parse, and for each import that forms a cycle.
+// The imports that fail are left out of File.Imports.
+func (r *Resolver) Resolve(paths ...string) (*FileSet, error) {
+	st := &resolveState{
+		Resolver: r,
+		set: &FileSet{
+			Files: make(map[string]*File),
+		},
+		visiting: make(map[string]bool),
+		failed:   make(map[string]error),
+	}
+	for _, p := range paths {
+		st.resolve(path.Clean(filepath.ToSlash(p)), nil)
+	}
+	if 0 < len(st.errs) {
+		return st.set, st.errs
+	}
+	return st.set, nil
+}
+
+// Resolve resolves the files at the import paths with the given options.
+func Resolve(paths []string, opts ...Option) (*FileSet, error) {
+	return NewResolver(opts...).Resolve(paths...)
+}
+
+type resolveState struct {
+	*Resolver
+
+	set  *FileSet
+	errs Errors
+	// visiting is the set of the import paths being resolved.
+	visiting map[string]bool
+	// stack is the chain of the import paths being resolved.
+	stack []string
+	// failed is the cause keyed by the import paths which failed to be found or parsed.
+	failed map[string]error
+}
+
+// resolve resolves the file at the import path. imp is the import statement, or nil for the given files.
+// It records an *ImportError and returns nil if the file cannot be resolved.
+func (st *resolveState) resolve(importPath string, imp *parser.Import) *File {
+	if file, ok := st.set.Files[importPath]; ok {
+		return file
+	}
+	if st.visiting[importPath] {
+		var cycle []string
+		for i, p := range st.stack {
+			if p == importPath {
+				cycle = append(cycle, st.stack[i:]...)
+				break
+			}
+		}
+		st.errs = append(st.errs, newImportError(imp, importPath, append(cycle, importPath), ErrImportCycle))
+		return nil
+	}
+
+	err, ok := st.failed[importPath]
+	var file *File
+	if !ok {
+		file, err = st.parse(importPath)
+	}
+	if err != nil {
+		st.failed[importPath] = err
+		st.errs = append(st.errs, newImportError(imp, importPath, nil, err))
+		return nil
+	}
+
+	st.visiting[importPath] = true
+	st.stack = append(st.stack, importPath)
+	for _, body := range file.Proto.ProtoBody {
+		i, ok := body.(*parser.Import)
+		if !ok {
+			continue
+		}
+		if imported := st.resolve(ImportPath(i), i); imported != nil {
+			file.Imports = append(file.Imports, imported)
+		}
+	}
+	st.stack = st.stack[:len(st.stack)-1]
+	delete(st.visiting, importPath)
+
+	st.set.Files[importPath] = file
+	st.set.sorted = append(st.set.sorted, file)
+	return file
+}
+
+// parse finds the file at the import path in the import paths and parses it.
+func (st *resolveState) parse(importPath string) (*File, error) {
+	for _, dir := range st.importPaths {
+		filename := filepath.Join(dir, filepath.FromSlash(importPath))
+		reader, err := st.accessor(filename)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		opts := append(append([]protoparser.Option(nil), st.parseOptions...), protoparser.WithFilename(filename))
+		proto, err := protoparser.Parse(reader, opts...)
+		if cerr := reader.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &File{
+			Path:     importPath,
+			Filename: filename,
+			Proto:    proto,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w in %v", ErrFileNotFound, st.importPaths)
+}
+
+// ImportPath returns the path of the import statement without quotes.
+func ImportPath(i *parser.Import) string {
+	if len(i.Location) < 2 {
+		return i.Location
+	}
+	return i.Location[1 : len(i.Location)-1]
+}
@@ -0,0 +1,237 @@
+package resolver_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name            string
+		inputFiles      map[string]string
+		inputPaths      []string
+		wantSortedPaths []string
+		wantFilenames   map[string]string
+		wantImports     map[string][]string
+		wantErr         error
+		wantErrPos      meta.Position
+		wantErrCycle    []string
+		wantErrCount    int
+	}{
+		{
+			name: "resolving transitive imports",
+			inputFiles: map[string]string{
+				"root/a.proto":       `import "b.proto"; import "sub/c.proto";`,
+				"root/b.proto":       `import "sub/c.proto";`,
+				"vendor/sub/c.proto": `message C {}`,
+			},
+			inputPaths:      []string{"a.proto"},
+			wantSortedPaths: []string{"sub/c.proto", "b.proto", "a.proto"},
+			wantFilenames: map[string]string{
+				"a.proto":     "root/a.proto",
+				"b.proto":     "root/b.proto",
+				"sub/c.proto": "vendor/sub/c.proto",
+			},
+			wantImports: map[string][]string{
+				"a.proto":     {"b.proto", "sub/c.proto"},
+				"b.proto":     {"sub/c.proto"},
+				"sub/c.proto": nil,
+			},
+		},
+		{
+			name: "resolving multiple files sharing imports",
+			inputFiles: map[string]string{
+				"root/a.proto": `import "c.proto";`,
+				"root/b.proto": `import "c.proto";`,
+				"root/c.proto": ``,
+			},
+			inputPaths:      []string{"b.proto", "a.proto"},
+			wantSortedPaths: []string{"c.proto", "b.proto", "a.proto"},
+		},
+		{
+			name: "preferring the earlier import path",
+			inputFiles: map[string]string{
+				"root/a.proto":   `import "b.proto";`,
+				"root/b.proto":   ``,
+				"vendor/b.proto": ``,
+			},
+			inputPaths:      []string{"a.proto"},
+			wantSortedPaths: []string{"b.proto", "a.proto"},
+			wantFilenames: map[string]string{
+				"a.proto": "root/a.proto",
+				"b.proto": "root/b.proto",
+			},
+		},
+		{
+			name: "reporting a missing file at the import statement",
+			inputFiles: map[string]string{
+				"root/a.proto": `syntax = "proto3";
+import "b.proto";`,
+				"root/b.proto": `
+  import "missing.proto";`,
+			},
+			inputPaths:      []string{"a.proto"},
+			wantSortedPaths: []string{"b.proto", "a.proto"},
+			wantErr:         resolver.ErrFileNotFound,
+			wantErrPos: meta.Position{
+				Filename: filepath.Join("root", "b.proto"),
+				Offset:   3,
+				Line:     2,
+				Column:   3,
+			},
+			wantErrCount: 1,
+		},
+		{
+			name:         "reporting a missing root file",
+			inputPaths:   []string{"missing.proto"},
+			wantErr:      resolver.ErrFileNotFound,
+			wantErrCount: 1,
+		},
+		{
+			name: "reporting every missing file at each import statement",
+			inputFiles: map[string]string{
+				"root/a.proto": `import "missing1.proto"; import "b.proto"; import "missing2.proto";`,
+				"root/b.proto": `
+import "missing1.proto";`,
+			},
+			inputPaths:      []string{"a.proto", "missing3.proto"},
+			wantSortedPaths: []string{"b.proto", "a.proto"},
+			wantImports: map[string][]string{
+				"a.proto": {"b.proto"},
+				"b.proto": nil,
+			},
+			wantErr: resolver.ErrFileNotFound,
+			wantErrPos: meta.Position{
+				Filename: filepath.Join("root", "a.proto"),
+				Line:     1,
+				Column:   1,
+			},
+			wantErrCount: 4,
+		},
+		{
+			name: "reporting an import cycle at the import statement",
+			inputFiles: map[string]string{
+				"root/a.proto": `import "b.proto";`,
+				"root/b.proto": `import "c.proto";`,
+				"root/c.proto": `syntax = "proto3";
+import "b.proto";`,
+			},
+			inputPaths:      []string{"a.proto"},
+			wantSortedPaths: []string{"c.proto", "b.proto", "a.proto"},
+			wantErr:         resolver.ErrImportCycle,
+			wantErrPos: meta.Position{
+				Filename: filepath.Join("root", "c.proto"),
+				Offset:   19,
+				Line:     2,
+				Column:   1,
+			},
+			wantErrCycle: []string{"b.proto", "c.proto", "b.proto"},
+			wantErrCount: 1,
+		},
+		{
+			name: "reporting a self import",
+			inputFiles: map[string]string{
+				"root/a.proto": `import "a.proto";`,
+			},
+			inputPaths:      []string{"a.proto"},
+			wantSortedPaths: []string{"a.proto"},
+			wantErr:         resolver.ErrImportCycle,
+			wantErrPos: meta.Position{
+				Filename: filepath.Join("root", "a.proto"),
+				Line:     1,
+				Column:   1,
+			},
+			wantErrCycle: []string{"a.proto", "a.proto"},
+			wantErrCount: 1,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := resolver.NewResolver(
+				resolver.WithImportPaths("root", "vendor"),
+				resolver.WithFiles(test.inputFiles),
+			)
+			got, err := r.Resolve(test.inputPaths...)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got err %v, but want %v", err, test.wantErr)
+				}
+				var ierr *resolver.ImportError
+				if !errors.As(err, &ierr) {
+					t.Fatalf("got err %T, but want *resolver.ImportError", err)
+				}
+				if !reflect.DeepEqual(ierr.Pos, test.wantErrPos) {
+					t.Errorf("got %v, but want %v", ierr.Pos, test.wantErrPos)
+				}
+				if !reflect.DeepEqual(ierr.Cycle, test.wantErrCycle) {
+					t.Errorf("got %v, but want %v", ierr.Cycle, test.wantErrCycle)
+				}
+				var errs resolver.Errors
+				if !errors.As(err, &errs) {
+					t.Fatalf("got err %T, but want resolver.Errors", err)
+				}
+				if len(errs) != test.wantErrCount {
+					t.Errorf("got %d errors %v, but want %d", len(errs), errs, test.wantErrCount)
+				}
+			} else if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+
+			var gotSortedPaths []string
+			for _, file := range got.Sorted() {
+				gotSortedPaths = append(gotSortedPaths, file.Path)
+				if got.Files[file.Path] != file {
+					t.Errorf("got %v, but want %v", got.Files[file.Path], file)
+				}
+			}
+			if !reflect.DeepEqual(gotSortedPaths, test.wantSortedPaths) {
+				t.Errorf("got %v, but want %v", gotSortedPaths, test.wantSortedPaths)
+			}
+			for path, want := range test.wantFilenames {
+				if got.Files[path].Filename != filepath.FromSlash(want) {
+					t.Errorf("got %v, but want %v", got.Files[path].Filename, want)
+				}
+			}
+			for path, want := range test.wantImports {
+				var gotImports []string
+				for _, file := range got.Files[path].Imports {
+					gotImports = append(gotImports, file.Path)
+				}
+				if !reflect.DeepEqual(gotImports, want) {
+					t.Errorf("got %v, but want %v", gotImports, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolve_fileSystem(t *testing.T) {
+	got, err := resolver.Resolve(
+		[]string{"simple.proto"},
+		resolver.WithImportPaths(filepath.Join("..", "_testdata")),
+	)
+	if !errors.Is(err, resolver.ErrFileNotFound) {
+		t.Fatalf("got err %v, but want %v", err, resolver.ErrFileNotFound)
+	}
+	if len(got.Sorted()) != 1 || got.Files["simple.proto"] == nil {
+		t.Errorf("got %v, but want simple.proto", got.Files)
+	}
+
+	got, err = resolver.Resolve(
+		[]string{"extension_declaration.proto"},
+		resolver.WithImportPaths(filepath.Join("..", "_testdata")),
+	)
+	if err != nil {
+		t.Fatalf("got err %v, but want nil", err)
+	}
+	if len(got.Sorted()) != 1 || got.Files["extension_declaration.proto"].Proto == nil {
+		t.Errorf("got %v, but want extension_declaration.proto", got.Files)
+	}
+}
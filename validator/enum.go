@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"github.com/yoheimuta/go-protoparser/v4/diagnostic"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// enumValue is a value declared in an enum.
+type enumValue struct {
+	enum        string
+	name        string
+	number      string
+	nameRange   diagnostic.Range
+	numberRange diagnostic.Range
+	numberVal   int64
+	numberOK    bool
+}
+
+// enums validates the enums declared in the scope.
+func (v *validator) enums(scope string, enums []*parser.Enum) {
+	// Enum values use C++ scoping rules, that is they are the siblings of their enum.
+	siblings := make(map[string]*enumValue)
+	for _, enum := range enums {
+		values := v.enum(join(scope, enum.EnumName), enum)
+		for _, value := range values {
+			original, ok := siblings[value.name]
+			if !ok {
+				siblings[value.name] = value
+				continue
+			}
+			if original.enum == value.enum {
+				continue
+			}
+			where := "the file"
+			if scope != "" {
+				where = `"` + scope + `"`
+			}
+			v.report(CodeEnumValueScopeConflict, value.nameRange, &original.nameRange, "the name is first used here",
+				"enum value %q of %q is already defined by %q, enum values are siblings of their enum, so the name must be unique within %s",
+				value.name, value.enum, original.enum, where)
+		}
+	}
+}
+
+// enum validates the values of the enum named name and returns them.
+func (v *validator) enum(name string, enum *parser.Enum) []*enumValue {
+	var values []*enumValue
+	allowAlias := false
+	for _, element := range enum.EnumBody {
+		switch e := element.(type) {
+		case *parser.EnumField:
+			value := &enumValue{
+				enum:        name,
+				name:        e.Ident,
+				number:      e.Number,
+				nameRange:   rangeOf(e.IdentSpan, e.Meta.Pos),
+				numberRange: rangeOf(e.NumberSpan, e.Meta.Pos),
+			}
+			value.numberVal, value.numberOK = parseNumber(e.Number)
+			values = append(values, value)
+		case *parser.Option:
+			if e.OptionName == "allow_alias" && e.Constant == "true" {
+				allowAlias = true
+			}
+		}
+	}
+
+	if v.syntax == "proto3" && 0 < len(values) {
+		if first := values[0]; !first.numberOK || first.numberVal != 0 {
+			v.report(CodeEnumFirstValueNotZero, first.numberRange, nil, "",
+				"the first enum value %q of %q must be zero in proto3", first.name, name)
+		}
+	}
+
+	names := make(map[string]*enumValue)
+	numbers := make(map[int64]*enumValue)
+	for _, value := range values {
+		if original, ok := names[value.name]; ok {
+			v.report(CodeDuplicateEnumValueName, value.nameRange, &original.nameRange, "the name is first used here",
+				"enum value %q is already defined in %q", value.name, name)
+			continue
+		}
+		names[value.name] = value
+
+		if !value.numberOK || allowAlias {
+			continue
+		}
+		if original, ok := numbers[value.numberVal]; ok {
+			v.report(CodeDuplicateEnumValueNumber, value.numberRange, &original.numberRange, "the number is first used here",
+				"enum value number %s has already been used in %q by enum value %q, set option allow_alias = true to allow aliases",
+				value.number, name, original.name)
+			continue
+		}
+		numbers[value.numberVal] = value
+	}
+	return values
+}
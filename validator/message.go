@@ -0,0 +1,320 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4/diagnostic"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+const (
+	// maxFieldNumber is the largest field number.
+	maxFieldNumber = 536870911
+	// maxMessageSetNumber is the largest extension number of a message using the MessageSet wire format.
+	maxMessageSetNumber = 2147483646
+	// firstImplementationReserved and lastImplementationReserved are the numbers reserved
+	// for the protocol buffer library implementation.
+	firstImplementationReserved = 19000
+	lastImplementationReserved  = 19999
+)
+
+// field is a field declared in a message.
+type field struct {
+	name        string
+	number      string
+	nameRange   diagnostic.Range
+	numberRange diagnostic.Range
+	hasJSON     bool
+	jsonName    string
+	numberVal   int64
+	numberOK    bool
+}
+
+// numberRange is an inclusive range of a reserved or an extensions statement.
+type numberRange struct {
+	kind  string
+	start int64
+	end   int64
+	text  string
+	rng   diagnostic.Range
+}
+
+// reservedName is a name reserved by a reserved statement.
+type reservedName struct {
+	name string
+	rng  diagnostic.Range
+}
+
+func newField(
+	name string,
+	number string,
+	options []*parser.FieldOption,
+	pos meta.Position,
+	nameSpan meta.Span,
+	numberSpan meta.Span,
+) *field {
+	f := &field{
+		name:        name,
+		number:      number,
+		nameRange:   rangeOf(nameSpan, pos),
+		numberRange: rangeOf(numberSpan, pos),
+		jsonName:    jsonName(name),
+	}
+	for _, option := range options {
+		if option.OptionName == "json_name" {
+			f.hasJSON = true
+		}
+	}
+	f.numberVal, f.numberOK = parseNumber(number)
+	return f
+}
+
+// message validates the body of the message named scope.
+func (v *validator) message(scope string, body []parser.Visitee) {
+	var fields []*field
+	var ranges []*numberRange
+	var names []*reservedName
+	var enums []*parser.Enum
+	maxExtension := int64(maxFieldNumber)
+
+	for _, element := range body {
+		switch e := element.(type) {
+		case *parser.Field:
+			fields = append(fields, newField(e.FieldName, e.FieldNumber, e.FieldOptions, e.Meta.Pos, e.FieldNameSpan, e.FieldNumberSpan))
+		case *parser.MapField:
+			fields = append(fields, newField(e.MapName, e.FieldNumber, e.FieldOptions, e.Meta.Pos, e.MapNameSpan, e.FieldNumberSpan))
+		case *parser.GroupField:
+			fields = append(fields, newField(strings.ToLower(e.GroupName), e.FieldNumber, nil, e.Meta.Pos, e.GroupNameSpan, e.FieldNumberSpan))
+			v.message(join(scope, e.GroupName), e.MessageBody)
+		case *parser.Oneof:
+			for _, f := range e.OneofFields {
+				fields = append(fields, newField(f.FieldName, f.FieldNumber, f.FieldOptions, f.Meta.Pos, f.FieldNameSpan, f.FieldNumberSpan))
+			}
+		case *parser.Reserved:
+			ranges = append(ranges, v.ranges("reserved", e.Ranges, maxFieldNumber, e.Meta.Pos)...)
+			for i, name := range e.FieldNames {
+				var span meta.Span
+				if i < len(e.FieldNameSpans) {
+					span = e.FieldNameSpans[i]
+				}
+				names = append(names, &reservedName{name: unquoteName(name), rng: rangeOf(span, e.Meta.Pos)})
+			}
+		case *parser.Option:
+			if e.OptionName == "message_set_wire_format" && e.Constant == "true" {
+				maxExtension = maxMessageSetNumber
+			}
+		case *parser.Message:
+			v.message(join(scope, e.MessageName), e.MessageBody)
+		case *parser.Enum:
+			enums = append(enums, e)
+		case *parser.Extend:
+			v.extend(scope, e)
+		}
+	}
+	for _, element := range body {
+		if e, ok := element.(*parser.Extensions); ok {
+			ranges = append(ranges, v.ranges("extensions", e.Ranges, maxExtension, e.Meta.Pos)...)
+		}
+	}
+
+	v.fieldNumbers(scope, fields)
+	v.fieldNames(scope, fields)
+	v.rangesOverlap(ranges)
+	v.reservedNames(names)
+	v.fieldsInRanges(fields, ranges, names)
+	v.enums(scope, enums)
+}
+
+// extend validates the numbers of the extension fields and the bodies of the groups declared in scope.
+// The other checks need the extended message, which may be declared in another file.
+func (v *validator) extend(scope string, e *parser.Extend) {
+	for _, element := range e.ExtendBody {
+		switch f := element.(type) {
+		case *parser.Field:
+			v.fieldNumber(newField(f.FieldName, f.FieldNumber, f.FieldOptions, f.Meta.Pos, f.FieldNameSpan, f.FieldNumberSpan), maxMessageSetNumber)
+		case *parser.GroupField:
+			v.fieldNumber(newField(strings.ToLower(f.GroupName), f.FieldNumber, nil, f.Meta.Pos, f.GroupNameSpan, f.FieldNumberSpan), maxMessageSetNumber)
+			v.message(join(scope, f.GroupName), f.MessageBody)
+		}
+	}
+}
+
+// fieldNumber reports the number out of range and reports whether the number is valid.
+func (v *validator) fieldNumber(f *field, max int64) bool {
+	switch {
+	case !f.numberOK, f.numberVal < 1, max < f.numberVal:
+		v.report(CodeFieldNumberOutOfRange, f.numberRange, nil, "",
+			"field number %s of %q is out of range, it must be between 1 and %d", f.number, f.name, max)
+		return false
+	case firstImplementationReserved <= f.numberVal && f.numberVal <= lastImplementationReserved:
+		v.report(CodeImplementationReservedNumber, f.numberRange, nil, "",
+			"field number %s of %q is in %d to %d, which are reserved for the protocol buffer library implementation",
+			f.number, f.name, firstImplementationReserved, lastImplementationReserved)
+		return false
+	}
+	return true
+}
+
+func (v *validator) fieldNumbers(scope string, fields []*field) {
+	used := make(map[int64]*field)
+	for _, f := range fields {
+		if !v.fieldNumber(f, maxFieldNumber) {
+			continue
+		}
+		if original, ok := used[f.numberVal]; ok {
+			v.report(CodeDuplicateFieldNumber, f.numberRange, &original.numberRange, "the number is first used here",
+				"field number %s has already been used in %q by field %q", f.number, scope, original.name)
+			continue
+		}
+		used[f.numberVal] = f
+	}
+}
+
+func (v *validator) fieldNames(scope string, fields []*field) {
+	names := make(map[string]*field)
+	jsonNames := make(map[string]*field)
+	for _, f := range fields {
+		if original, ok := names[f.name]; ok {
+			v.report(CodeDuplicateFieldName, f.nameRange, &original.nameRange, "the name is first used here",
+				"field %q is already defined in %q", f.name, scope)
+			continue
+		}
+		names[f.name] = f
+
+		if v.syntax == "proto2" || f.hasJSON {
+			continue
+		}
+		if original, ok := jsonNames[f.jsonName]; ok {
+			v.report(CodeJSONNameConflict, f.nameRange, &original.nameRange, "the JSON name is first used here",
+				"the JSON name %q of field %q conflicts with field %q", f.jsonName, f.name, original.name)
+			continue
+		}
+		jsonNames[f.jsonName] = f
+	}
+}
+
+func (v *validator) fieldsInRanges(fields []*field, ranges []*numberRange, names []*reservedName) {
+	for _, f := range fields {
+		if f.numberOK {
+			for _, r := range ranges {
+				if f.numberVal < r.start || r.end < f.numberVal {
+					continue
+				}
+				if r.kind == "reserved" {
+					v.report(CodeReservedFieldNumber, f.numberRange, &r.rng, "the number is reserved here",
+						"field %q uses the reserved number %s", f.name, f.number)
+				} else {
+					v.report(CodeFieldNumberInExtensionRange, f.numberRange, &r.rng, "the extension range is declared here",
+						"field %q uses the number %s in the extension range %s", f.name, f.number, r.text)
+				}
+			}
+		}
+		for _, name := range names {
+			if f.name == name.name {
+				v.report(CodeReservedFieldName, f.nameRange, &name.rng, "the name is reserved here",
+					"field %q uses the reserved name", f.name)
+			}
+		}
+	}
+}
+
+// ranges converts the ranges of the statement at pos, reporting the invalid ones.
+func (v *validator) ranges(kind string, ranges []*parser.Range, max int64, pos meta.Position) []*numberRange {
+	var rs []*numberRange
+	for _, r := range ranges {
+		text := r.Begin
+		if r.End != "" {
+			text += " to " + r.End
+		}
+
+		span := r.BeginSpan
+		if r.EndSpan.IsValid() {
+			span.End = r.EndSpan.End
+		}
+		rng := rangeOf(span, pos)
+
+		start, ok := parseNumber(r.Begin)
+		end := start
+		switch r.End {
+		case "":
+		case "max":
+			end = max
+		default:
+			var endOK bool
+			end, endOK = parseNumber(r.End)
+			ok = ok && endOK
+		}
+
+		switch {
+		case !ok, start < 1, max < end:
+			v.report(CodeInvalidRange, rng, nil, "",
+				"%s range %s is out of range, the numbers must be between 1 and %d", kind, text, max)
+			continue
+		case end < start:
+			v.report(CodeInvalidRange, rng, nil, "",
+				"%s range %s is invalid, the end must be greater than or equal to the start", kind, text)
+			continue
+		}
+		rs = append(rs, &numberRange{kind: kind, start: start, end: end, text: text, rng: rng})
+	}
+	return rs
+}
+
+func (v *validator) rangesOverlap(ranges []*numberRange) {
+	for i, r := range ranges {
+		for _, original := range ranges[:i] {
+			if r.end < original.start || original.end < r.start {
+				continue
+			}
+			v.report(CodeOverlappingRanges, r.rng, &original.rng, "the overlapped range is declared here",
+				"%s range %s overlaps with %s range %s", r.kind, r.text, original.kind, original.text)
+		}
+	}
+}
+
+func (v *validator) reservedNames(names []*reservedName) {
+	reserved := make(map[string]*reservedName)
+	for _, name := range names {
+		if original, ok := reserved[name.name]; ok {
+			v.report(CodeDuplicateReservedName, name.rng, &original.rng, "the name is first reserved here",
+				"name %q is reserved multiple times", name.name)
+			continue
+		}
+		reserved[name.name] = name
+	}
+}
+
+func parseNumber(number string) (int64, bool) {
+	n, err := strconv.ParseInt(number, 0, 64)
+	return n, err == nil
+}
+
+// unquoteName returns the reserved name without quotes.
+// Reserved names in editions are identifiers without quotes.
+func unquoteName(name string) string {
+	if 2 <= len(name) && (name[0] == '"' || name[0] == '\'') && name[len(name)-1] == name[0] {
+		return name[1 : len(name)-1]
+	}
+	return name
+}
+
+// jsonName converts the field name to lowerCamelCase like protoc.
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, c := range name {
+		switch {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			b.WriteRune(c - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(c)
+			upper = false
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+// Package validator checks the semantics of a parsed proto which the parser accepts but protoc rejects,
+// such as the field numbers reused in a message.
+package validator
+
+import (
+	"fmt"
+
+	"github.com/yoheimuta/go-protoparser/v4/diagnostic"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/parser/meta"
+)
+
+// Code values of the diagnostics reported by Validate.
+const (
+	// CodeDuplicateFieldNumber is the code of a field number used by more than one field.
+	CodeDuplicateFieldNumber diagnostic.Code = "duplicate-field-number"
+	// CodeDuplicateFieldName is the code of a field name used by more than one field.
+	CodeDuplicateFieldName diagnostic.Code = "duplicate-field-name"
+	// CodeJSONNameConflict is the code of fields whose JSON names are the same.
+	CodeJSONNameConflict diagnostic.Code = "json-name-conflict"
+	// CodeFieldNumberOutOfRange is the code of a field number less than 1 or greater than 536870911.
+	CodeFieldNumberOutOfRange diagnostic.Code = "field-number-out-of-range"
+	// CodeImplementationReservedNumber is the code of a field number in 19000 to 19999.
+	CodeImplementationReservedNumber diagnostic.Code = "implementation-reserved-number"
+	// CodeReservedFieldNumber is the code of a field number in a reserved range.
+	CodeReservedFieldNumber diagnostic.Code = "reserved-field-number"
+	// CodeReservedFieldName is the code of a field name which is reserved.
+	CodeReservedFieldName diagnostic.Code = "reserved-field-name"
+	// CodeFieldNumberInExtensionRange is the code of a field number in an extension range.
+	CodeFieldNumberInExtensionRange diagnostic.Code = "field-number-in-extension-range"
+	// CodeInvalidRange is the code of a reserved or an extension range whose numbers are invalid.
+	CodeInvalidRange diagnostic.Code = "invalid-range"
+	// CodeOverlappingRanges is the code of reserved or extension ranges which overlap each other.
+	CodeOverlappingRanges diagnostic.Code = "overlapping-ranges"
+	// CodeDuplicateReservedName is the code of a name reserved more than once.
+	CodeDuplicateReservedName diagnostic.Code = "duplicate-reserved-name"
+	// CodeEnumFirstValueNotZero is the code of a proto3 enum whose first value is not zero.
+	CodeEnumFirstValueNotZero diagnostic.Code = "enum-first-value-not-zero"
+	// CodeDuplicateEnumValueNumber is the code of an enum value number used by more than one value without allow_alias.
+	CodeDuplicateEnumValueNumber diagnostic.Code = "duplicate-enum-value-number"
+	// CodeDuplicateEnumValueName is the code of an enum value name used by more than one value in an enum.
+	CodeDuplicateEnumValueName diagnostic.Code = "duplicate-enum-value-name"
+	// CodeEnumValueScopeConflict is the code of an enum value name declared by sibling enums.
+	CodeEnumValueScopeConflict diagnostic.Code = "enum-value-scope-conflict"
+)
+
+// Validate checks the proto and reports every problem which protoc would reject, sorted by the position.
+// Each diagnostic relates the clashing element to the original one, if any.
+// The diagnostics point at the clashing names and numbers if the proto is parsed with the spans option,
+// and at the start of the statements otherwise.
+// It returns nil if no problem is found.
+func Validate(proto *parser.Proto) diagnostic.Diagnostics {
+	v := &validator{
+		syntax: syntaxOf(proto),
+	}
+	v.body(packageOf(proto), proto.ProtoBody)
+	v.diags.Sort()
+	return v.diags
+}
+
+type validator struct {
+	syntax string
+	diags  diagnostic.Diagnostics
+}
+
+// syntaxOf returns either "proto2", "proto3" or "editions".
+func syntaxOf(proto *parser.Proto) string {
+	switch {
+	case proto.Edition != nil:
+		return "editions"
+	case proto.Syntax != nil:
+		return proto.Syntax.ProtobufVersion
+	}
+	return "proto2"
+}
+
+// packageOf returns the package name of the proto, or an empty string if there is none.
+func packageOf(proto *parser.Proto) string {
+	for _, element := range proto.ProtoBody {
+		if p, ok := element.(*parser.Package); ok {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// rangeOf returns the range of the span, or the range of an unknown length at pos if the span is not set.
+func rangeOf(span meta.Span, pos meta.Position) diagnostic.Range {
+	if !span.IsValid() {
+		return diagnostic.RangeOf(pos, "")
+	}
+	return diagnostic.Range{Start: span.Pos, End: span.End}
+}
+
+func (v *validator) report(
+	code diagnostic.Code,
+	rng diagnostic.Range,
+	original *diagnostic.Range,
+	originalMessage string,
+	format string,
+	a ...interface{},
+) {
+	diag := &diagnostic.Diagnostic{
+		Code:     code,
+		Severity: diagnostic.SeverityError,
+		Message:  fmt.Sprintf(format, a...),
+		Range:    rng,
+	}
+	if original != nil {
+		diag.Related = append(diag.Related, diagnostic.RelatedLocation{
+			Range:   *original,
+			Message: originalMessage,
+		})
+	}
+	v.diags = append(v.diags, diag)
+}
+
+// body validates the declarations placed in the body of a file whose package is scope.
+func (v *validator) body(scope string, body []parser.Visitee) {
+	var enums []*parser.Enum
+	for _, element := range body {
+		switch e := element.(type) {
+		case *parser.Message:
+			v.message(join(scope, e.MessageName), e.MessageBody)
+		case *parser.Enum:
+			enums = append(enums, e)
+		case *parser.Extend:
+			v.extend(scope, e)
+		}
+	}
+	v.enums(scope, enums)
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
@@ -0,0 +1,294 @@
+package validator_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/diagnostic"
+	"github.com/yoheimuta/go-protoparser/v4/validator"
+)
+
+func rangeString(r diagnostic.Range) string {
+	end := "?"
+	if r.End.Line != 0 {
+		end = fmt.Sprintf("%d:%d", r.End.Line, r.End.Column)
+	}
+	return fmt.Sprintf("%s-%s", r.Start, end)
+}
+
+func summarize(diags diagnostic.Diagnostics) []string {
+	var lines []string
+	for _, d := range diags {
+		lines = append(lines, fmt.Sprintf("%s: %s: %s", rangeString(d.Range), d.Code, d.Message))
+		for _, r := range d.Related {
+			lines = append(lines, fmt.Sprintf("  %s: %s", rangeString(r.Range), r.Message))
+		}
+	}
+	return lines
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		withoutSpans bool
+		wantDiags    []string
+	}{
+		{
+			name: "no problem",
+			input: `syntax = "proto3";
+message A {
+  int32 a = 1;
+  map<string, int32> b = 2;
+  oneof c {
+    string d = 3;
+  }
+  reserved 4, 10 to max;
+  reserved "e";
+}
+`,
+		},
+		{
+			name: "duplicate field numbers and names",
+			input: `syntax = "proto2";
+message A {
+  optional int32 a = 1;
+  oneof o {
+    string b = 1;
+  }
+  optional group A = 2 {
+    optional int32 a = 1;
+  }
+  message B {
+    optional int32 a = 1;
+    optional int32 a = 2;
+  }
+}
+`,
+			wantDiags: []string{
+				`<input>:5:16-5:17: duplicate-field-number: field number 1 has already been used in "A" by field "a"`,
+				`  <input>:3:22-3:23: the number is first used here`,
+				`<input>:7:18-7:19: duplicate-field-name: field "a" is already defined in "A"`,
+				`  <input>:3:18-3:19: the name is first used here`,
+				`<input>:12:20-12:21: duplicate-field-name: field "a" is already defined in "A.B"`,
+				`  <input>:11:20-11:21: the name is first used here`,
+			},
+		},
+		{
+			name: "out of range field numbers",
+			input: `syntax = "proto3";
+message A {
+  int32 a = 0;
+  int32 b = 536870912;
+  int32 c = 19000;
+}
+extend A {
+  int32 d = 2147483647;
+}
+`,
+			wantDiags: []string{
+				`<input>:3:13-3:14: field-number-out-of-range: field number 0 of "a" is out of range, it must be between 1 and 536870911`,
+				`<input>:4:13-4:22: field-number-out-of-range: field number 536870912 of "b" is out of range, it must be between 1 and 536870911`,
+				`<input>:5:13-5:18: implementation-reserved-number: field number 19000 of "c" is in 19000 to 19999, which are reserved for the protocol buffer library implementation`,
+				`<input>:8:13-8:23: field-number-out-of-range: field number 2147483647 of "d" is out of range, it must be between 1 and 2147483646`,
+			},
+		},
+		{
+			name: "groups in extend bodies",
+			input: `syntax = "proto2";
+extend A {
+  optional group G = 0 {
+    optional int32 a = 1;
+    optional int32 b = 1;
+  }
+}
+`,
+			wantDiags: []string{
+				`<input>:3:22-3:23: field-number-out-of-range: field number 0 of "g" is out of range, it must be between 1 and 2147483646`,
+				`<input>:5:24-5:25: duplicate-field-number: field number 1 has already been used in "G" by field "a"`,
+				`  <input>:4:24-4:25: the number is first used here`,
+			},
+		},
+		{
+			name: "reserved numbers and names",
+			input: `syntax = "proto3";
+message A {
+  reserved 2, 5 to 7;
+  reserved "b";
+  int32 a = 6;
+  int32 b = 8;
+}
+`,
+			wantDiags: []string{
+				`<input>:5:13-5:14: reserved-field-number: field "a" uses the reserved number 6`,
+				`  <input>:3:15-3:21: the number is reserved here`,
+				`<input>:6:9-6:10: reserved-field-name: field "b" uses the reserved name`,
+				`  <input>:4:12-4:15: the name is reserved here`,
+			},
+		},
+		{
+			name: "invalid and overlapping ranges",
+			input: `syntax = "proto2";
+message A {
+  reserved 10 to 5, 0;
+  reserved 1 to 3;
+  extensions 3 to 100;
+  extensions 50;
+  optional int32 b = 60;
+  reserved "a", "a";
+}
+`,
+			wantDiags: []string{
+				`<input>:3:12-3:19: invalid-range: reserved range 10 to 5 is invalid, the end must be greater than or equal to the start`,
+				`<input>:3:21-3:22: invalid-range: reserved range 0 is out of range, the numbers must be between 1 and 536870911`,
+				`<input>:5:14-5:22: overlapping-ranges: extensions range 3 to 100 overlaps with reserved range 1 to 3`,
+				`  <input>:4:12-4:18: the overlapped range is declared here`,
+				`<input>:6:14-6:16: overlapping-ranges: extensions range 50 overlaps with extensions range 3 to 100`,
+				`  <input>:5:14-5:22: the overlapped range is declared here`,
+				`<input>:7:22-7:24: field-number-in-extension-range: field "b" uses the number 60 in the extension range 3 to 100`,
+				`  <input>:5:14-5:22: the extension range is declared here`,
+				`<input>:8:17-8:20: duplicate-reserved-name: name "a" is reserved multiple times`,
+				`  <input>:8:12-8:15: the name is first reserved here`,
+			},
+		},
+		{
+			name: "message set extension ranges",
+			input: `syntax = "proto2";
+message A {
+  option message_set_wire_format = true;
+  extensions 4 to max;
+}
+`,
+		},
+		{
+			name: "json name conflicts",
+			input: `syntax = "proto3";
+message A {
+  int32 foo_bar = 1;
+  int32 fooBar = 2;
+  int32 foo_baz = 3;
+  int32 fooBaz = 4 [json_name = "other"];
+}
+`,
+			wantDiags: []string{
+				`<input>:4:9-4:15: json-name-conflict: the JSON name "fooBar" of field "fooBar" conflicts with field "foo_bar"`,
+				`  <input>:3:9-3:16: the JSON name is first used here`,
+			},
+		},
+		{
+			name: "json names in proto2",
+			input: `syntax = "proto2";
+message A {
+  optional int32 foo_bar = 1;
+  optional int32 fooBar = 2;
+}
+`,
+		},
+		{
+			name: "proto3 enum whose first value is not zero",
+			input: `syntax = "proto3";
+enum E {
+  E_A = 1;
+  E_B = 0;
+}
+`,
+			wantDiags: []string{
+				`<input>:3:9-3:10: enum-first-value-not-zero: the first enum value "E_A" of "E" must be zero in proto3`,
+			},
+		},
+		{
+			name: "proto2 enum whose first value is not zero",
+			input: `syntax = "proto2";
+enum E {
+  E_A = 1;
+}
+`,
+		},
+		{
+			name: "duplicate enum value numbers and names",
+			input: `syntax = "proto3";
+package pkg;
+enum E {
+  E_A = 0;
+  E_B = 0;
+  E_A = 1;
+}
+enum F {
+  option allow_alias = true;
+  F_A = 0;
+  F_B = 0;
+}
+`,
+			wantDiags: []string{
+				`<input>:5:9-5:10: duplicate-enum-value-number: enum value number 0 has already been used in "pkg.E" by enum value "E_A", set option allow_alias = true to allow aliases`,
+				`  <input>:4:9-4:10: the number is first used here`,
+				`<input>:6:3-6:6: duplicate-enum-value-name: enum value "E_A" is already defined in "pkg.E"`,
+				`  <input>:4:3-4:6: the name is first used here`,
+			},
+		},
+		{
+			name: "sibling enums declaring the same value name",
+			input: `syntax = "proto3";
+enum E {
+  UNKNOWN = 0;
+}
+enum F {
+  UNKNOWN = 0;
+}
+message A {
+  enum G {
+    NONE = 0;
+  }
+  enum H {
+    NONE = 0;
+  }
+  message B {
+    enum I {
+      NONE = 0;
+    }
+  }
+}
+`,
+			wantDiags: []string{
+				`<input>:6:3-6:10: enum-value-scope-conflict: enum value "UNKNOWN" of "F" is already defined by "E", enum values are siblings of their enum, so the name must be unique within the file`,
+				`  <input>:3:3-3:10: the name is first used here`,
+				`<input>:13:5-13:9: enum-value-scope-conflict: enum value "NONE" of "A.H" is already defined by "A.G", enum values are siblings of their enum, so the name must be unique within "A"`,
+				`  <input>:10:5-10:9: the name is first used here`,
+			},
+		},
+		{
+			name: "pointing at the statements without the spans option",
+			input: `syntax = "proto3";
+message A {
+  reserved 1;
+  int32 a = 1;
+  int32 a = 2;
+}
+`,
+			withoutSpans: true,
+			wantDiags: []string{
+				`<input>:4:3-?: reserved-field-number: field "a" uses the reserved number 1`,
+				`  <input>:3:3-?: the number is reserved here`,
+				`<input>:5:3-?: duplicate-field-name: field "a" is already defined in "A"`,
+				`  <input>:4:3-?: the name is first used here`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			proto, err := protoparser.Parse(strings.NewReader(test.input), protoparser.WithSpans(!test.withoutSpans))
+			if err != nil {
+				t.Fatalf("got err %v, but want nil", err)
+			}
+			got := summarize(validator.Validate(proto))
+			if !reflect.DeepEqual(got, test.wantDiags) {
+				t.Errorf("got %v, but want %v", strings.Join(got, "\n"), strings.Join(test.wantDiags, "\n"))
+			}
+		})
+	}
+}
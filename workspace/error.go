@@ -0,0 +1,19 @@
+package workspace
+
+import "strings"
+
+// Errors holds the errors of the files in the order of the files.
+type Errors []error
+
+func (e Errors) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors, so that errors.Is and errors.As look into each of them.
+func (e Errors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,208 @@
+// Package workspace parses many Protocol Buffer files concurrently, caching the results by their contents.
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	protoparser "github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+	"github.com/yoheimuta/go-protoparser/v4/resolver"
+)
+
+// Workspace parses files on a bounded number of goroutines.
+// It keeps the result of each file, and parses the file again only when its content changes.
+// It is safe for concurrent use.
+type Workspace struct {
+	concurrency  int
+	accessor     resolver.FileAccessor
+	parseOptions []protoparser.Option
+
+	mu sync.Mutex
+	// cache is the last results keyed by the filenames.
+	cache map[string]*entry
+}
+
+type entry struct {
+	sum   [sha256.Size]byte
+	proto *parser.Proto
+	err   error
+}
+
+// Option is an option for NewWorkspace.
+type Option func(*Workspace)
+
+// WithConcurrency is an option to set the maximum number of the files parsed at the same time.
+// The default is runtime.GOMAXPROCS(0).
+func WithConcurrency(concurrency int) Option {
+	return func(w *Workspace) {
+		w.concurrency = concurrency
+	}
+}
+
+// WithAccessor is an option to set the function to open files.
+// The default opens files in the file system.
+func WithAccessor(accessor resolver.FileAccessor) Option {
+	return func(w *Workspace) {
+		w.accessor = accessor
+	}
+}
+
+// WithParseOptions is an option to set the options passed to protoparser.Parse.
+// The filename is always set to the one of the file.
+func WithParseOptions(parseOptions ...protoparser.Option) Option {
+	return func(w *Workspace) {
+		w.parseOptions = parseOptions
+	}
+}
+
+// NewWorkspace creates a new Workspace.
+func NewWorkspace(opts ...Option) *Workspace {
+	w := &Workspace{
+		concurrency: runtime.GOMAXPROCS(0),
+		accessor: func(filename string) (io.ReadCloser, error) {
+			return os.Open(filename)
+		},
+		cache: make(map[string]*entry),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.concurrency < 1 {
+		w.concurrency = 1
+	}
+	return w
+}
+
+// File is a parsed file.
+type File struct {
+	// Filename is the name of the file.
+	Filename string
+	// Proto is the parsed file. It is nil if the file cannot be read or parsed,
+	// or the partially parsed one if the parser runs with the recovery option.
+	// It is shared with the later results of the same content; Clone it before modifying.
+	Proto *parser.Proto
+	// Err is the error of reading or parsing the file, if any.
+	Err error
+	// Cached is true if the file has the same content as the last time and was not parsed again.
+	Cached bool
+}
+
+// ParseFiles parses the files and returns them in the given order.
+// If any of them fails, it returns Errors holding every error along with all the files.
+// If ctx is done before the files are parsed, it returns the error of ctx.
+func (w *Workspace) ParseFiles(ctx context.Context, filenames ...string) ([]*File, error) {
+	files := make([]*File, len(filenames))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency && i < len(filenames); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				files[i] = w.parseFile(filenames[i])
+			}
+		}()
+	}
+
+sending:
+	for i := range filenames {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break sending
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var errs Errors
+	for _, file := range files {
+		if file.Err != nil {
+			errs = append(errs, file.Err)
+		}
+	}
+	if 0 < len(errs) {
+		return files, errs
+	}
+	return files, nil
+}
+
+// ParseDir parses every .proto file in the directory tree rooted at root, in the lexical order of their filenames.
+// The directory is walked in the file system regardless of WithAccessor.
+func (w *Workspace) ParseDir(ctx context.Context, root string) ([]*File, error) {
+	var filenames []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".proto") {
+			filenames = append(filenames, path)
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.ParseFiles(ctx, filenames...)
+}
+
+// parseFile parses the file unless its content is the same as the last time.
+func (w *Workspace) parseFile(filename string) *File {
+	content, err := w.read(filename)
+	if err != nil {
+		w.mu.Lock()
+		delete(w.cache, filename)
+		w.mu.Unlock()
+		return &File{Filename: filename, Err: err}
+	}
+	sum := sha256.Sum256(content)
+
+	w.mu.Lock()
+	cached, ok := w.cache[filename]
+	w.mu.Unlock()
+	if ok && cached.sum == sum {
+		return &File{Filename: filename, Proto: cached.proto, Err: cached.err, Cached: true}
+	}
+
+	proto, err := protoparser.Parse(
+		bytes.NewReader(content),
+		append(append([]protoparser.Option(nil), w.parseOptions...), protoparser.WithFilename(filename))...,
+	)
+	w.mu.Lock()
+	w.cache[filename] = &entry{sum: sum, proto: proto, err: err}
+	w.mu.Unlock()
+	return &File{Filename: filename, Proto: proto, Err: err}
+}
+
+func (w *Workspace) read(filename string) ([]byte, error) {
+	reader, err := w.accessor(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return io.ReadAll(reader)
+}
+
+// ParseFiles parses the files with the given options. See Workspace.ParseFiles.
+func ParseFiles(ctx context.Context, filenames []string, opts ...Option) ([]*File, error) {
+	return NewWorkspace(opts...).ParseFiles(ctx, filenames...)
+}
+
+// ParseDir parses the .proto files in the directory tree with the given options. See Workspace.ParseDir.
+func ParseDir(ctx context.Context, root string, opts ...Option) ([]*File, error) {
+	return NewWorkspace(opts...).ParseDir(ctx, root)
+}
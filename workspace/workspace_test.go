@@ -0,0 +1,237 @@
+package workspace_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yoheimuta/go-protoparser/v4/workspace"
+)
+
+type fakeFS struct {
+	mu    sync.Mutex
+	files map[string]string
+	opens map[string]int
+}
+
+func newFakeFS(files map[string]string) *fakeFS {
+	return &fakeFS{files: files, opens: make(map[string]int)}
+}
+
+func (f *fakeFS) open(filename string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opens[filename]++
+	content, ok := f.files[filename]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (f *fakeFS) write(filename, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[filename] = content
+}
+
+func filenamesOf(files []*workspace.File) []string {
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Filename)
+	}
+	return names
+}
+
+func TestWorkspace_ParseFiles(t *testing.T) {
+	tests := []struct {
+		name             string
+		inputFiles       map[string]string
+		inputFilenames   []string
+		inputConcurrency int
+		wantFilenames    []string
+		wantErrCount     int
+		wantErrFiles     []string
+	}{
+		{
+			name: "parsing files in the given order",
+			inputFiles: map[string]string{
+				"c.proto": `syntax = "proto3"; message C {}`,
+				"a.proto": `syntax = "proto3"; message A {}`,
+				"b.proto": `syntax = "proto3"; message B {}`,
+			},
+			inputFilenames:   []string{"c.proto", "a.proto", "b.proto"},
+			inputConcurrency: 2,
+			wantFilenames:    []string{"c.proto", "a.proto", "b.proto"},
+		},
+		{
+			name: "parsing files on a single worker",
+			inputFiles: map[string]string{
+				"a.proto": `syntax = "proto3"; message A {}`,
+				"b.proto": `syntax = "proto3"; message B {}`,
+			},
+			inputFilenames:   []string{"b.proto", "a.proto"},
+			inputConcurrency: 1,
+			wantFilenames:    []string{"b.proto", "a.proto"},
+		},
+		{
+			name: "aggregating the errors in the order of the files",
+			inputFiles: map[string]string{
+				"a.proto": `syntax = "proto3"; message A {`,
+				"b.proto": `syntax = "proto3"; message B {}`,
+			},
+			inputFilenames:   []string{"missing.proto", "a.proto", "b.proto"},
+			inputConcurrency: 3,
+			wantFilenames:    []string{"missing.proto", "a.proto", "b.proto"},
+			wantErrCount:     2,
+			wantErrFiles:     []string{"missing.proto", "a.proto"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fs := newFakeFS(test.inputFiles)
+			w := workspace.NewWorkspace(
+				workspace.WithAccessor(fs.open),
+				workspace.WithConcurrency(test.inputConcurrency),
+			)
+			got, err := w.ParseFiles(context.Background(), test.inputFilenames...)
+
+			if gotNames := filenamesOf(got); !reflect.DeepEqual(gotNames, test.wantFilenames) {
+				t.Errorf("got %v, but want %v", gotNames, test.wantFilenames)
+			}
+			if test.wantErrCount == 0 {
+				if err != nil {
+					t.Fatalf("got err %v", err)
+				}
+				for _, file := range got {
+					if file.Proto == nil {
+						t.Errorf("got nil Proto of %s", file.Filename)
+					}
+				}
+				return
+			}
+
+			var errs workspace.Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("got %T, but want workspace.Errors", err)
+			}
+			if len(errs) != test.wantErrCount {
+				t.Errorf("got %v, but want %v", len(errs), test.wantErrCount)
+			}
+			var gotErrFiles []string
+			for _, file := range got {
+				if file.Err != nil {
+					gotErrFiles = append(gotErrFiles, file.Filename)
+				}
+			}
+			if !reflect.DeepEqual(gotErrFiles, test.wantErrFiles) {
+				t.Errorf("got %v, but want %v", gotErrFiles, test.wantErrFiles)
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("got %v, but want it to wrap %v", err, os.ErrNotExist)
+			}
+		})
+	}
+}
+
+func TestWorkspace_ParseFiles_cache(t *testing.T) {
+	fs := newFakeFS(map[string]string{
+		"a.proto": `syntax = "proto3"; message A {}`,
+		"b.proto": `syntax = "proto3"; message B {}`,
+	})
+	w := workspace.NewWorkspace(workspace.WithAccessor(fs.open))
+
+	first, err := w.ParseFiles(context.Background(), "a.proto", "b.proto")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+	for _, file := range first {
+		if file.Cached {
+			t.Errorf("got cached %s on the first parse", file.Filename)
+		}
+	}
+
+	fs.write("b.proto", `syntax = "proto3"; message B { string b = 1; }`)
+	second, err := w.ParseFiles(context.Background(), "a.proto", "b.proto")
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	if !second[0].Cached {
+		t.Errorf("got not cached a.proto, but want cached")
+	}
+	if second[0].Proto != first[0].Proto {
+		t.Errorf("got a different Proto of a.proto, but want the cached one")
+	}
+	if second[1].Cached {
+		t.Errorf("got cached b.proto, but want parsed again")
+	}
+	if second[1].Proto == first[1].Proto {
+		t.Errorf("got the same Proto of the changed b.proto")
+	}
+	if got := fs.opens["a.proto"]; got != 2 {
+		t.Errorf("got %v, but want %v", got, 2)
+	}
+}
+
+func TestWorkspace_ParseFiles_canceled(t *testing.T) {
+	fs := newFakeFS(map[string]string{
+		"a.proto": `syntax = "proto3";`,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err := workspace.ParseFiles(ctx, []string{"a.proto"}, workspace.WithAccessor(fs.open))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, but want %v", err, context.Canceled)
+	}
+	if got != nil {
+		t.Errorf("got %v, but want nil", got)
+	}
+}
+
+func TestWorkspace_ParseDir(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"b.proto":        `syntax = "proto3"; message B {}`,
+		"a.proto":        `syntax = "proto3"; message A {}`,
+		"sub/c.proto":    `syntax = "proto3"; message C {}`,
+		"sub/README.md":  `# not a proto`,
+		"sub/z/d.proto":  `syntax = "proto3"; message D {}`,
+		"sub/z/e.protox": `not a proto`,
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := workspace.ParseDir(context.Background(), root, workspace.WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("got err %v", err)
+	}
+
+	var gotNames []string
+	for _, name := range filenamesOf(got) {
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotNames = append(gotNames, filepath.ToSlash(rel))
+	}
+	wantNames := []string{"a.proto", "b.proto", "sub/c.proto", "sub/z/d.proto"}
+	if !reflect.DeepEqual(gotNames, wantNames) {
+		t.Errorf("got %v, but want %v", gotNames, wantNames)
+	}
+}